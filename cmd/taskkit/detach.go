@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// detachedLog receives the console output of a detached run
+const detachedLog = "taskkit-detached.log"
+
+// detachRun starts `taskkit workflow <command>` again in a new background
+// process with the same args minus --detach, writing run state to the
+// workdir and its console output to <workdir>/taskkit-detached.log
+func detachRun(command string, args []string, workdir string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate taskkit binary: %w", err)
+	}
+	if err := os.MkdirAll(workdir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create workdir: %w", err)
+	}
+	logFile, err := os.Create(filepath.Join(workdir, detachedLog))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create detached log: %w", err)
+	}
+	defer logFile.Close()
+
+	childArgs := []string{"workflow", command, "--write-state", "--no-progress"}
+	for _, arg := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && name == "detach" {
+			continue
+		}
+		childArgs = append(childArgs, arg)
+	}
+
+	cmd := exec.Command(exe, childArgs...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start detached run: %w", err)
+	}
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return 0, fmt.Errorf("failed to release detached run: %w", err)
+	}
+	return pid, nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "syscall"
+
+// detachedProcAttr uses the default process attributes where sessions are
+// not available
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,85 @@
+//go:build ignore
+
+// gen_modules writes modules_gen.go, which blank-imports every task package
+// under tasks/ and lists them in moduleManifest. Run it with go generate
+// after adding or removing a task package.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	root := filepath.Join("..", "..")
+	module, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "tasks"))
+	if err != nil {
+		log.Fatalf("failed to read tasks: %v", err)
+	}
+	var packages []string
+	for _, entry := range entries {
+		if entry.IsDir() && hasGoFiles(filepath.Join(root, "tasks", entry.Name())) {
+			packages = append(packages, module+"/tasks/"+entry.Name())
+		}
+	}
+	sort.Strings(packages)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_modules.go; DO NOT EDIT.\n\npackage main\n\n")
+	buf.WriteString("import (\n\t// Import task packages to register handlers via init()\n")
+	for _, pkg := range packages {
+		fmt.Fprintf(&buf, "\t_ %q\n", pkg)
+	}
+	buf.WriteString(")\n\n// moduleManifest lists the task packages compiled into taskkit\nvar moduleManifest = []string{\n")
+	for _, pkg := range packages {
+		fmt.Fprintf(&buf, "\t%q,\n", pkg)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format modules_gen.go: %v", err)
+	}
+	if err := os.WriteFile("modules_gen.go", src, 0644); err != nil {
+		log.Fatalf("failed to write modules_gen.go: %v", err)
+	}
+}
+
+// modulePath reads the module path from go.mod
+func modulePath(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open go.mod: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.TrimSpace(rest), nil
+		}
+	}
+	return "", fmt.Errorf("no module line in %s", path)
+}
+
+// hasGoFiles reports whether dir holds a non-test Go file
+func hasGoFiles(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if !strings.HasSuffix(file, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func TestListHandlersEmptyRegistry(t *testing.T) {
+	snap := taskkit.SnapshotRegistry()
+	t.Cleanup(func() { taskkit.RestoreRegistry(snap) })
+	taskkit.RestoreRegistry(taskkit.RegistrySnapshot{})
+
+	out := captureStdout(t, func() { listHandlers(nil) })
+	if want := "Warning: no handlers registered; did you import your task packages?\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestListHandlersCompiledIn(t *testing.T) {
+	out := captureStdout(t, func() { listHandlers(nil) })
+	// The binary blank-imports the task packages, so their handlers appear
+	for _, name := range []string{"net-check", "manual-approve"} {
+		if !strings.Contains(out, "  - "+name+"\n") {
+			t.Errorf("handler %s not listed:\n%s", name, out)
+		}
+	}
+}
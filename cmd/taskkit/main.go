@@ -3,19 +3,36 @@
 // Usage:
 //
 //	taskkit workflow run --workflow <path> [options]
-//	taskkit list-handlers
+//	taskkit workflow retry --workflow <path> --from <result.json> [options]
+//	taskkit workflow plan --workflow <path> [options]
+//	taskkit workflow status --workdir <dir>
+//	taskkit workflow lint --workflow <path> [--strict]
+//	taskkit replay --step <name> --from <dir>
+//	taskkit show --from <result.json> [--since-failure]
+//	taskkit list-handlers [--export <path>]
+//	taskkit list-modules
+//	taskkit test-handlers
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/erauner/homelab-task-go/pkg/taskkit"
-	// Import task packages to register handlers via init()
-	_ "github.com/erauner/homelab-task-go/tasks/smoke_test"
+	"github.com/erauner/homelab-task-go/pkg/taskkit/redisstore"
+	"github.com/erauner/homelab-task-go/pkg/taskkit/sqlitestore"
 )
 
+// Task packages are blank-imported by modules_gen.go
+//go:generate go run gen_modules.go
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
@@ -24,14 +41,40 @@ func main() {
 
 	switch os.Args[1] {
 	case "workflow":
-		if len(os.Args) < 3 || os.Args[2] != "run" {
-			fmt.Println("Usage: taskkit workflow run --workflow <path> [options]")
+		if len(os.Args) < 3 {
+			fmt.Println("Usage: taskkit workflow <run|retry|plan|status|lint> [options]")
+			os.Exit(1)
+		}
+		switch os.Args[2] {
+		case "run":
+			os.Exit(runWorkflow("run", os.Args[3:]))
+		case "retry":
+			os.Exit(runWorkflow("retry", os.Args[3:]))
+		case "plan":
+			planWorkflow(os.Args[3:])
+		case "status":
+			workflowStatus(os.Args[3:])
+		case "lint":
+			lintWorkflow(os.Args[3:])
+		default:
+			fmt.Println("Usage: taskkit workflow <run|retry|plan|status|lint> [options]")
 			os.Exit(1)
 		}
-		runWorkflow(os.Args[3:])
+
+	case "replay":
+		replayStep(os.Args[2:])
+
+	case "show":
+		showResult(os.Args[2:])
 
 	case "list-handlers":
-		listHandlers()
+		listHandlers(os.Args[2:])
+
+	case "list-modules":
+		listModules()
+
+	case "test-handlers":
+		testHandlers()
 
 	case "version":
 		fmt.Println("taskkit v0.1.0")
@@ -46,45 +89,265 @@ func main() {
 	}
 }
 
+func lintWorkflow(args []string) {
+	fs := flag.NewFlagSet("workflow lint", flag.ExitOnError)
+	workflowPath := fs.String("workflow", "", "Path to workflow YAML file")
+	fs.StringVar(workflowPath, "w", "", "Path to workflow YAML file (shorthand)")
+	strict := fs.Bool("strict", false, "Exit non-zero when there are lint warnings")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *workflowPath == "" {
+		fmt.Println("Error: --workflow is required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	wf, err := taskkit.LoadWorkflow(*workflowPath)
+	if err != nil {
+		fmt.Printf("Error loading workflow: %v\n", err)
+		os.Exit(1)
+	}
+
+	warnings := wf.Lint()
+	if len(warnings) == 0 {
+		fmt.Printf("%s: no lint warnings\n", wf.Name)
+		return
+	}
+	fmt.Printf("%s: %d lint warnings\n", wf.Name, len(warnings))
+	for _, w := range warnings {
+		fmt.Printf("  %s\n", w)
+	}
+	if *strict {
+		os.Exit(1)
+	}
+}
+
 func printUsage() {
 	fmt.Println(`taskkit - Homelab task workflow runner
 
 Commands:
   workflow run    Execute a workflow
+  workflow retry  Re-run the steps that failed in a prior run, plus their dependents
+  workflow plan   Show which steps would run or be skipped given vars and params
+  workflow status Show the progress of a run started with --detach or --write-state
+  workflow lint   Check a workflow against best practices
+  replay          Re-run one step from inputs recorded with --record-inputs
+  show            Print a saved execution result
   list-handlers   List all registered step handlers
+  list-modules    List the task packages compiled in and their handlers
+  test-handlers   Run handler self-tests
   version         Show version
 
 Workflow Options:
   --workflow, -w  Path to workflow YAML file (required)
   --params, -p    Path to params.json file
+  --param         Override a param, e.g. --param key=value (repeatable)
+  --param-env-prefix Read unset params from env, e.g. TASKKIT_PARAM_REGION (default prefix TASKKIT_PARAM_)
   --workdir       Working directory for outputs
   --task-id       Task ID for tracking
+  --import-vars   Seed vars from a prior execution-result.json
+  --set           Set a var, e.g. --set key=value (repeatable)
+  --var-store     Persist vars in Redis (redis://host:port/db) instead of vars.yaml
+  --var-store-ttl Expire Redis-stored vars after this duration (e.g. 24h)
+  --var-store-fallback Fall back to vars.yaml when Redis is unreachable
+  --strict-vars   Fail when vars.yaml is malformed or vars exceed --max-vars instead of warning
+  --max-vars      Warn when more than this many vars would be saved to vars.yaml
+  --prune-vars    Drop reserved and stale vars once --max-vars is exceeded
+  --snapshot-vars Record the vars after each step in the result
+  --lint-vars     Warn about vars set but never read by a later step
+  --allow-missing Run even if some step handlers are not registered
+  --capture-stdout Record streamed handler output in step results
+  --exit-code     Map an outcome to an exit code, e.g. Failed=3 or NoOp=4 (repeatable)
+  --record-inputs Save each step's input to <workdir>/inputs/<step>.json
+  --seed          Seed for the run's random source (default: time-based)
+  --strict-warnings Fail steps that emit WARNING messages
+  --log-file      Also write console output to this file
+  --log-append    Append to --log-file instead of truncating it
+  --log-format    Format for --log-file: text (default) or json
+  --slog          Also log to stderr as structured slog records: text or json (DEBUG with --verbose)
+  --dump-params   Print each step's merged params before its handler runs (implied by --verbose)
+  --quiet, -q     Only print the final workflow status
+  --no-progress   Disable the progress line on interactive terminals
+  --tags          Only run steps with one of these tags, e.g. --tags network,dns
+  --skip-tags     Skip steps with any of these tags, e.g. --skip-tags destructive
+  --profile-runtime Record Go memory and goroutine stats in the result
+  --cpuprofile    Write a CPU profile of the run to this file
+  --memprofile    Write a heap profile taken after the run to this file
+  --failure-policy After a failure: continue or skip-to-finalize
+  --cleanup-temp  Remove handler temp files under <workdir>/tmp when the run ends
+  --result-url    POST the execution result JSON to this URL (bearer token from $TASKKIT_RESULT_TOKEN)
+  --result-db     Record each run in this SQLite database (schema created on first use)
+  --result-db-steps Also record a row per step in --result-db
+  --max-steps     Abort after this many step executions (default 1000, negative disables)
+  --detach        Continue the run in a background process (requires --workdir)
+  --write-state   Keep <workdir>/run-state.json updated for workflow status
+  --approve       Pre-approve manual-approve gate steps
+  --expect-hash   Refuse to run unless the workflow file's SHA-256 matches
+  --confirm       Allow a workflow marked destructive to run (or set TASKKIT_CONFIRM=1)
+  --log-throttle  Collapse identical output lines repeated within this window (e.g. 5s)
+  --modules       Require these task packages to be compiled in, e.g. --modules gate,net
+  --max-output-bytes Drop the largest output keys of steps whose output exceeds this size
+  --finalize-on-empty Run finalize steps even when no other step ran (default true; --finalize-on-empty=false skips them)
+  --metrics-file  Write per-handler step counts, durations, and retries to this JSON file in --workdir
+  --slow-factor   Warn when a step runs longer than this times its expected_seconds (default 1.5)
+  --result-format Write the execution result as json (default) or yaml
+  --explain       Print why each step ran or was skipped
+  --fail-step     Force a step to fail, e.g. --fail-step deploy="disk full" (repeatable, for testing)
+  --verbose, -v   Enable verbose logging
+  --trace         Dump each step's full input and result as JSON
+
+Retry Options:
+  --from          Path to the prior run's execution-result.json or .yaml (required)
+  All workflow options also apply
+
+Plan Options:
+  --workflow, -w  Path to workflow YAML file (required)
+  --params, -p    Path to params.json file
+  --param         Override a param, e.g. --param key=value (repeatable)
+  --param-env-prefix Read unset params from env, as for workflow run
+  --set           Set a var, e.g. --set key=value (repeatable)
+  --workdir       Include vars persisted in <workdir>/vars.yaml
+
+Lint Options:
+  --workflow, -w  Path to workflow YAML file (required)
+  --strict        Exit non-zero when there are lint warnings
+
+Status Options:
+  --workdir       Working directory of the run (required)
+
+Show Options:
+  --from          Path to execution-result.json or .yaml (required)
+  --since-failure Start at the first failed step
+
+List-Handlers Options:
+  --export        Write the handler list to this file as JSON (see taskkit.HandlerExport)
+
+Replay Options:
+  --step          Name of the step to replay (required)
+  --from          Workdir of the run that recorded the inputs (required)
+  --workdir       Working directory for the handler (defaults to --from)
   --verbose, -v   Enable verbose logging
+  --allow-redacted Replay even if sensitive values were redacted when recorded
 
 Example:
   taskkit workflow run --workflow workflows/smoke_test.yaml --workdir /tmp/run`)
 }
 
-func runWorkflow(args []string) {
-	fs := flag.NewFlagSet("workflow run", flag.ExitOnError)
+// runWorkflow implements `workflow run`, and `workflow retry` when command
+// is "retry". It returns the exit code instead of calling os.Exit so the
+// deferred store Close calls run.
+func runWorkflow(command string, args []string) int {
+	fs := flag.NewFlagSet("workflow "+command, flag.ExitOnError)
+	var retryFrom *string
+	if command == "retry" {
+		retryFrom = fs.String("from", "", "Path to the prior run's execution-result.json")
+	}
 	workflowPath := fs.String("workflow", "", "Path to workflow YAML file")
 	fs.StringVar(workflowPath, "w", "", "Path to workflow YAML file (shorthand)")
 	paramsPath := fs.String("params", "", "Path to params.json file")
 	fs.StringVar(paramsPath, "p", "", "Path to params.json file (shorthand)")
 	workdir := fs.String("workdir", "", "Working directory for outputs")
 	taskID := fs.String("task-id", "", "Task ID for tracking")
+	importVars := fs.String("import-vars", "", "Seed vars from a prior execution-result.json")
+	strictVars := fs.Bool("strict-vars", false, "Fail when vars.yaml is malformed or vars exceed --max-vars instead of warning")
+	maxVars := fs.Int("max-vars", 0, "Warn when more than this many vars would be saved to vars.yaml")
+	pruneVars := fs.Bool("prune-vars", false, "Drop reserved and stale vars once --max-vars is exceeded")
+	snapshotVars := fs.Bool("snapshot-vars", false, "Record the vars after each step in the result")
+	lintVars := fs.Bool("lint-vars", false, "Warn about vars set but never read by a later step")
+	allowMissing := fs.Bool("allow-missing", false, "Run even if some step handlers are not registered")
+	captureStdout := fs.Bool("capture-stdout", false, "Record streamed handler output in step results")
+	recordInputs := fs.Bool("record-inputs", false, "Save each step's input to <workdir>/inputs/<step>.json")
+	strictWarnings := fs.Bool("strict-warnings", false, "Fail steps that emit WARNING messages")
+	logFile := fs.String("log-file", "", "Also write console output to this file")
+	logAppend := fs.Bool("log-append", false, "Append to --log-file instead of truncating it")
+	logFormat := fs.String("log-format", "text", "Format for --log-file: text or json")
+	slogFormat := fs.String("slog", "", "Also log to stderr as structured slog records: text or json")
+	quiet := fs.Bool("quiet", false, "Only print the final workflow status")
+	fs.BoolVar(quiet, "q", false, "Only print the final workflow status (shorthand)")
+	noProgress := fs.Bool("no-progress", false, "Disable the progress line on interactive terminals")
+	var tags, skipTags []string
+	fs.Func("tags", "Only run steps with one of these comma-separated tags (repeatable)", appendList(&tags))
+	fs.Func("skip-tags", "Skip steps with any of these comma-separated tags (repeatable)", appendList(&skipTags))
+	profileRuntime := fs.Bool("profile-runtime", false, "Record Go memory and goroutine stats in the result")
+	cpuProfile := fs.String("cpuprofile", "", "Write a CPU profile of the run to this file")
+	memProfile := fs.String("memprofile", "", "Write a heap profile taken after the run to this file")
+	failurePolicy := fs.String("failure-policy", "", "After a failure: continue or skip-to-finalize (default: workflow's failure_policy)")
+	cleanupTemp := fs.Bool("cleanup-temp", false, "Remove handler temp files under <workdir>/tmp when the run ends")
+	resultURL := fs.String("result-url", "", "POST the execution result JSON to this URL (bearer token from $TASKKIT_RESULT_TOKEN)")
+	resultDB := fs.String("result-db", "", "Record each run in this SQLite database (schema created on first use)")
+	resultDBSteps := fs.Bool("result-db-steps", false, "Also record a row per step in --result-db")
+	maxSteps := fs.Int("max-steps", 0, "Abort after this many step executions (default 1000, negative disables)")
+	detach := fs.Bool("detach", false, "Continue the run in a background process (requires --workdir)")
+	writeState := fs.Bool("write-state", false, "Keep <workdir>/run-state.json updated for workflow status")
+	approve := fs.Bool("approve", false, "Pre-approve manual-approve gate steps")
+	expectHash := fs.String("expect-hash", "", "Refuse to run unless the workflow file's SHA-256 matches")
+	confirm := fs.Bool("confirm", false, "Allow a workflow marked destructive to run")
+	maxOutputBytes := fs.Int("max-output-bytes", 0, "Drop the largest output keys of steps whose output exceeds this size")
+	finalizeOnEmpty := fs.Bool("finalize-on-empty", true, "Run finalize steps even when no other step ran")
+	metricsFile := fs.String("metrics-file", "", "Write per-handler step counts, durations, and retries to this JSON file (relative to --workdir)")
+	slowFactor := fs.Float64("slow-factor", taskkit.DefaultSlowStepFactor, "Warn when a step runs longer than this times its expected_seconds")
+	resultFormat := fs.String("result-format", "json", "Write the execution result as json or yaml")
+	explain := fs.Bool("explain", false, "Print why each step ran or was skipped")
+	var modules []string
+	fs.Func("modules", "Require these comma-separated task packages to be compiled in (repeatable)", appendList(&modules))
+	failSteps := make(map[string]string)
+	fs.Func("fail-step", "Force a step to fail, e.g. --fail-step name=message (repeatable, for testing)", assignString(failSteps))
+	logThrottle := fs.Duration("log-throttle", 0, "Collapse identical output lines repeated within this window (e.g. 5s)")
+	seed := fs.Int64("seed", 0, "Seed for the run's random source (default: time-based)")
+	varStoreURL := fs.String("var-store", "", "Persist vars in Redis (redis://host:port/db) instead of vars.yaml")
+	varStoreTTL := fs.Duration("var-store-ttl", 0, "Expire Redis-stored vars after this duration")
+	varStoreFallback := fs.Bool("var-store-fallback", false, "Fall back to vars.yaml when Redis is unreachable")
+	paramEnvPrefix := fs.String("param-env-prefix", "TASKKIT_PARAM_", "Env var prefix for fallback params (empty disables)")
+	paramOverrides := make(map[string]any)
+	fs.Func("param", "Override a param, e.g. --param key=value (repeatable)", assignTo(paramOverrides))
+	setVars := make(map[string]any)
+	fs.Func("set", "Set a var, e.g. --set key=value (repeatable)", assignTo(setVars))
+	exitCodes := taskkit.DefaultExitCodeMap()
+	fs.Func("exit-code", "Map an outcome to an exit code, e.g. Failed=3 (repeatable)", exitCodes.Set)
 	verbose := fs.Bool("verbose", false, "Enable verbose logging")
 	fs.BoolVar(verbose, "v", false, "Enable verbose logging (shorthand)")
+	trace := fs.Bool("trace", false, "Dump each step's full input and result as JSON")
+	dumpParams := fs.Bool("dump-params", false, "Print each step's merged params before its handler runs")
 
 	if err := fs.Parse(args); err != nil {
 		fmt.Printf("Error parsing flags: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
 	if *workflowPath == "" {
 		fmt.Println("Error: --workflow is required")
 		fs.PrintDefaults()
-		os.Exit(1)
+		return 1
+	}
+
+	if retryFrom != nil && *retryFrom == "" {
+		fmt.Println("Error: --from is required")
+		fs.PrintDefaults()
+		return 1
+	}
+
+	if err := taskkit.RequireModules(modules); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+
+	if *detach {
+		if *workdir == "" {
+			fmt.Println("Error: --detach requires --workdir")
+			return 1
+		}
+		pid, err := detachRun(command, args, *workdir)
+		if err != nil {
+			fmt.Printf("Error detaching run: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Detached run started (pid %d)\n", pid)
+		fmt.Printf("Check progress with: taskkit workflow status --workdir %s\n", *workdir)
+		return 0
 	}
 
 	config := taskkit.LocalRunnerConfig{
@@ -93,31 +356,477 @@ func runWorkflow(args []string) {
 		Workdir:      *workdir,
 		TaskID:       *taskID,
 		Verbose:      *verbose,
+		Trace:        *trace,
+		DumpParams:   *dumpParams,
+
+		ImportVarsPath: *importVars,
+		SetVars:        setVars,
+		ParamOverrides: paramOverrides,
+		ParamEnvPrefix: *paramEnvPrefix,
+		StrictVars:     *strictVars,
+		MaxVars:        *maxVars,
+		PruneVars:      *pruneVars,
+		LintVars:       *lintVars,
+		SnapshotVars:   *snapshotVars,
+		AllowMissing:   *allowMissing,
+		CaptureStdout:  *captureStdout,
+		RecordInputs:   *recordInputs,
+		Seed:           *seed,
+		StrictWarnings: *strictWarnings,
+		LogFile:        *logFile,
+		LogAppend:      *logAppend,
+		LogFormat:      *logFormat,
+		Quiet:          *quiet,
+		LogThrottle:    *logThrottle,
+		Approve:        *approve,
+		Confirm:        *confirm,
+		ExpectHash:     *expectHash,
+		WriteState:     *writeState,
+		MaxSteps:       *maxSteps,
+		MaxOutputBytes: *maxOutputBytes,
+		SlowStepFactor: *slowFactor,
+		MetricsFile:    *metricsFile,
+		CleanupTemp:    *cleanupTemp,
+		FailurePolicy:  *failurePolicy,
+		ProfileRuntime: *profileRuntime,
+		FailSteps:      failSteps,
+		Explain:        *explain,
+		Tags:           tags,
+		SkipTags:       skipTags,
+		Progress:       !*quiet && !*noProgress && taskkit.IsTerminal(os.Stdout),
+
+		FinalizeOnEmpty: finalizeOnEmpty,
+	}
+	if retryFrom != nil {
+		config.RetryFromPath = *retryFrom
+	}
+
+	if *slogFormat != "" {
+		logger, err := newSlogLogger(*slogFormat, *verbose)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+		config.Logger = logger
+	}
+
+	encoder, err := taskkit.NewResultEncoder(*resultFormat)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	config.ResultEncoder = encoder
+
+	if *resultURL != "" {
+		config.ResultSink = &taskkit.ResultSink{
+			URL:   *resultURL,
+			Token: os.Getenv("TASKKIT_RESULT_TOKEN"),
+		}
+	}
+
+	if *resultDB != "" {
+		store, err := sqlitestore.New(sqlitestore.Options{Path: *resultDB, Steps: *resultDBSteps})
+		if err != nil {
+			fmt.Printf("Error configuring result database: %v\n", err)
+			return 1
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				fmt.Printf("Warning: failed to close result database: %v\n", err)
+			}
+		}()
+		config.ResultStore = store
+	}
+
+	if *varStoreURL != "" {
+		store, err := newRedisVarStore(*varStoreURL, *taskID, *workdir, *varStoreTTL, *varStoreFallback)
+		if err != nil {
+			fmt.Printf("Error configuring var store: %v\n", err)
+			return 1
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				fmt.Printf("Warning: failed to close var store: %v\n", err)
+			}
+		}()
+		config.VarStore = store
 	}
 
 	runner, err := taskkit.NewLocalRunner(config)
 	if err != nil {
 		fmt.Printf("Error initializing runner: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
 
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile)
+	if err != nil {
+		fmt.Printf("Error starting profiler: %v\n", err)
+		return 1
+	}
 	result := runner.Run()
+	stopProfiling()
+
+	// A handler-requested exit code wins over the outcome mapping
+	if result.ExitCode != nil {
+		return *result.ExitCode
+	}
+
+	// Exit with the code mapped to the run's outcome
+	return exitCodes.Code(result)
+}
 
-	// Exit with appropriate code
-	switch result.Result {
-	case "Succeeded":
-		os.Exit(0)
-	case "Failed":
+// newSlogLogger builds a runner Logger writing slog records to stderr in
+// the given format, including DEBUG records when verbose
+func newSlogLogger(format string, verbose bool) (taskkit.Logger, error) {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if verbose {
+		opts.Level = slog.LevelDebug
+	}
+	switch format {
+	case "text":
+		return taskkit.NewSlogLogger(slog.New(slog.NewTextHandler(os.Stderr, opts))), nil
+	case "json":
+		return taskkit.NewSlogLogger(slog.New(slog.NewJSONHandler(os.Stderr, opts))), nil
+	}
+	return nil, fmt.Errorf("unknown --slog format %q: want text or json", format)
+}
+
+// newRedisVarStore builds a Redis var store keyed by task ID, optionally
+// falling back to the workdir's vars.yaml
+func newRedisVarStore(url, taskID, workdir string, ttl time.Duration, fallback bool) (*redisstore.Store, error) {
+	if taskID == "" {
+		return nil, fmt.Errorf("--var-store requires --task-id")
+	}
+	opts := redisstore.Options{
+		URL:    url,
+		TaskID: taskID,
+		TTL:    ttl,
+		Logger: func(format string, args ...any) {
+			fmt.Printf("Warning: "+format+"\n", args...)
+		},
+	}
+	if fallback {
+		if workdir == "" {
+			workdir = "."
+		}
+		opts.Fallback = taskkit.NewFileVarStore(filepath.Join(workdir, "vars.yaml"))
+	}
+	return redisstore.New(opts)
+}
+
+// assignTo returns a flag.Func callback that parses key=value into m.
+// Values are decoded as JSON scalars where possible, otherwise kept as strings.
+func assignTo(m map[string]any) func(string) error {
+	return func(spec string) error {
+		key, raw, ok := strings.Cut(spec, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", spec)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			value = raw
+		}
+		m[key] = value
+		return nil
+	}
+}
+
+// assignString returns a flag.Func callback that parses key=value into m,
+// keeping the value as a string
+func assignString(m map[string]string) func(string) error {
+	return func(spec string) error {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", spec)
+		}
+		m[key] = value
+		return nil
+	}
+}
+
+// appendList returns a flag.Func callback that appends comma-separated values to *list
+func appendList(list *[]string) func(string) error {
+	return func(s string) error {
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				*list = append(*list, v)
+			}
+		}
+		return nil
+	}
+}
+
+func planWorkflow(args []string) {
+	fs := flag.NewFlagSet("workflow plan", flag.ExitOnError)
+	workflowPath := fs.String("workflow", "", "Path to workflow YAML file")
+	fs.StringVar(workflowPath, "w", "", "Path to workflow YAML file (shorthand)")
+	paramsPath := fs.String("params", "", "Path to params.json file")
+	fs.StringVar(paramsPath, "p", "", "Path to params.json file (shorthand)")
+	workdir := fs.String("workdir", "", "Include vars persisted in <workdir>/vars.yaml")
+	paramEnvPrefix := fs.String("param-env-prefix", "TASKKIT_PARAM_", "Env var prefix for fallback params (empty disables)")
+	paramOverrides := make(map[string]any)
+	fs.Func("param", "Override a param, e.g. --param key=value (repeatable)", assignTo(paramOverrides))
+	setVars := make(map[string]any)
+	fs.Func("set", "Set a var, e.g. --set key=value (repeatable)", assignTo(setVars))
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
 		os.Exit(1)
-	default:
+	}
+
+	if *workflowPath == "" {
+		fmt.Println("Error: --workflow is required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	wf, err := taskkit.LoadWorkflow(*workflowPath)
+	if err != nil {
+		fmt.Printf("Error loading workflow: %v\n", err)
+		os.Exit(1)
+	}
+
+	params := make(map[string]any)
+	if *paramsPath != "" {
+		data, err := os.ReadFile(*paramsPath)
+		if err != nil {
+			fmt.Printf("Error reading params: %v\n", err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(data, &params); err != nil {
+			fmt.Printf("Error parsing params: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	vars := make(map[string]any)
+	if *workdir != "" {
+		stored, err := taskkit.NewFileVarStore(filepath.Join(*workdir, "vars.yaml")).Load()
+		if errors.Is(err, taskkit.ErrMalformedVars) {
+			fmt.Printf("Warning: ignoring vars: %v\n", err)
+		} else if err != nil {
+			fmt.Printf("Error loading vars: %v\n", err)
+			os.Exit(1)
+		}
+		vars = stored
+	}
+	for k, v := range setVars {
+		vars[k] = v
+	}
+
+	plan, err := wf.PlanFor(taskkit.PlanInputs{
+		Vars:           vars,
+		Params:         params,
+		ParamOverrides: paramOverrides,
+		ParamEnvPrefix: *paramEnvPrefix,
+	})
+	if err != nil {
+		fmt.Printf("Error planning workflow: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Plan for %s (%d steps):\n", wf.Name, len(plan))
+	for _, sp := range plan {
+		line := fmt.Sprintf("  %-11s %s (%s)", sp.Action, sp.Step, sp.Handler)
+		if sp.Reason != "" {
+			line += ": " + sp.Reason
+		}
+		fmt.Println(line)
+	}
+}
+
+func replayStep(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	stepName := fs.String("step", "", "Name of the step to replay")
+	fromDir := fs.String("from", "", "Workdir of the run that recorded the inputs")
+	workdir := fs.String("workdir", "", "Working directory for the handler (defaults to --from)")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+	fs.BoolVar(verbose, "v", false, "Enable verbose logging (shorthand)")
+	allowRedacted := fs.Bool("allow-redacted", false, "Replay even if sensitive values were redacted when recorded")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *stepName == "" || *fromDir == "" {
+		fmt.Println("Error: --step and --from are required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+	if *workdir == "" {
+		*workdir = *fromDir
+	}
+
+	deps := taskkit.Deps{
+		Workdir: *workdir,
+		Logger: func(format string, args ...any) {
+			if *verbose {
+				fmt.Printf("[DEBUG] "+format+"\n", args...)
+			}
+		},
+	}
+
+	rec, err := taskkit.LoadRecordedInput(*fromDir, *stepName)
+	if err != nil {
+		fmt.Printf("Error replaying step: %v\n", err)
+		os.Exit(2)
+	}
+	if rec.Redacted && *allowRedacted {
+		fmt.Println("Warning: sensitive values were redacted when recorded; the handler receives *** in their place")
+	}
+
+	result, err := rec.Replay(deps, *allowRedacted)
+	if errors.Is(err, taskkit.ErrRedactedInput) {
+		fmt.Printf("Error replaying step: %v (rerun with --allow-redacted to replay anyway)\n", err)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Printf("Error replaying step: %v\n", err)
 		os.Exit(2)
 	}
+
+	fmt.Println(taskkit.ToJSON(result))
+	if result.HasErrors() {
+		os.Exit(1)
+	}
 }
 
-func listHandlers() {
+func workflowStatus(args []string) {
+	fs := flag.NewFlagSet("workflow status", flag.ExitOnError)
+	workdir := fs.String("workdir", "", "Working directory of the run")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *workdir == "" {
+		fmt.Println("Error: --workdir is required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	state, err := taskkit.LoadRunState(*workdir)
+	if err != nil {
+		// Runs without --write-state only leave the final result behind
+		var result *taskkit.ExecutionResult
+		resultErr := err
+		for _, ext := range []string{".json", ".yaml"} {
+			result, resultErr = taskkit.LoadExecutionResult(filepath.Join(*workdir, taskkit.ResultFileBase+ext))
+			if resultErr == nil {
+				break
+			}
+		}
+		if resultErr != nil {
+			fmt.Printf("Error: no run state or result in %s\n", *workdir)
+			os.Exit(1)
+		}
+		fmt.Printf("Workflow %s: %s (%d steps, finished %s)\n",
+			result.WorkflowName, result.Result, len(result.Steps), result.EndTime.Format(time.RFC3339))
+		return
+	}
+
+	fmt.Printf("Workflow %s: %s (%d/%d steps, pid %d)\n", state.Workflow, state.Status, state.Completed, state.Total, state.PID)
+	if state.CurrentStep != "" {
+		fmt.Printf("  Current step: %s\n", state.CurrentStep)
+	}
+	fmt.Printf("  Started: %s, updated %s ago\n", state.StartedAt.Format(time.RFC3339), time.Since(state.UpdatedAt).Round(time.Second))
+	for _, step := range state.Steps {
+		fmt.Printf("  %-10s %s (%s)\n", step.Status, step.Name, step.Duration)
+	}
+}
+
+func showResult(args []string) {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	from := fs.String("from", "", "Path to execution-result.json")
+	sinceFailure := fs.Bool("since-failure", false, "Start at the first failed step")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *from == "" {
+		fmt.Println("Error: --from is required")
+		fs.PrintDefaults()
+		os.Exit(1)
+	}
+
+	result, err := taskkit.LoadExecutionResult(*from)
+	if err != nil {
+		fmt.Printf("Error loading result: %v\n", err)
+		os.Exit(1)
+	}
+	taskkit.RenderResult(os.Stdout, *result, taskkit.RenderOptions{SinceFailure: *sinceFailure})
+}
+
+func listHandlers(args []string) {
+	fs := flag.NewFlagSet("list-handlers", flag.ExitOnError)
+	exportPath := fs.String("export", "", "Write the handler list to this file as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Printf("Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *exportPath != "" {
+		if err := taskkit.ExportHandlers(*exportPath); err != nil {
+			fmt.Printf("Error exporting handlers: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Exported %d handlers to %s\n", taskkit.HandlerCount(), *exportPath)
+		return
+	}
+
 	handlers := taskkit.ListHandlers()
+	if len(handlers) == 0 {
+		fmt.Printf("Warning: %v\n", taskkit.ErrNoHandlers)
+		return
+	}
 	fmt.Printf("Registered step handlers (%d):\n", len(handlers))
 	for _, name := range handlers {
 		fmt.Printf("  - %s\n", name)
 	}
 }
+
+func listModules() {
+	modules := taskkit.Modules()
+	fmt.Printf("Compiled-in modules (%d):\n", len(modules))
+	registered := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		registered[m.Package] = true
+		fmt.Printf("  - %s (%s): %s\n", m.Name, m.Package, strings.Join(m.Handlers, ", "))
+	}
+	for _, pkg := range moduleManifest {
+		if !registered[pkg] {
+			fmt.Printf("  - %s (%s): no handlers registered\n", filepath.Base(pkg), pkg)
+		}
+	}
+}
+
+func testHandlers() {
+	if taskkit.HandlerCount() == 0 {
+		fmt.Printf("Error: %v\n", taskkit.ErrNoHandlers)
+		os.Exit(1)
+	}
+	results := taskkit.RunSelfTests()
+	fmt.Printf("Handler self-tests (%d):\n", len(results))
+	failed := 0
+	for _, r := range results {
+		if r.Passed {
+			fmt.Printf("  PASS %s (%s)\n", r.Handler, r.Duration)
+		} else {
+			failed++
+			fmt.Printf("  FAIL %s (%s): %s\n", r.Handler, r.Duration, r.Error)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d self-tests failed\n", failed, len(results))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func TestProfilesWrittenForFailedRun(t *testing.T) {
+	snap := taskkit.SnapshotRegistry()
+	t.Cleanup(func() { taskkit.RestoreRegistry(snap) })
+	taskkit.Register("test-busy", func(taskkit.StepInput, taskkit.Deps) taskkit.StepResult {
+		sum := 0
+		for deadline := time.Now().Add(200 * time.Millisecond); time.Now().Before(deadline); {
+			sum++
+		}
+		result := taskkit.NewStepResult()
+		result.SetOutput("iterations", sum)
+		result.AddError("busy work failed", "test")
+		return result
+	})
+
+	dir := t.TempDir()
+	workflow := filepath.Join(dir, "workflow.yaml")
+	if err := os.WriteFile(workflow, []byte("name: busy\nplatform: test\nsteps:\n  - name: busy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cpuPath := filepath.Join(dir, "cpu.pprof")
+	memPath := filepath.Join(dir, "mem.pprof")
+
+	stop, err := startProfiling(cpuPath, memPath)
+	if err != nil {
+		t.Fatalf("startProfiling: %v", err)
+	}
+	runner, err := taskkit.NewLocalRunner(taskkit.LocalRunnerConfig{
+		WorkflowPath: workflow,
+		Workdir:      dir,
+		Stdout:       io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("NewLocalRunner: %v", err)
+	}
+	if result := runner.Run(); result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+	stop()
+
+	for _, path := range []string{cpuPath, memPath} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Errorf("profile not written: %v", err)
+			continue
+		}
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", filepath.Base(path))
+		}
+	}
+}
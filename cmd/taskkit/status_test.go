@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout returns what fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestWorkflowStatusInProgress(t *testing.T) {
+	workdir := t.TempDir()
+	state := `{
+  "workflow": "nightly-backup",
+  "pid": 4242,
+  "status": "Running",
+  "started_at": "2026-10-14T02:00:00Z",
+  "updated_at": "2026-10-14T02:05:00Z",
+  "current_step": "snapshot",
+  "completed": 1,
+  "total": 3,
+  "steps": [{"name": "prepare", "status": "Succeeded", "duration": "2s"}]
+}`
+	if err := os.WriteFile(filepath.Join(workdir, "run-state.json"), []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() { workflowStatus([]string{"--workdir", workdir}) })
+	for _, want := range []string{
+		"Workflow nightly-backup: Running (1/3 steps, pid 4242)",
+		"Current step: snapshot",
+		"Started: 2026-10-14T02:00:00Z",
+		"Succeeded  prepare (2s)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorkflowStatusFinishedResult(t *testing.T) {
+	workdir := t.TempDir()
+	result := `{"result": "Failed", "workflow_name": "nightly-backup", "end_time": "2026-10-14T02:30:00Z", "steps": [{"name": "prepare"}, {"name": "snapshot"}]}`
+	if err := os.WriteFile(filepath.Join(workdir, "execution-result.json"), []byte(result), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() { workflowStatus([]string{"--workdir", workdir}) })
+	if want := "Workflow nightly-backup: Failed (2 steps, finished 2026-10-14T02:30:00Z)"; !strings.Contains(out, want) {
+		t.Errorf("status output = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,63 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnchoredParamsReachEveryStep(t *testing.T) {
+	seen := map[string]map[string]any{}
+	record := func(name string) StepHandler {
+		return func(input StepInput, deps Deps) StepResult {
+			seen[name] = input.Params
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-web": record("web"),
+		"test-api": record("api"),
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: anchors
+platform: test
+x-defaults: &defaults
+  region: eu-west
+  replicas: 2
+steps:
+  - name: web
+    params:
+      <<: *defaults
+  - name: api
+    params:
+      <<: *defaults
+      replicas: 4
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s (%s), want Succeeded", result.Result, result.ErrorMessage)
+	}
+	if want := map[string]any{"region": "eu-west", "replicas": 2}; !reflect.DeepEqual(seen["web"], want) {
+		t.Errorf("web params = %v, want %v", seen["web"], want)
+	}
+	// Keys set beside the merge key override the anchor's
+	if want := map[string]any{"region": "eu-west", "replicas": 4}; !reflect.DeepEqual(seen["api"], want) {
+		t.Errorf("api params = %v, want %v", seen["api"], want)
+	}
+}
+
+func TestAnchoredStepFieldsValidatedPerStep(t *testing.T) {
+	err := loadError(t, `
+name: anchors
+platform: test
+x-retry: &retry
+  retries: -1
+steps:
+  - name: web
+  - name: api
+    <<: *retry
+`)
+	if err == nil || !strings.Contains(err.Error(), `step "api": retries must not be negative`) {
+		t.Errorf("LoadWorkflow error = %v, want the inherited value reported under api", err)
+	}
+}
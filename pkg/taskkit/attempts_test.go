@@ -0,0 +1,41 @@
+package taskkit
+
+import "testing"
+
+func TestAttemptHistory(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-flaky": func(StepInput, Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			if calls < 3 {
+				result.AddError("connection reset", "test")
+			}
+			return result
+		},
+	})
+	result, _ := runTestWorkflow(t, `
+name: attempts
+platform: test
+steps:
+  - name: flaky
+    retries: 3
+`, LocalRunnerConfig{})
+
+	flaky := stepByName(t, result, "flaky")
+	if flaky.Status != "Succeeded" {
+		t.Fatalf("flaky = %s, want Succeeded", flaky.Status)
+	}
+	if len(flaky.Attempts) != 3 {
+		t.Fatalf("attempts = %+v, want 3 records", flaky.Attempts)
+	}
+	for i, want := range []string{"Failed", "Failed", "Succeeded"} {
+		a := flaky.Attempts[i]
+		if a.Attempt != i+1 || a.Status != want || a.Duration == "" {
+			t.Errorf("attempt %d = %+v, want #%d %s with a duration", i, a, i+1, want)
+		}
+		if wantErr := map[bool]string{true: "connection reset"}[want == "Failed"]; a.Error != wantErr {
+			t.Errorf("attempt %d error = %q, want %q", i+1, a.Error, wantErr)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package taskkit
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// JitterStrategy selects how randomness is applied to retry delays
+type JitterStrategy string
+
+const (
+	// JitterNone waits the full exponential delay: min(max, base*2^n)
+	JitterNone JitterStrategy = "none"
+	// JitterFull waits a random delay in [0, min(max, base*2^n))
+	JitterFull JitterStrategy = "full"
+	// JitterEqual waits half the exponential delay plus a random delay in [0, half)
+	JitterEqual JitterStrategy = "equal"
+	// JitterDecorrelated waits a random delay in [base, prev*3), capped at max
+	JitterDecorrelated JitterStrategy = "decorrelated"
+)
+
+// BackoffConfig controls the delay between retry attempts
+type BackoffConfig struct {
+	BaseSeconds float64        `yaml:"base_seconds"`
+	MaxSeconds  float64        `yaml:"max_seconds,omitempty"`
+	Jitter      JitterStrategy `yaml:"jitter,omitempty"` // defaults to full
+}
+
+// Validate checks the backoff settings
+func (b BackoffConfig) Validate() error {
+	if b.BaseSeconds < 0 || b.MaxSeconds < 0 {
+		return fmt.Errorf("backoff seconds must not be negative")
+	}
+	switch b.Jitter {
+	case "", JitterNone, JitterFull, JitterEqual, JitterDecorrelated:
+		return nil
+	default:
+		return fmt.Errorf("unknown jitter strategy %q", b.Jitter)
+	}
+}
+
+// Delay returns the wait before the given retry (1 for the first retry).
+// prev is the previous delay and is only used by decorrelated jitter.
+func (b BackoffConfig) Delay(retry int, prev time.Duration, rng *rand.Rand) time.Duration {
+	base := time.Duration(b.BaseSeconds * float64(time.Second))
+	if base <= 0 || retry < 1 {
+		return 0
+	}
+	limit := time.Duration(b.MaxSeconds * float64(time.Second))
+	if limit <= 0 {
+		limit = time.Duration(1<<63 - 1)
+	}
+
+	// Exponential ceiling, guarding against overflow
+	ceiling := base
+	for i := 1; i < retry && ceiling < limit; i++ {
+		ceiling *= 2
+	}
+	if ceiling > limit || ceiling <= 0 {
+		ceiling = limit
+	}
+
+	switch b.Jitter {
+	case JitterNone:
+		return ceiling
+	case JitterEqual:
+		half := ceiling / 2
+		return half + randDuration(rng, ceiling-half)
+	case JitterDecorrelated:
+		if prev < base {
+			prev = base
+		}
+		upper := prev * 3
+		if upper > limit || upper <= 0 {
+			upper = limit
+		}
+		return base + randDuration(rng, upper-base)
+	default:
+		return randDuration(rng, ceiling)
+	}
+}
+
+// randDuration returns a random duration in [0, n)
+func randDuration(rng *rand.Rand, n time.Duration) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(rng.Int63n(int64(n)))
+}
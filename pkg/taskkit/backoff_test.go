@@ -0,0 +1,103 @@
+package taskkit
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelayBounds(t *testing.T) {
+	const base, limit = time.Second, 10 * time.Second
+	ceiling := func(retry int) time.Duration {
+		d := base << (retry - 1)
+		if d > limit {
+			d = limit
+		}
+		return d
+	}
+	tests := []struct {
+		jitter JitterStrategy
+		bounds func(retry int, prev time.Duration) (time.Duration, time.Duration)
+	}{
+		{JitterNone, func(retry int, _ time.Duration) (time.Duration, time.Duration) {
+			return ceiling(retry), ceiling(retry)
+		}},
+		{JitterFull, func(retry int, _ time.Duration) (time.Duration, time.Duration) {
+			return 0, ceiling(retry)
+		}},
+		{JitterEqual, func(retry int, _ time.Duration) (time.Duration, time.Duration) {
+			return ceiling(retry) / 2, ceiling(retry)
+		}},
+		{JitterDecorrelated, func(_ int, prev time.Duration) (time.Duration, time.Duration) {
+			if prev < base {
+				prev = base
+			}
+			upper := prev * 3
+			if upper > limit {
+				upper = limit
+			}
+			return base, upper
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.jitter), func(t *testing.T) {
+			b := BackoffConfig{BaseSeconds: 1, MaxSeconds: 10, Jitter: tt.jitter}
+			rng := rand.New(rand.NewSource(42))
+			for trial := 0; trial < 200; trial++ {
+				var prev time.Duration
+				for retry := 1; retry <= 6; retry++ {
+					d := b.Delay(retry, prev, rng)
+					lo, hi := tt.bounds(retry, prev)
+					// none waits exactly the ceiling; the others stay below hi
+					if d < lo || d > hi || (lo != hi && d == hi) {
+						t.Fatalf("retry %d delay %s outside [%s, %s)", retry, d, lo, hi)
+					}
+					prev = d
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffDefaultsToFullJitter(t *testing.T) {
+	full := BackoffConfig{BaseSeconds: 1, Jitter: JitterFull}
+	unset := BackoffConfig{BaseSeconds: 1}
+	a, b := rand.New(rand.NewSource(7)), rand.New(rand.NewSource(7))
+	for retry := 1; retry <= 5; retry++ {
+		if got, want := unset.Delay(retry, 0, a), full.Delay(retry, 0, b); got != want {
+			t.Errorf("retry %d: unset jitter = %s, full = %s", retry, got, want)
+		}
+	}
+}
+
+func TestBackoffDeterministicWithSeed(t *testing.T) {
+	b := BackoffConfig{BaseSeconds: 0.5, MaxSeconds: 30, Jitter: JitterDecorrelated}
+	delays := func() []time.Duration {
+		rng := rand.New(rand.NewSource(99))
+		var out []time.Duration
+		var prev time.Duration
+		for retry := 1; retry <= 5; retry++ {
+			prev = b.Delay(retry, prev, rng)
+			out = append(out, prev)
+		}
+		return out
+	}
+	first, second := delays(), delays()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("delays differ with the same seed: %v vs %v", first, second)
+		}
+	}
+}
+
+func TestBackoffValidate(t *testing.T) {
+	if err := (BackoffConfig{BaseSeconds: 1, Jitter: "random"}).Validate(); err == nil {
+		t.Error("unknown jitter accepted")
+	}
+	if err := (BackoffConfig{BaseSeconds: -1}).Validate(); err == nil {
+		t.Error("negative base accepted")
+	}
+	if d := (BackoffConfig{}).Delay(1, 0, rand.New(rand.NewSource(1))); d != 0 {
+		t.Errorf("zero base delay = %s, want 0", d)
+	}
+}
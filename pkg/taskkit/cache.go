@@ -0,0 +1,86 @@
+package taskkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// memoKey hashes a step's handler, merged params, and the named vars. Map
+// keys are sorted by encoding/json, so equal inputs always produce the same
+// key.
+func memoKey(handler string, input StepInput, varNames []string) (string, error) {
+	vars := make(map[string]any, len(varNames))
+	for _, name := range varNames {
+		if v, ok := input.Vars[name]; ok {
+			vars[name] = v
+		}
+	}
+	data, err := json.Marshal(map[string]any{
+		"handler": handler,
+		"params":  input.Params,
+		"vars":    vars,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash step input: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CacheKeyParam is the param whose value, when set, caches a step's result
+// under that key instead of an input hash (e.g. a git SHA)
+const CacheKeyParam = "cache_key"
+
+// explicitCacheKey hashes a user-supplied cache key with the step and
+// handler, so steps sharing a cache_key value do not reuse each other's
+// results
+func explicitCacheKey(handler, step string, key any) (string, error) {
+	data, err := json.Marshal(map[string]any{
+		"handler":   handler,
+		"step":      step,
+		"cache_key": fmt.Sprint(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cachePath returns where a cached step result is stored in the workdir
+func cachePath(workdir, key string) string {
+	return filepath.Join(workdir, "cache", key+".json")
+}
+
+// loadCachedResult returns a previously stored result for key, if any
+func loadCachedResult(workdir, key string) (StepResult, bool) {
+	data, err := os.ReadFile(cachePath(workdir, key))
+	if err != nil {
+		return StepResult{}, false
+	}
+	var result StepResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return StepResult{}, false
+	}
+	return result, true
+}
+
+// storeCachedResult writes a step result to the cache
+func storeCachedResult(workdir, key string, result StepResult) error {
+	path := cachePath(workdir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached result: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package taskkit
+
+import "testing"
+
+const cacheKeyWorkflow = `
+name: cache-key
+platform: test
+steps:
+  - name: build
+`
+
+func TestCacheKeyControlsReuse(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(input StepInput, deps Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			result.SetOutput("image", "app:"+input.GetParamString("tag"))
+			return result
+		},
+	})
+	workdir := t.TempDir()
+	run := func(key, tag string) StepExec {
+		t.Helper()
+		result, _ := runTestWorkflow(t, cacheKeyWorkflow, LocalRunnerConfig{
+			Workdir:        workdir,
+			ParamOverrides: map[string]any{CacheKeyParam: key, "tag": tag},
+		})
+		return stepByName(t, result, "build")
+	}
+
+	if got := run("abc123", "v1").Status; got != "Succeeded" {
+		t.Fatalf("first run status = %s, want Succeeded", got)
+	}
+
+	// Only the key decides reuse, even when other params change
+	hit := run("abc123", "v2")
+	if hit.Status != "Cached" || calls != 1 {
+		t.Errorf("same key status = %s after %d calls, want Cached after 1", hit.Status, calls)
+	}
+	if hit.Output["image"] != "app:v1" {
+		t.Errorf("cached output = %v, want the first run's image", hit.Output)
+	}
+
+	miss := run("def456", "v2")
+	if miss.Status != "Succeeded" || calls != 2 || miss.Output["image"] != "app:v2" {
+		t.Errorf("changed key = %s %v after %d calls, want a fresh run", miss.Status, miss.Output, calls)
+	}
+}
@@ -0,0 +1,323 @@
+package taskkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a parsed `when` expression. The grammar is deliberately
+// small:
+//
+//	expr    := and ("||" and)*
+//	and     := unary ("&&" unary)*
+//	unary   := "!" unary | "(" expr ")" | compare
+//	compare := operand (("==" | "!=") operand)?
+//	operand := vars.<path> | params.<path> | 'string' | "string" | number | true | false | null
+//
+// A bare operand is true unless it is missing, null, false, "", or 0.
+type Condition struct {
+	source string
+	root   condNode
+}
+
+// ParseCondition parses a `when` expression
+func ParseCondition(expr string) (*Condition, error) {
+	p := &condParser{tokens: tokenizeCondition(expr)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid condition %q: unexpected %q", expr, p.tokens[p.pos])
+	}
+	return &Condition{source: expr, root: root}, nil
+}
+
+// String returns the original expression
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Eval evaluates the condition against vars and params
+func (c *Condition) Eval(vars, params map[string]any) bool {
+	return truthy(c.root.eval(condScope{vars: vars, params: params}))
+}
+
+// Refs returns the top-level names the condition reads under root
+// ("vars" or "params"), e.g. "env" for params.env.name
+func (c *Condition) Refs(root string) []string {
+	var names []string
+	var walk func(n condNode)
+	walk = func(n condNode) {
+		switch n := n.(type) {
+		case orNode:
+			walk(n.left)
+			walk(n.right)
+		case andNode:
+			walk(n.left)
+			walk(n.right)
+		case notNode:
+			walk(n.inner)
+		case compareNode:
+			walk(n.left)
+			walk(n.right)
+		case refNode:
+			if n.root == root && len(n.path) > 0 {
+				names = append(names, n.path[0])
+			}
+		}
+	}
+	walk(c.root)
+	return names
+}
+
+type condScope struct {
+	vars   map[string]any
+	params map[string]any
+}
+
+type condNode interface {
+	eval(scope condScope) any
+}
+
+type (
+	orNode      struct{ left, right condNode }
+	andNode     struct{ left, right condNode }
+	notNode     struct{ inner condNode }
+	compareNode struct {
+		left, right condNode
+		negate      bool
+	}
+	literalNode struct{ value any }
+	refNode     struct {
+		root string
+		path []string
+	}
+)
+
+func (n orNode) eval(s condScope) any  { return truthy(n.left.eval(s)) || truthy(n.right.eval(s)) }
+func (n andNode) eval(s condScope) any { return truthy(n.left.eval(s)) && truthy(n.right.eval(s)) }
+func (n notNode) eval(s condScope) any { return !truthy(n.inner.eval(s)) }
+
+func (n compareNode) eval(s condScope) any {
+	eq := condEqual(n.left.eval(s), n.right.eval(s))
+	if n.negate {
+		return !eq
+	}
+	return eq
+}
+
+func (n literalNode) eval(condScope) any { return n.value }
+
+func (n refNode) eval(s condScope) any {
+	root := s.vars
+	if n.root == "params" {
+		root = s.params
+	}
+	v, ok := lookupPath(root, n.path)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// truthy applies the condition language's truthiness rules
+func truthy(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	default:
+		if f, ok := toFloat(v); ok {
+			return f != 0
+		}
+		return true
+	}
+}
+
+// condEqual compares numerically when both sides are numbers, otherwise by
+// their string form
+func condEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		return af == bf
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+type condParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *condParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *condParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *condParser) parseOr() (condNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseAnd() (condNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *condParser) parseUnary() (condNode, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	case "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.peek(); op == "==" || op == "!=" {
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{left: left, right: right, negate: op == "!="}, nil
+	}
+	return left, nil
+}
+
+func (p *condParser) parseOperand() (condNode, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case tok == "true":
+		return literalNode{true}, nil
+	case tok == "false":
+		return literalNode{false}, nil
+	case tok == "null":
+		return literalNode{nil}, nil
+	case tok[0] == '\'' || tok[0] == '"':
+		if len(tok) < 2 || tok[len(tok)-1] != tok[0] {
+			return nil, fmt.Errorf("unterminated string %s", strings.TrimSuffix(tok, "\x00"))
+		}
+		return literalNode{tok[1 : len(tok)-1]}, nil
+	case strings.HasPrefix(tok, "vars.") || strings.HasPrefix(tok, "params."):
+		root, path, _ := strings.Cut(tok, ".")
+		return refNode{root: root, path: strings.Split(path, ".")}, nil
+	}
+	if f, err := strconv.ParseFloat(tok, 64); err == nil {
+		return literalNode{f}, nil
+	}
+	return nil, fmt.Errorf("unknown operand %q (use vars.<name> or params.<name>)", tok)
+}
+
+// tokenizeCondition splits an expression into operators, quoted strings,
+// and bare words. An unterminated quote yields a token the parser rejects.
+func tokenizeCondition(expr string) []string {
+	var tokens []string
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case c == '!' || c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				tokens = append(tokens, expr[i:]+"\x00")
+				return tokens
+			}
+			tokens = append(tokens, expr[i:i+end+2])
+			i += end + 2
+		default:
+			j := i
+			for j < len(expr) && !unicode.IsSpace(rune(expr[j])) && !strings.ContainsRune("()!=&|'\"", rune(expr[j])) {
+				j++
+			}
+			if j == i {
+				// Lone operator character such as a single '=' or '&'
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
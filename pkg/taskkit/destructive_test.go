@@ -0,0 +1,59 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const destructiveWorkflow = `
+name: wipe-disks
+platform: test
+destructive: true
+steps:
+  - name: wipe
+`
+
+func TestDestructiveWorkflowRequiresConfirmation(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm bool
+		env     string
+		wantErr bool
+	}{
+		{"unconfirmed", false, "", true},
+		{"env not 1", false, "yes", true},
+		{"flag", true, "", false},
+		{"env", false, "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := false
+			registerHandlers(t, map[string]StepHandler{
+				"test-wipe": func(StepInput, Deps) StepResult {
+					ran = true
+					return NewStepResult()
+				},
+			})
+			t.Setenv(ConfirmEnv, tt.env)
+
+			r, err := NewLocalRunner(LocalRunnerConfig{
+				WorkflowPath: writeFile(t, t.TempDir(), "workflow.yaml", destructiveWorkflow),
+				Workdir:      t.TempDir(),
+				Confirm:      tt.confirm,
+				Stdout:       &strings.Builder{},
+			})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "marked destructive: rerun with --confirm") {
+					t.Errorf("NewLocalRunner error = %v, want a confirmation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewLocalRunner: %v", err)
+			}
+			if result := r.Run(); result.Result != "Succeeded" || !ran {
+				t.Errorf("confirmed run = %s (ran %v), want Succeeded", result.Result, ran)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDumpParamsPrecedence(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": succeed})
+	for _, key := range []string{"A", "B", "C", "D"} {
+		t.Setenv("TASKKIT_DUMP_"+key, "env")
+	}
+	paramsPath := writeFile(t, t.TempDir(), "params.json", `{"a": "file", "b": "file", "c": "file"}`)
+
+	_, out := runTestWorkflow(t, `
+name: dump-params
+platform: test
+steps:
+  - name: deploy
+    params:
+      a: step
+      b: step
+`, LocalRunnerConfig{
+		DumpParams:     true,
+		ParamEnvPrefix: "TASKKIT_DUMP_",
+		ParamsPath:     paramsPath,
+		ParamOverrides: map[string]any{"a": "override"},
+	})
+
+	_, dump, ok := strings.Cut(out, "  Params:\n")
+	if !ok {
+		t.Fatalf("no params dump in output:\n%s", out)
+	}
+	var params map[string]any
+	if err := json.NewDecoder(strings.NewReader(dump)).Decode(&params); err != nil {
+		t.Fatalf("params dump is not JSON: %v\n%s", err, out)
+	}
+	// Each key comes from the highest layer that sets it: env fallback,
+	// then --params, then step params, then --param
+	want := map[string]any{"a": "override", "b": "step", "c": "file", "d": "env"}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("dumped params = %v, want %v", params, want)
+	}
+}
+
+func TestDumpParamsOff(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": succeed})
+	_, out := runTestWorkflow(t, `
+name: dump-params
+platform: test
+steps:
+  - name: deploy
+    params:
+      a: step
+`, LocalRunnerConfig{})
+	if strings.Contains(out, "Params:") {
+		t.Errorf("params dumped without DumpParams or Verbose:\n%s", out)
+	}
+}
@@ -0,0 +1,57 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStepEnv(t *testing.T) {
+	t.Setenv("TASKKIT_TEST_HOME", "/home/ci")
+	envs := map[string]map[string]string{}
+	handler := func(input StepInput, deps Deps) StepResult {
+		envs[input.StepName] = deps.Env
+		return NewStepResult()
+	}
+	registerHandlers(t, map[string]StepHandler{"test-build": handler, "test-deploy": handler})
+
+	result, _ := runTestWorkflow(t, `
+name: env
+platform: test
+env:
+  REGION: us-east
+  LOG_LEVEL: info
+steps:
+  - name: build
+  - name: deploy
+    env:
+      LOG_LEVEL: debug
+      KUBECONFIG: ${ENV:TASKKIT_TEST_HOME}/.kube/config
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	want := map[string]map[string]string{
+		"build": {"REGION": "us-east", "LOG_LEVEL": "info"},
+		// Step env overrides the workflow's, and tokens are interpolated
+		"deploy": {"REGION": "us-east", "LOG_LEVEL": "debug", "KUBECONFIG": "/home/ci/.kube/config"},
+	}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("handler env = %v, want %v", envs, want)
+	}
+}
+
+func TestStepEnvMissingVariable(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed})
+	result, _ := runTestWorkflow(t, `
+name: env
+platform: test
+steps:
+  - name: build
+    env:
+      TOKEN: ${ENV:TASKKIT_TEST_UNSET_VARIABLE}
+`, LocalRunnerConfig{})
+	build := stepByName(t, result, "build")
+	if build.Status != "Failed" || build.FailureKind != FailureEnv {
+		t.Errorf("build = %s/%s, want Failed/%s", build.Status, build.FailureKind, FailureEnv)
+	}
+}
@@ -0,0 +1,89 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const executionPlanWorkflow = `
+name: execution-plan
+platform: test
+steps:
+  - name: setup
+    template: init
+  - name: fetch
+  - name: lint
+  - name: build
+    depends: [fetch]
+  - name: test
+    depends: [build]
+    soft_depends: [lint, absent]
+  - name: report
+    template: finalize
+`
+
+func TestExecutionPlan(t *testing.T) {
+	plan, err := loadTestWorkflow(t, executionPlanWorkflow).Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Workflow != "execution-plan" {
+		t.Errorf("workflow = %q, want execution-plan", plan.Workflow)
+	}
+
+	// Independent steps share a batch; a chain takes one batch per link
+	wantBatches := [][]string{{"setup"}, {"fetch", "lint"}, {"build"}, {"test"}, {"report"}}
+	if !reflect.DeepEqual(plan.Batches, wantBatches) {
+		t.Errorf("batches = %v, want %v", plan.Batches, wantBatches)
+	}
+
+	want := []PlannedStep{
+		{Name: "setup", Handler: "test-setup", Template: TemplateInit, Batch: 0},
+		{Name: "fetch", Handler: "test-fetch", Batch: 1, WaitsFor: []string{"setup"}},
+		{Name: "lint", Handler: "test-lint", Batch: 1, WaitsFor: []string{"setup"}},
+		{Name: "build", Handler: "test-build", Batch: 2, WaitsFor: []string{"fetch", "setup"}},
+		// An absent soft dependency is not waited for
+		{Name: "test", Handler: "test-test", Batch: 3, WaitsFor: []string{"build", "lint", "setup"}},
+		{Name: "report", Handler: "test-report", Template: TemplateFinalize, Batch: 4,
+			WaitsFor: []string{"setup", "fetch", "lint", "build", "test"}},
+	}
+	if len(plan.Steps) != len(want) {
+		t.Fatalf("steps = %+v, want %d", plan.Steps, len(want))
+	}
+	for i, step := range plan.Steps {
+		step.Definition = WorkflowStep{}
+		if !reflect.DeepEqual(step, want[i]) {
+			t.Errorf("step %d = %+v, want %+v", i, step, want[i])
+		}
+	}
+	if plan.Steps[3].Definition.Name != "build" {
+		t.Errorf("build definition = %+v, want the declared step", plan.Steps[3].Definition)
+	}
+}
+
+func TestExecutionPlanErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps string
+		want  string
+	}{
+		{"unknown dependency", `
+  - name: build
+    depends: [missing]`, "missing"},
+		{"cycle", `
+  - name: a
+    depends: [b]
+  - name: b
+    depends: [a]`, "dependency cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf := loadTestWorkflow(t, "name: bad\nplatform: test\nsteps:"+tt.steps+"\n")
+			plan, err := wf.Plan()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Plan() = %+v, %v, want an error mentioning %q", plan, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package taskkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Outcomes used as ExitCodeMap keys
+const (
+	OutcomeSucceeded             = "Succeeded"
+	OutcomeSucceededWithWarnings = "SucceededWithWarnings"
+	OutcomeSkipped               = "Skipped"
+	OutcomeNoOp                  = "NoOp"
+	OutcomeFailed                = "Failed"
+	OutcomeError                 = "Error"
+)
+
+// ExitCodeMap maps run outcomes to process exit codes
+type ExitCodeMap map[string]int
+
+// DefaultExitCodeMap returns the standard mapping: success 0, failure 1, error 2
+func DefaultExitCodeMap() ExitCodeMap {
+	return ExitCodeMap{
+		OutcomeSucceeded:             0,
+		OutcomeSucceededWithWarnings: 0,
+		OutcomeSkipped:               0,
+		OutcomeNoOp:                  0,
+		OutcomeFailed:                1,
+		OutcomeError:                 2,
+	}
+}
+
+// Outcome classifies an execution result into one of the Outcome* values
+func Outcome(result ExecutionResult) string {
+	switch result.Result {
+	case "Succeeded":
+		allSkipped := len(result.Steps) > 0
+		hasWarnings := len(result.Warnings) > 0
+		for _, step := range result.Steps {
+			if step.Status != "Skipped" {
+				allSkipped = false
+			}
+			for _, msg := range step.Messages {
+				if msg.Severity == SeverityWarning {
+					hasWarnings = true
+				}
+			}
+		}
+		if allSkipped {
+			return OutcomeSkipped
+		}
+		if hasWarnings {
+			return OutcomeSucceededWithWarnings
+		}
+		return OutcomeSucceeded
+	case "NoOp":
+		return OutcomeNoOp
+	case "Failed":
+		return OutcomeFailed
+	default:
+		return OutcomeError
+	}
+}
+
+// Code returns the exit code for a result. Outcomes missing from the map
+// fall back to the Succeeded code for success variants and to the Error
+// code (or 2) otherwise.
+func (m ExitCodeMap) Code(result ExecutionResult) int {
+	outcome := Outcome(result)
+	if code, ok := m[outcome]; ok {
+		return code
+	}
+	if outcome == OutcomeSucceededWithWarnings || outcome == OutcomeSkipped || outcome == OutcomeNoOp {
+		if code, ok := m[OutcomeSucceeded]; ok {
+			return code
+		}
+		return 0
+	}
+	if code, ok := m[OutcomeError]; ok {
+		return code
+	}
+	return 2
+}
+
+// Set parses an "outcome=code" pair into the map
+func (m ExitCodeMap) Set(spec string) error {
+	outcome, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return fmt.Errorf("invalid exit code mapping %q, expected outcome=code", spec)
+	}
+	switch outcome {
+	case OutcomeSucceeded, OutcomeSucceededWithWarnings, OutcomeSkipped, OutcomeNoOp, OutcomeFailed, OutcomeError:
+	default:
+		return fmt.Errorf("unknown outcome %q", outcome)
+	}
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid exit code %q: %w", value, err)
+	}
+	m[outcome] = code
+	return nil
+}
@@ -0,0 +1,60 @@
+package taskkit
+
+import "testing"
+
+func TestExitCodeMap(t *testing.T) {
+	succeeded := StepExec{Name: "build", Status: "Succeeded"}
+	warned := StepExec{Name: "build", Status: "Succeeded", Messages: []Message{{Severity: SeverityWarning, Text: "slow"}}}
+	skipped := StepExec{Name: "build", Status: "Skipped"}
+
+	results := map[string]ExecutionResult{
+		OutcomeSucceeded:             {Result: "Succeeded", Steps: []StepExec{succeeded}},
+		OutcomeSucceededWithWarnings: {Result: "Succeeded", Steps: []StepExec{warned}},
+		OutcomeSkipped:               {Result: "Succeeded", Steps: []StepExec{skipped}},
+		OutcomeNoOp:                  {Result: "NoOp", Steps: []StepExec{skipped}},
+		OutcomeFailed:                {Result: "Failed", Steps: []StepExec{succeeded}},
+		OutcomeError:                 {Result: "Error"},
+	}
+	configured := ExitCodeMap{}
+	for _, spec := range []string{"Succeeded=0", "SucceededWithWarnings=3", "Skipped=4", "NoOp=5", "Failed=6", "Error=7"} {
+		if err := configured.Set(spec); err != nil {
+			t.Fatalf("Set(%q): %v", spec, err)
+		}
+	}
+	wantConfigured := map[string]int{
+		OutcomeSucceeded: 0, OutcomeSucceededWithWarnings: 3, OutcomeSkipped: 4,
+		OutcomeNoOp: 5, OutcomeFailed: 6, OutcomeError: 7,
+	}
+	wantDefault := map[string]int{
+		OutcomeSucceeded: 0, OutcomeSucceededWithWarnings: 0, OutcomeSkipped: 0,
+		OutcomeNoOp: 0, OutcomeFailed: 1, OutcomeError: 2,
+	}
+	for outcome, result := range results {
+		if got := Outcome(result); got != outcome {
+			t.Errorf("Outcome = %s, want %s", got, outcome)
+		}
+		if got := configured.Code(result); got != wantConfigured[outcome] {
+			t.Errorf("configured code for %s = %d, want %d", outcome, got, wantConfigured[outcome])
+		}
+		if got := DefaultExitCodeMap().Code(result); got != wantDefault[outcome] {
+			t.Errorf("default code for %s = %d, want %d", outcome, got, wantDefault[outcome])
+		}
+	}
+
+	// Unmapped success variants fall back to Succeeded, others to Error
+	partial := ExitCodeMap{OutcomeSucceeded: 9, OutcomeError: 8}
+	if got := partial.Code(results[OutcomeSucceededWithWarnings]); got != 9 {
+		t.Errorf("unmapped warnings code = %d, want 9", got)
+	}
+	if got := partial.Code(results[OutcomeFailed]); got != 8 {
+		t.Errorf("unmapped failure code = %d, want 8", got)
+	}
+}
+
+func TestExitCodeMapSetErrors(t *testing.T) {
+	for _, spec := range []string{"Succeeded", "Partial=3", "Failed=x"} {
+		if err := (ExitCodeMap{}).Set(spec); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", spec)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package taskkit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestHandlerRequestedExitCode(t *testing.T) {
+	ran := map[string]bool{}
+	record := func(name string, h StepHandler) StepHandler {
+		return func(input StepInput, deps Deps) StepResult {
+			ran[name] = true
+			return h(input, deps)
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-health": record("health", func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.ExitWith(42, "service degraded")
+			return result
+		}),
+		"test-deploy": record("deploy", succeed),
+		"test-report": record("report", succeed),
+	})
+
+	workdir := t.TempDir()
+	result, _ := runTestWorkflow(t, `
+name: exit-code
+platform: test
+steps:
+  - name: health
+  - name: deploy
+    depends: [health]
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{Workdir: workdir})
+
+	if result.ExitCode == nil || *result.ExitCode != 42 || result.ExitReason != "service degraded" {
+		t.Fatalf("exit = %v %q, want 42 service degraded", result.ExitCode, result.ExitReason)
+	}
+	// Only finalize steps run after an exit request
+	if ran["deploy"] || !ran["report"] {
+		t.Errorf("ran = %v, want report but not deploy", ran)
+	}
+
+	saved, err := LoadExecutionResult(filepath.Join(workdir, "execution-result.json"))
+	if err != nil {
+		t.Fatalf("result not persisted: %v", err)
+	}
+	if saved.ExitCode == nil || *saved.ExitCode != 42 {
+		t.Errorf("persisted exit code = %v, want 42", saved.ExitCode)
+	}
+}
+
+func TestRequestedExitCode(t *testing.T) {
+	result := NewStepResult()
+	if _, ok := result.RequestedExitCode(); ok {
+		t.Error("new result requests an exit")
+	}
+	result.ExitWith(3, "partial")
+	if code, ok := result.RequestedExitCode(); !ok || code != 3 {
+		t.Errorf("RequestedExitCode = %d, %v; want 3", code, ok)
+	}
+	// FlowControl set directly, e.g. after a JSON round trip
+	result.FlowControl["exit_code"] = float64(7)
+	if code, ok := result.RequestedExitCode(); !ok || code != 7 {
+		t.Errorf("RequestedExitCode from float = %d, %v; want 7", code, ok)
+	}
+}
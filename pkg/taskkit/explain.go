@@ -0,0 +1,81 @@
+package taskkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// explainRun describes why a step is about to run, given the statuses of
+// the steps before it
+func (r *LocalRunner) explainRun(step WorkflowStep, statuses map[string]string) string {
+	var parts []string
+	switch step.Template {
+	case TemplateInit:
+		parts = append(parts, "init step, runs before other steps")
+	case TemplateFinalize:
+		if r.workflowResult == "Running" {
+			parts = append(parts, "finalize step, always runs last (no failures so far)")
+		} else {
+			parts = append(parts, fmt.Sprintf("finalize step, always runs last (workflow %s so far)", r.workflowResult))
+		}
+	}
+
+	if len(step.Depends) > 0 {
+		deps := make([]string, 0, len(step.Depends))
+		label := "dependencies satisfied: "
+		for _, dep := range step.Depends {
+			status := statuses[dep]
+			if status != "Succeeded" && status != "Cached" {
+				label = "dependencies finished: "
+			}
+			deps = append(deps, fmt.Sprintf("%s (%s)", dep, status))
+		}
+		parts = append(parts, label+strings.Join(deps, ", "))
+	}
+	var after []string
+	for _, dep := range step.SoftDepends {
+		if status, ok := statuses[dep]; ok {
+			after = append(after, fmt.Sprintf("%s (%s)", dep, status))
+		}
+	}
+	if len(after) > 0 {
+		parts = append(parts, "ordered after soft dependencies: "+strings.Join(after, ", "))
+	}
+
+	for _, name := range sortedKeys(step.IfStepStatus) {
+		parts = append(parts, fmt.Sprintf("if_step_status met: %s is %s", name, statuses[name]))
+	}
+	if step.When != "" {
+		parts = append(parts, fmt.Sprintf("condition %q is true", step.When))
+	}
+	if len(r.config.Tags) > 0 || len(r.config.SkipTags) > 0 {
+		if step.Template == TemplateFinalize {
+			parts = append(parts, "tag filters do not apply to finalize steps")
+		} else {
+			parts = append(parts, fmt.Sprintf("tags %v pass the tag filters", step.Tags))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "no dependencies or conditions"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// explainSkip expands a skip reason for --explain
+func (r *LocalRunner) explainSkip(reason string) string {
+	if reason == skipUpstreamFailure {
+		return "an earlier step failed and failure_policy is skip-to-finalize"
+	}
+	return reason
+}
+
+// printRationale prints and clears the pending --explain line, right after
+// a step's header
+func (r *LocalRunner) printRationale() {
+	if r.rationale == "" {
+		return
+	}
+	r.stepHeader("  Why: %s\n", r.rationale)
+	r.rationale = ""
+}
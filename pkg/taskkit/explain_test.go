@@ -0,0 +1,72 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const explainWorkflow = `
+name: explain
+platform: test
+failure_policy: skip-to-finalize
+steps:
+  - name: build
+  - name: test
+    depends: [build]
+  - name: deploy
+    depends: [test]
+    when: vars.env == "prod"
+  - name: report
+    template: finalize
+`
+
+func TestExplainRationale(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build":  succeed,
+		"test-test":   failWith("2 tests failed"),
+		"test-deploy": succeed,
+		"test-report": succeed,
+	})
+
+	_, out := runTestWorkflow(t, explainWorkflow, LocalRunnerConfig{Explain: true})
+	for _, want := range []string{
+		"--- Step: build (handler: test-build) ---\n  Why: no dependencies or conditions\n",
+		"--- Step: test (handler: test-test) ---\n  Why: dependencies satisfied: build (Succeeded)\n",
+		"--- Step: deploy (skipped: upstream failure) ---\n  Why: an earlier step failed and failure_policy is skip-to-finalize\n",
+		"--- Step: report (handler: test-report) ---\n  Why: finalize step, always runs last (workflow Failed so far)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	_, out = runTestWorkflow(t, explainWorkflow, LocalRunnerConfig{})
+	if strings.Contains(out, "Why:") {
+		t.Errorf("rationale printed without Explain:\n%s", out)
+	}
+}
+
+func TestExplainConditions(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build":  succeed,
+		"test-test":   succeed,
+		"test-deploy": succeed,
+		"test-report": succeed,
+	})
+
+	for env, want := range map[string]string{
+		"prod":    `Why: dependencies satisfied: test (Succeeded); condition "vars.env == \"prod\"" is true`,
+		"staging": "--- Step: deploy (skipped: ",
+	} {
+		result, out := runTestWorkflow(t, explainWorkflow, LocalRunnerConfig{Explain: true, SetVars: map[string]any{"env": env}})
+		if !strings.Contains(out, want) {
+			t.Errorf("env %s: output missing %q:\n%s", env, want, out)
+		}
+		if env == "staging" {
+			reason := stepByName(t, result, "deploy").SkipReason
+			if !strings.Contains(out, "  Why: "+reason+"\n") {
+				t.Errorf("skip rationale does not give the reason %q:\n%s", reason, out)
+			}
+		}
+	}
+}
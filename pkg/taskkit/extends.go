@@ -0,0 +1,123 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveExtends loads the chain of base workflows named by extends,
+// starting at the child in path, and returns the merged document as YAML
+// along with the raw bytes of every file in the chain (child first).
+//
+// The child specializes its base: top-level values replace the base's,
+// maps (env, default_params, vars, ...) are merged key by key, and a child
+// step with the same name as a base step is merged into it the same way,
+// so a child can override just one param of an inherited step. New child
+// steps are appended after the base steps.
+func resolveExtends(path string) ([]byte, []byte, error) {
+	doc, sources, err := loadExtendsChain(path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(doc, "extends")
+	merged, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal extended workflow: %w", err)
+	}
+	return merged, sources, nil
+}
+
+func loadExtendsChain(path string, chain []string) (map[string]any, []byte, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve workflow path: %w", err)
+	}
+	for _, seen := range chain {
+		if seen == abs {
+			return nil, nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), abs)
+		}
+	}
+	chain = append(chain, abs)
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+	doc := make(map[string]any)
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse workflow YAML %s: %w", abs, err)
+	}
+
+	base, _ := doc["extends"].(string)
+	if base == "" {
+		return doc, data, nil
+	}
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(abs), base)
+	}
+	baseDoc, baseData, err := loadExtendsChain(base, chain)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mergeWorkflowDocs(baseDoc, doc), append(data, baseData...), nil
+}
+
+// mergeWorkflowDocs overlays child onto base, merging steps by name
+func mergeWorkflowDocs(base, child map[string]any) map[string]any {
+	merged := mergeMaps(base, child)
+	baseSteps, _ := base["steps"].([]any)
+	childSteps, ok := child["steps"].([]any)
+	if !ok {
+		if baseSteps != nil {
+			merged["steps"] = baseSteps
+		}
+		return merged
+	}
+
+	steps := make([]any, 0, len(baseSteps)+len(childSteps))
+	index := make(map[string]int)
+	for _, s := range baseSteps {
+		if m, ok := s.(map[string]any); ok {
+			if name, ok := m["name"].(string); ok {
+				index[name] = len(steps)
+			}
+		}
+		steps = append(steps, s)
+	}
+	for _, s := range childSteps {
+		m, ok := s.(map[string]any)
+		name, named := m["name"].(string)
+		if i, exists := index[name]; ok && named && exists {
+			if baseStep, ok := steps[i].(map[string]any); ok {
+				steps[i] = mergeMaps(baseStep, m)
+				continue
+			}
+		}
+		steps = append(steps, s)
+	}
+	merged["steps"] = steps
+	return merged
+}
+
+// mergeMaps returns base with overlay's keys applied, merging nested maps
+// recursively; any other overlay value replaces the base value
+func mergeMaps(base, overlay map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		if om, ok := v.(map[string]any); ok {
+			if bm, ok := out[k].(map[string]any); ok {
+				out[k] = mergeMaps(bm, om)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
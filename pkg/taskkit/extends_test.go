@@ -0,0 +1,73 @@
+package taskkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const baseWorkflow = `
+name: deploy-base
+platform: test
+default_params:
+  region: eu-west
+steps:
+  - name: build
+    params:
+      target: linux
+      cache: true
+  - name: deploy
+    depends: [build]
+    params:
+      replicas: 1
+`
+
+func TestExtendsOverridesBaseSteps(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.yaml", baseWorkflow)
+	wf, err := LoadWorkflow(writeFile(t, dir, "prod.yaml", `
+extends: base.yaml
+name: deploy-prod
+steps:
+  - name: deploy
+    params:
+      replicas: 3
+  - name: verify
+    depends: [deploy]
+`))
+	if err != nil {
+		t.Fatalf("LoadWorkflow: %v", err)
+	}
+
+	if wf.Name != "deploy-prod" || wf.Platform != "test" || wf.DefaultParams["region"] != "eu-west" {
+		t.Errorf("workflow = %s/%s %v, want the child name with inherited settings", wf.Name, wf.Platform, wf.DefaultParams)
+	}
+	var names []string
+	steps := map[string]WorkflowStep{}
+	for _, step := range wf.Steps {
+		names = append(names, step.Name)
+		steps[step.Name] = step
+	}
+	// Overridden steps keep their base position; new steps are appended
+	if want := []string{"build", "deploy", "verify"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("steps = %v, want %v", names, want)
+	}
+	if want := map[string]any{"target": "linux", "cache": true}; !reflect.DeepEqual(steps["build"].Params, want) {
+		t.Errorf("build params = %v, want the base params %v", steps["build"].Params, want)
+	}
+	if steps["deploy"].Params["replicas"] != 3 || !reflect.DeepEqual(steps["deploy"].Depends, []string{"build"}) {
+		t.Errorf("deploy = %+v, want replicas 3 with the base depends", steps["deploy"])
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "extends: b.yaml\nname: a\nplatform: test\nsteps:\n  - name: build\n")
+	writeFile(t, dir, "b.yaml", "extends: a.yaml\nname: b\nplatform: test\nsteps:\n  - name: build\n")
+
+	_, err := LoadWorkflow(filepath.Join(dir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("LoadWorkflow error = %v, want an extends cycle", err)
+	}
+}
@@ -0,0 +1,73 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFailStepsInjectFailures(t *testing.T) {
+	called := map[string]int{}
+	record := func(name string) StepHandler {
+		return func(StepInput, Deps) StepResult {
+			called[name]++
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy":   record("deploy"),
+		"test-verify":   record("verify"),
+		"test-rollback": record("rollback"),
+		"test-report":   record("report"),
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: inject
+platform: test
+failure_policy: skip-to-finalize
+steps:
+  - name: deploy
+    retries: 2
+  - name: verify
+  - name: rollback
+    if_step_status:
+      deploy: Failed
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{FailSteps: map[string]string{"deploy": "registry unavailable"}})
+	if result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+
+	deploy := stepByName(t, result, "deploy")
+	if deploy.Status != "Failed" || deploy.FailureKind != FailureInjected {
+		t.Errorf("deploy = %s/%s, want Failed/%s", deploy.Status, deploy.FailureKind, FailureInjected)
+	}
+	// Every attempt fails without reaching the handler
+	if len(deploy.Attempts) != 3 || called["deploy"] != 0 {
+		t.Errorf("deploy made %d attempts with %d handler calls, want 3 and 0", len(deploy.Attempts), called["deploy"])
+	}
+	if len(deploy.Messages) == 0 || !strings.Contains(deploy.Messages[len(deploy.Messages)-1].Text, "Injected failure: registry unavailable") {
+		t.Errorf("deploy messages = %+v, want the injected failure", deploy.Messages)
+	}
+
+	// The failure policy and failure handlers react as to a real failure
+	statuses := stepStatuses(result)
+	if statuses["verify"] != "Skipped" || called["verify"] != 0 {
+		t.Errorf("verify = %s, want Skipped by skip-to-finalize", statuses["verify"])
+	}
+	if statuses["rollback"] != "Succeeded" || statuses["report"] != "Succeeded" {
+		t.Errorf("rollback, report = %s, %s, want both Succeeded", statuses["rollback"], statuses["report"])
+	}
+}
+
+func TestFailStepsUnknownStep(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": succeed})
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath: writeFile(t, t.TempDir(), "workflow.yaml", "name: inject\nplatform: test\nsteps:\n  - name: deploy\n"),
+		Workdir:      t.TempDir(),
+		FailSteps:    map[string]string{"deplyo": "boom"},
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown step "deplyo"`) {
+		t.Errorf("NewLocalRunner error = %v, want the unknown step named", err)
+	}
+}
@@ -0,0 +1,91 @@
+package taskkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailureKinds(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-slow": func(input StepInput, deps Deps) StepResult {
+			<-deps.Context.Done()
+			return NewStepResult()
+		},
+		"test-broken": failWith("boom"),
+		"test-panics": func(StepInput, Deps) StepResult {
+			panic("oops")
+		},
+		"test-warns": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.AddWarning("disk almost full", "test")
+			return result
+		},
+		"test-injected": succeed,
+		"test-report":   succeed,
+	})
+
+	// The finalize step keeps the run going past each failure
+	result, _ := runTestWorkflow(t, `
+name: failure-kinds
+platform: test
+timeout_seconds: 1
+steps:
+  - name: slow
+  - name: missing
+  - name: broken
+  - name: panics
+  - name: warns
+  - name: injected
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{
+		AllowMissing:   true,
+		StrictWarnings: true,
+		FailSteps:      map[string]string{"injected": "forced"},
+	})
+
+	want := map[string]FailureKind{
+		"slow":     FailureTimeout,
+		"missing":  FailureHandlerNotFound,
+		"broken":   FailureHandlerError,
+		"panics":   FailurePanic,
+		"warns":    FailureStrictWarning,
+		"injected": FailureInjected,
+	}
+	for name, kind := range want {
+		step := stepByName(t, result, name)
+		if step.Status != "Failed" || step.FailureKind != kind {
+			t.Errorf("step %s = %s/%s, want Failed/%s", name, step.Status, step.FailureKind, kind)
+		}
+		if n := len(step.Attempts); n > 0 && step.Attempts[n-1].FailureKind != kind {
+			t.Errorf("step %s last attempt kind = %s, want %s", name, step.Attempts[n-1].FailureKind, kind)
+		}
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	wf := &WorkflowDefinition{TimeoutSeconds: 30}
+	tests := []struct {
+		name    string
+		step    WorkflowStep
+		attempt int
+		want    time.Duration
+	}{
+		{"workflow default", WorkflowStep{}, 1, 30 * time.Second},
+		{"step overrides workflow", WorkflowStep{TimeoutSeconds: 10}, 1, 10 * time.Second},
+		{"no growth", WorkflowStep{TimeoutSeconds: 10}, 3, 10 * time.Second},
+		{"growth", WorkflowStep{TimeoutSeconds: 10, TimeoutGrowth: 1}, 3, 30 * time.Second},
+		{"growth from workflow default", WorkflowStep{TimeoutGrowth: 0.5}, 3, 60 * time.Second},
+		{"capped", WorkflowStep{TimeoutSeconds: 10, TimeoutGrowth: 1, MaxTimeoutSeconds: 25}, 3, 25 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wf.GetTimeout(tt.step, tt.attempt); got != tt.want {
+				t.Errorf("GetTimeout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	if got := (&WorkflowDefinition{}).GetTimeout(WorkflowStep{}, 1); got != 0 {
+		t.Errorf("GetTimeout without any timeout = %s, want 0", got)
+	}
+}
@@ -0,0 +1,77 @@
+package taskkit
+
+import "testing"
+
+const failurePolicyWorkflow = `
+name: failure-policy
+platform: test
+steps:
+  - name: build
+  - name: deploy
+  - name: announce
+  - name: rollback
+    if_step_status:
+      build: Failed
+  - name: report
+    template: finalize
+`
+
+func TestFailurePolicies(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   map[string]string
+	}{
+		{FailurePolicySkipToFinalize, map[string]string{
+			"build":    "Failed",
+			"deploy":   "Skipped",
+			"announce": "Skipped",
+			"rollback": "Succeeded",
+			"report":   "Succeeded",
+		}},
+		{FailurePolicyContinue, map[string]string{
+			"build":    "Failed",
+			"deploy":   "Succeeded",
+			"announce": "Succeeded",
+			"rollback": "Succeeded",
+			"report":   "Succeeded",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			registerHandlers(t, map[string]StepHandler{
+				"test-build":    failWith("compile error"),
+				"test-deploy":   succeed,
+				"test-announce": succeed,
+				"test-rollback": succeed,
+				"test-report":   succeed,
+			})
+
+			result, _ := runTestWorkflow(t, failurePolicyWorkflow, LocalRunnerConfig{FailurePolicy: tt.policy})
+			if result.Result != "Failed" {
+				t.Errorf("result = %s, want Failed", result.Result)
+			}
+			statuses := stepStatuses(result)
+			for name, want := range tt.want {
+				if statuses[name] != want {
+					t.Errorf("%s status = %s, want %s", name, statuses[name], want)
+				}
+			}
+			if tt.policy == FailurePolicySkipToFinalize {
+				if got := stepByName(t, result, "deploy").SkipReason; got != skipUpstreamFailure {
+					t.Errorf("deploy skip reason = %q, want %q", got, skipUpstreamFailure)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownFailurePolicy(t *testing.T) {
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath:  writeFile(t, t.TempDir(), "workflow.yaml", failurePolicyWorkflow),
+		Workdir:       t.TempDir(),
+		FailurePolicy: "halt",
+	})
+	if err == nil {
+		t.Error("NewLocalRunner accepted an unknown failure policy")
+	}
+}
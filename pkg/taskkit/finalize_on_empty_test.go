@@ -0,0 +1,47 @@
+package taskkit
+
+import "testing"
+
+func TestFinalizeOnEmpty(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name         string
+		setting      *bool
+		deployRuns   bool
+		wantFinalize string
+	}{
+		{"default runs finalize", nil, false, "Succeeded"},
+		{"true runs finalize", &yes, false, "Succeeded"},
+		{"false skips finalize", &no, false, "Skipped"},
+		{"false still finalizes real work", &no, true, "Succeeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerHandlers(t, map[string]StepHandler{"test-deploy": succeed, "test-report": succeed})
+			result, _ := runTestWorkflow(t, `
+name: noop
+platform: test
+steps:
+  - name: deploy
+    when: vars.deploy == true
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{FinalizeOnEmpty: tt.setting, SetVars: map[string]any{"deploy": tt.deployRuns}})
+
+			wantResult := "NoOp"
+			if tt.deployRuns {
+				wantResult = "Succeeded"
+			}
+			if result.Result != wantResult {
+				t.Errorf("result = %s, want %s", result.Result, wantResult)
+			}
+			report := stepByName(t, result, "report")
+			if report.Status != tt.wantFinalize {
+				t.Errorf("report = %s, want %s", report.Status, tt.wantFinalize)
+			}
+			if tt.wantFinalize == "Skipped" && report.SkipReason != "no init or action step ran" {
+				t.Errorf("skip reason = %q", report.SkipReason)
+			}
+		})
+	}
+}
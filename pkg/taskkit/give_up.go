@@ -0,0 +1,58 @@
+package taskkit
+
+import (
+	"fmt"
+	"time"
+)
+
+// GiveUpStepName is the StepInput.StepName the on_step_give_up handler runs as
+const GiveUpStepName = ReservedPrefix + "give_up"
+
+// runGiveUp invokes the workflow's on_step_give_up handler for a step that
+// failed on every attempt. The handler receives the failed step's name,
+// messages, error, failure kind, and attempt count as params, with known
+// secrets masked in the messages and error. Its own failure is returned as
+// a warning for the step.
+func (r *LocalRunner) runGiveUp(step WorkflowStep, exec *StepExec, failed StepResult, deps Deps) string {
+	name := r.workflow.OnStepGiveUp
+	handler, ok := Get(name)
+	if !ok {
+		// ResolveHandlers reports this up front unless AllowMissing is set
+		return fmt.Sprintf("on_step_give_up handler not found: %s", name)
+	}
+
+	r.printf("  Giving up on %s after %d attempts, running %s\n", step.Name, len(exec.Attempts), name)
+	// Messages are typed, so redacting the params map would not reach them
+	messages := make([]Message, len(failed.Messages))
+	for i, msg := range failed.Messages {
+		msg.Text = r.redactor.redactText(msg.Text)
+		messages[i] = msg
+	}
+	input := StepInput{
+		StepName:     GiveUpStepName,
+		TaskID:       r.config.TaskID,
+		WorkflowName: r.workflow.Name,
+		Attempt:      1,
+		Params: map[string]any{
+			"step":         step.Name,
+			"handler":      exec.Handler,
+			"messages":     messages,
+			"error":        r.redactor.redactText(attemptError(failed)),
+			"failure_kind": string(exec.FailureKind),
+			"attempts":     len(exec.Attempts),
+		},
+		Vars:     r.vars.Snapshot(),
+		varReads: make(map[string]bool),
+
+		WorkflowResult: r.workflowResult,
+	}
+	timeout := time.Duration(r.workflow.TimeoutSeconds) * time.Second
+	result, _ := r.callHandler(handler, input, deps, timeout)
+	for _, msg := range result.Messages {
+		r.printf("  %s\n", formatMessage(msg))
+	}
+	if result.HasErrors() {
+		return fmt.Sprintf("on_step_give_up handler %s failed: %s", name, attemptError(result))
+	}
+	return ""
+}
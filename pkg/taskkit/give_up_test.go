@@ -0,0 +1,84 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOnStepGiveUp(t *testing.T) {
+	flakyCalls := 0
+	var gaveUp []map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-exhausted": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.AddError("login rejected for "+input.GetParamString("token"), "test")
+			return result
+		},
+		"test-broken": failWith("disk full"),
+		"test-flaky": func(StepInput, Deps) StepResult {
+			flakyCalls++
+			result := NewStepResult()
+			if flakyCalls == 1 {
+				result.AddError("connection reset", "test")
+			}
+			return result
+		},
+		"test-report": succeed,
+		"test-give-up": func(input StepInput, deps Deps) StepResult {
+			gaveUp = append(gaveUp, input.Params)
+			return NewStepResult()
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: give-up
+platform: test
+on_step_give_up: test-give-up
+sensitive: [token]
+retry_backoff:
+  base_seconds: 0.01
+  jitter: none
+steps:
+  - name: exhausted
+    retries: 2
+    params:
+      token: s3cret
+  - name: broken
+  - name: flaky
+    retries: 1
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{})
+	if result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+	if got := stepByName(t, result, "flaky").Status; got != "Succeeded" {
+		t.Fatalf("flaky = %s, want Succeeded on its retry", got)
+	}
+
+	// Once per step that failed every attempt, never for flaky
+	var steps []string
+	for _, params := range gaveUp {
+		steps = append(steps, params["step"].(string))
+	}
+	if want := []string{"exhausted", "broken"}; !reflect.DeepEqual(steps, want) {
+		t.Fatalf("give-up ran for %v, want %v", steps, want)
+	}
+
+	exhausted := gaveUp[0]
+	if exhausted["handler"] != "test-exhausted" || exhausted["attempts"] != 3 {
+		t.Errorf("exhausted params = %v, want handler test-exhausted after 3 attempts", exhausted)
+	}
+	messages, ok := exhausted["messages"].([]Message)
+	if !ok || len(messages) != 1 {
+		t.Fatalf("messages = %#v, want the failed attempt's message", exhausted["messages"])
+	}
+	// The secret from the step's sensitive params is masked
+	if messages[0].Text != "login rejected for ***" || exhausted["error"] != "login rejected for ***" {
+		t.Errorf("message = %q, error = %q, want the token redacted", messages[0].Text, exhausted["error"])
+	}
+	if broken := gaveUp[1]; broken["attempts"] != 1 || !strings.Contains(broken["error"].(string), "disk full") {
+		t.Errorf("broken params = %v, want 1 attempt failing with disk full", broken)
+	}
+}
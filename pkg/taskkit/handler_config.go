@@ -0,0 +1,54 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadHandlerConfig resolves handler_config entries given as a file path
+// (relative to the workflow file) by reading the YAML map it holds, and
+// returns the raw bytes of the files read, in handler name order
+func (w *WorkflowDefinition) loadHandlerConfig(path string) ([]byte, error) {
+	names := make([]string, 0, len(w.HandlerConfig))
+	for name := range w.HandlerConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sources []byte
+	for _, name := range names {
+		entry := w.HandlerConfig[name]
+		switch v := entry.(type) {
+		case map[string]any:
+		case string:
+			file := v
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read handler config for %s: %w", name, err)
+			}
+			config := make(map[string]any)
+			if err := yaml.Unmarshal(data, &config); err != nil {
+				return nil, fmt.Errorf("failed to parse handler config for %s: %w", name, err)
+			}
+			w.HandlerConfig[name] = config
+			sources = append(sources, data...)
+		default:
+			return nil, fmt.Errorf("handler_config for %s must be a map or a file path, got %T", name, entry)
+		}
+	}
+	return sources, nil
+}
+
+// HandlerConfig returns the workflow's handler_config entry for a handler,
+// or nil if it has none. Treat it as read-only.
+func (d Deps) HandlerConfig(name string) map[string]any {
+	config, _ := d.handlerConfig[name].(map[string]any)
+	return config
+}
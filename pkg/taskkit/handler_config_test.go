@@ -0,0 +1,78 @@
+package taskkit
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestHandlerConfig(t *testing.T) {
+	configs := make(map[string]map[string]any)
+	record := func(name string) StepHandler {
+		return func(input StepInput, deps Deps) StepResult {
+			configs[name] = deps.HandlerConfig(name)
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": record("test-deploy"),
+		"test-notify": record("test-notify"),
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: handler-config
+platform: test
+handler_config:
+  test-deploy:
+    cluster: homelab
+    replicas: 2
+steps:
+  - name: deploy
+  - name: notify
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	want := map[string]any{"cluster": "homelab", "replicas": 2}
+	if !reflect.DeepEqual(configs["test-deploy"], want) {
+		t.Errorf("test-deploy config = %v, want %v", configs["test-deploy"], want)
+	}
+	// A handler without a section gets nil, not another handler's section
+	if got, ran := configs["test-notify"]; !ran || got != nil {
+		t.Errorf("test-notify config = %#v (ran %v), want nil", got, ran)
+	}
+}
+
+func TestHandlerConfigFromFile(t *testing.T) {
+	var config map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			config = deps.HandlerConfig("test-deploy")
+			return NewStepResult()
+		},
+	})
+	dir := t.TempDir()
+	writeFile(t, dir, "deploy.yaml", "endpoints:\n  api: https://api.test\n")
+	path := writeFile(t, dir, "workflow.yaml", `
+name: handler-config-file
+platform: test
+handler_config:
+  test-deploy: deploy.yaml
+steps:
+  - name: deploy
+`)
+
+	// The file path is relative to the workflow
+	r, err := NewLocalRunner(LocalRunnerConfig{WorkflowPath: path, Workdir: t.TempDir(), Stdout: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := r.Run(); result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	want := map[string]any{"endpoints": map[string]any{"api": "https://api.test"}}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want %v", config, want)
+	}
+}
@@ -0,0 +1,49 @@
+package taskkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const hashWorkflow = `
+name: hashed
+platform: test
+steps:
+  - name: build
+`
+
+func TestWorkflowHash(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed})
+	sum := sha256.Sum256([]byte(hashWorkflow))
+	want := hex.EncodeToString(sum[:])
+
+	if got := loadTestWorkflow(t, hashWorkflow).Hash; got != want {
+		t.Errorf("Hash = %s, want the SHA-256 of the file, %s", got, want)
+	}
+	if got := loadTestWorkflow(t, hashWorkflow).Hash; got != want {
+		t.Errorf("Hash changed between loads: %s", got)
+	}
+	if got := loadTestWorkflow(t, hashWorkflow+"  - name: deploy\n").Hash; got == want {
+		t.Error("Hash unchanged after editing the workflow")
+	}
+
+	// A matching expected hash runs, in either case, and is recorded
+	result, _ := runTestWorkflow(t, hashWorkflow, LocalRunnerConfig{ExpectHash: strings.ToUpper(want)})
+	if result.Result != "Succeeded" || result.WorkflowHash != want {
+		t.Errorf("result = %s with hash %s, want Succeeded with %s", result.Result, result.WorkflowHash, want)
+	}
+}
+
+func TestExpectHashMismatch(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed})
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath: writeFile(t, t.TempDir(), "workflow.yaml", hashWorkflow),
+		Workdir:      t.TempDir(),
+		ExpectHash:   strings.Repeat("0", 64),
+	})
+	if err == nil || !strings.Contains(err.Error(), "workflow hash mismatch") {
+		t.Errorf("NewLocalRunner error = %v, want a hash mismatch", err)
+	}
+}
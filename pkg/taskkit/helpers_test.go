@@ -0,0 +1,106 @@
+package taskkit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// registerHandlers registers handlers for the duration of the test
+func registerHandlers(t *testing.T, handlers map[string]StepHandler) {
+	t.Helper()
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	for name, h := range handlers {
+		Register(name, h)
+	}
+}
+
+// writeFile writes content to name under dir and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadTestWorkflow writes workflow to a temp dir and loads it
+func loadTestWorkflow(t *testing.T, workflow string) *WorkflowDefinition {
+	t.Helper()
+	wf, err := LoadWorkflow(writeFile(t, t.TempDir(), "workflow.yaml", workflow))
+	if err != nil {
+		t.Fatalf("LoadWorkflow: %v", err)
+	}
+	return wf
+}
+
+// newTestRunner writes workflow to a temp dir and creates a runner for it.
+// Unset Workdir and Seed get a temp dir and a fixed seed, and console
+// output goes to the returned buffer.
+func newTestRunner(t *testing.T, workflow string, config LocalRunnerConfig) (*LocalRunner, *bytes.Buffer) {
+	t.Helper()
+	config.WorkflowPath = writeFile(t, t.TempDir(), "workflow.yaml", workflow)
+	if config.Workdir == "" {
+		config.Workdir = t.TempDir()
+	}
+	if config.Seed == 0 {
+		config.Seed = 1
+	}
+	out := &bytes.Buffer{}
+	config.Stdout = out
+	r, err := NewLocalRunner(config)
+	if err != nil {
+		t.Fatalf("NewLocalRunner: %v", err)
+	}
+	return r, out
+}
+
+// runTestWorkflow runs workflow with config, returning the result and the
+// console output
+func runTestWorkflow(t *testing.T, workflow string, config LocalRunnerConfig) (ExecutionResult, string) {
+	t.Helper()
+	r, out := newTestRunner(t, workflow, config)
+	result := r.Run()
+	return result, out.String()
+}
+
+// succeed is a handler that always succeeds
+func succeed(StepInput, Deps) StepResult {
+	return NewStepResult()
+}
+
+// failWith returns a handler that always fails with message
+func failWith(message string) StepHandler {
+	return func(StepInput, Deps) StepResult {
+		result := NewStepResult()
+		result.AddError(message, "test")
+		return result
+	}
+}
+
+// stepByName returns the recorded step with the given name
+func stepByName(t *testing.T, result ExecutionResult, name string) StepExec {
+	t.Helper()
+	for _, step := range result.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	t.Fatalf("step %q not in result", name)
+	return StepExec{}
+}
+
+// stepStatuses maps each recorded step's name to its status
+func stepStatuses(result ExecutionResult) map[string]string {
+	statuses := make(map[string]string, len(result.Steps))
+	for _, step := range result.Steps {
+		statuses[step.Name] = step.Status
+	}
+	return statuses
+}
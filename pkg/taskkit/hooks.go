@@ -0,0 +1,86 @@
+package taskkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// runWithHooks runs a step's pre hook, its handler attempts, then its post
+// hook. Hook failures are warnings unless the step sets
+// fail_on_hook_error, in which case a failed pre hook skips the handler
+// and a failed post hook fails the step.
+func (r *LocalRunner) runWithHooks(step WorkflowStep, handler StepHandler, input StepInput, deps Deps, exec *StepExec) StepResult {
+	var warnings []string
+	if step.Pre != "" {
+		if err := r.runHook("pre", step.Pre, deps); err != nil {
+			message := fmt.Sprintf("pre hook failed: %v", err)
+			if step.FailOnHookError {
+				result := NewStepResult()
+				result.AddError(message, "hook")
+				exec.Status = "Failed"
+				exec.FailureKind = FailureHook
+				return result
+			}
+			warnings = append(warnings, message)
+		}
+	}
+
+	result := r.runAttempts(step, handler, input, deps, exec)
+
+	// The post hook runs whatever the handler's outcome, like a cleanup
+	if step.Post != "" {
+		if err := r.runHook("post", step.Post, deps); err != nil {
+			message := fmt.Sprintf("post hook failed: %v", err)
+			if step.FailOnHookError && exec.Status == "Succeeded" {
+				result.AddError(message, "hook")
+				exec.Status = "Failed"
+				exec.FailureKind = FailureHook
+			} else {
+				warnings = append(warnings, message)
+			}
+		}
+	}
+
+	for _, w := range warnings {
+		result.AddWarning(w, "hook")
+	}
+	return result
+}
+
+// runHook runs a hook command through the platform shell in the workdir,
+// with the step env added and output streamed like handler output. The
+// command is killed if the run's context is cancelled.
+func (r *LocalRunner) runHook(kind, command string, deps Deps) error {
+	ctx := deps.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.printf("  Running %s hook: %s\n", kind, command)
+
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = deps.Workdir
+	cmd.Env = os.Environ()
+	for k, v := range deps.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = deps.Stdout
+	cmd.Stderr = deps.Stdout
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// shellCommand wraps command in sh -c, or cmd /C on Windows
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,75 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// hookWorkflow runs a step whose pre and post hooks append to hooks.log
+func hookWorkflow(pre string, failOnHookError bool) string {
+	return fmt.Sprintf(`
+name: hooks
+platform: test
+steps:
+  - name: deploy
+    pre: %q
+    post: "echo post >> hooks.log"
+    fail_on_hook_error: %v
+`, pre, failOnHookError)
+}
+
+func runHookWorkflow(t *testing.T, pre string, failOnHookError bool) (StepExec, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands use POSIX shell syntax")
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			f, err := os.OpenFile(filepath.Join(deps.Workdir, "hooks.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+			if err == nil {
+				fmt.Fprintln(f, "handler")
+				f.Close()
+			}
+			return NewStepResult()
+		},
+	})
+	workdir := t.TempDir()
+	result, _ := runTestWorkflow(t, hookWorkflow(pre, failOnHookError), LocalRunnerConfig{Workdir: workdir})
+	log, _ := os.ReadFile(filepath.Join(workdir, "hooks.log"))
+	return stepByName(t, result, "deploy"), string(log)
+}
+
+func TestHooksRunAroundHandler(t *testing.T) {
+	step, log := runHookWorkflow(t, "echo pre >> hooks.log", true)
+	if step.Status != "Succeeded" {
+		t.Fatalf("deploy status = %s, want Succeeded", step.Status)
+	}
+	if log != "pre\nhandler\npost\n" {
+		t.Errorf("hooks.log = %q, want pre, handler, post", log)
+	}
+}
+
+func TestFailingPreHook(t *testing.T) {
+	t.Run("aborts", func(t *testing.T) {
+		step, log := runHookWorkflow(t, "exit 3", true)
+		if step.Status != "Failed" || step.FailureKind != FailureHook {
+			t.Errorf("deploy = %s/%s, want Failed/%s", step.Status, step.FailureKind, FailureHook)
+		}
+		if log != "" {
+			t.Errorf("hooks.log = %q, want nothing after the pre hook failed", log)
+		}
+	})
+	t.Run("warns", func(t *testing.T) {
+		step, log := runHookWorkflow(t, "exit 3", false)
+		if step.Status != "Succeeded" || log != "handler\npost\n" {
+			t.Errorf("deploy = %s with log %q, want Succeeded after handler and post", step.Status, log)
+		}
+		if len(step.Messages) == 0 || !strings.Contains(step.Messages[len(step.Messages)-1].Text, "pre hook failed") {
+			t.Errorf("messages = %+v, want a pre hook warning", step.Messages)
+		}
+	})
+}
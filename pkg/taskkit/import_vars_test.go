@@ -0,0 +1,100 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const importVarsWorkflow = `
+name: import-vars
+platform: test
+steps:
+  - name: read
+`
+
+func TestImportVarsSeedsHandlerVars(t *testing.T) {
+	var seen map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-read": func(input StepInput, deps Deps) StepResult {
+			seen = input.Vars
+			return NewStepResult()
+		},
+	})
+
+	dir := t.TempDir()
+	prior := writeFile(t, dir, "execution-result.json", `{
+		"result": "Succeeded",
+		"workflow_name": "upstream",
+		"final_vars": {"image": "app:1.2", "count": 2}
+	}`)
+	workdir := t.TempDir()
+	writeFile(t, workdir, "vars.yaml", "count: 5\n")
+
+	result, _ := runTestWorkflow(t, importVarsWorkflow, LocalRunnerConfig{
+		ImportVarsPath: prior,
+		Workdir:        workdir,
+	})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	if seen["image"] != "app:1.2" {
+		t.Errorf("image = %v, want app:1.2", seen["image"])
+	}
+	// The workdir's vars.yaml takes precedence over imported vars
+	if seen["count"] != 5 {
+		t.Errorf("count = %v (%T), want 5 from vars.yaml", seen["count"], seen["count"])
+	}
+}
+
+func TestImportVarsWithoutUsableFinalVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		warning string
+	}{
+		{"missing", `{"result": "Failed"}`, "no final_vars"},
+		{"null", `{"result": "Failed", "final_vars": null}`, "no final_vars"},
+		{"malformed", `{"result": "Failed", "final_vars": [1, 2]}`, "malformed final_vars"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen map[string]any
+			registerHandlers(t, map[string]StepHandler{
+				"test-read": func(input StepInput, deps Deps) StepResult {
+					seen = input.Vars
+					return NewStepResult()
+				},
+			})
+			prior := writeFile(t, t.TempDir(), "execution-result.json", tt.content)
+
+			result, out := runTestWorkflow(t, importVarsWorkflow, LocalRunnerConfig{ImportVarsPath: prior})
+			if result.Result != "Succeeded" {
+				t.Fatalf("result = %s, want Succeeded", result.Result)
+			}
+			if len(seen) != 0 {
+				t.Errorf("vars = %v, want none", seen)
+			}
+			if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], tt.warning) {
+				t.Errorf("warnings = %q, want one containing %q", result.Warnings, tt.warning)
+			}
+			if !strings.Contains(out, "Warning: ") {
+				t.Errorf("warning not printed through the runner:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestImportVarsUnreadableFile(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-read": succeed})
+	dir := t.TempDir()
+	for _, path := range []string{dir + "/missing.json", writeFile(t, dir, "bad.json", "not json")} {
+		_, err := NewLocalRunner(LocalRunnerConfig{
+			WorkflowPath:   writeFile(t, dir, "workflow.yaml", importVarsWorkflow),
+			Workdir:        dir,
+			ImportVarsPath: path,
+		})
+		if err == nil {
+			t.Errorf("NewLocalRunner with %s succeeded, want error", path)
+		}
+	}
+}
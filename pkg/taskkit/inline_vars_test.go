@@ -0,0 +1,40 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+const inlineVarsWorkflow = `
+name: inline-vars
+platform: test
+vars:
+  region: us-east
+  replicas: 2
+  cluster: homelab
+steps:
+  - name: setup
+`
+
+func TestInlineVars(t *testing.T) {
+	var seen map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-setup": func(input StepInput, deps Deps) StepResult {
+			seen = input.Vars
+			return NewStepResult()
+		},
+	})
+
+	workdir := t.TempDir()
+	writeFile(t, workdir, "vars.yaml", "replicas: 3\ncluster: staging\n")
+	runTestWorkflow(t, inlineVarsWorkflow, LocalRunnerConfig{
+		Workdir: workdir,
+		SetVars: map[string]any{"cluster": "prod"},
+	})
+
+	// vars.yaml overrides inline vars and --set overrides both
+	want := map[string]any{"region": "us-east", "replicas": 3, "cluster": "prod"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("first handler vars = %v, want %v", seen, want)
+	}
+}
@@ -0,0 +1,251 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// TokenDelimiters set the text around ${KIND:arg} interpolation tokens,
+// for content that legitimately contains "${...}". Doubling the first
+// character of Open escapes a token: with the defaults, "$${X}" is left as
+// the literal "${X}".
+type TokenDelimiters struct {
+	Open  string `yaml:"open"`
+	Close string `yaml:"close"`
+}
+
+// Default token delimiters
+const (
+	DefaultTokenOpen  = "${"
+	DefaultTokenClose = "}"
+)
+
+// tokenResolvers resolve the argument of a token by kind
+var tokenResolvers = map[string]func(arg string) (string, error){
+	"ENV":  resolveEnvToken,
+	"FILE": resolveFileToken,
+}
+
+// secretTokens are token kinds whose resolved values are always redacted
+var secretTokens = map[string]bool{
+	"FILE": true,
+}
+
+// tokenSyntax finds tokens and escapes for one set of delimiters
+type tokenSyntax struct {
+	pattern *regexp.Regexp
+	escape  string
+	open    string
+}
+
+// defaultTokens is the syntax for the default delimiters
+var defaultTokens = newTokenSyntax(TokenDelimiters{})
+
+// newTokenSyntax builds the token syntax for d; empty fields use the
+// defaults
+func newTokenSyntax(d TokenDelimiters) *tokenSyntax {
+	if d.Open == "" {
+		d.Open = DefaultTokenOpen
+	}
+	if d.Close == "" {
+		d.Close = DefaultTokenClose
+	}
+	escape := string([]rune(d.Open)[:1]) + d.Open
+	// Escapes are matched first so an escaped token is never resolved
+	pattern := regexp.MustCompile(`(?s)` + regexp.QuoteMeta(escape) + `|` +
+		regexp.QuoteMeta(d.Open) + `([A-Z]+):(.*?)` + regexp.QuoteMeta(d.Close))
+	return &tokenSyntax{pattern: pattern, escape: escape, open: d.Open}
+}
+
+// expand replaces tokens in s and unescapes escaped ones, passing each
+// value resolved from a secret token kind to secret (if non-nil). Tokens of
+// an unknown kind are left untouched. Every token feature goes through it.
+func (t *tokenSyntax) expand(s string, secret func(string)) (string, error) {
+	var firstErr error
+	out := t.pattern.ReplaceAllStringFunc(s, func(token string) string {
+		if token == t.escape {
+			return t.open
+		}
+		m := t.pattern.FindStringSubmatch(token)
+		resolve, ok := tokenResolvers[m[1]]
+		if !ok {
+			return token
+		}
+		v, err := resolve(m[2])
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return token
+		}
+		if secret != nil && secretTokens[m[1]] && v != "" {
+			secret(v)
+		}
+		return v
+	})
+	return out, firstErr
+}
+
+// expandParams returns a copy of params with tokens in string values,
+// including nested ones, replaced
+func (t *tokenSyntax) expandParams(params map[string]any, secret func(string)) (map[string]any, error) {
+	out, err := t.expandValue(params, secret)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]any), nil
+}
+
+func (t *tokenSyntax) expandValue(v any, secret func(string)) (any, error) {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			resolved, err := t.expandValue(child, secret)
+			if err != nil {
+				return nil, fmt.Errorf("param %q: %w", k, err)
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			resolved, err := t.expandValue(child, secret)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case string:
+		return t.expand(node, secret)
+	default:
+		return v, nil
+	}
+}
+
+// stepTemplatePattern matches a {{ steps.<name>.output.<key> }} reference,
+// where key may be a dotted path into the output. Other {{ }} actions are
+// left for handlers that render templates themselves (e.g. render-template
+// bodies using {{ range .steps }}).
+var stepTemplatePattern = regexp.MustCompile(`\{\{-?\s*steps\.([\w-]+)\.output\.([\w-]+(?:\.[\w-]+)*)\s*-?\}\}`)
+
+// templateActionPattern matches any {{ }} action, and stepRefPattern a
+// reference to steps within one (but not a field such as .steps)
+var (
+	templateActionPattern = regexp.MustCompile(`\{\{.*?\}\}`)
+	stepRefPattern        = regexp.MustCompile(`(?:^|[^.\w$])steps\.`)
+)
+
+// checkStepTemplates reports the first {{ }} action in a param value that
+// references steps but is not a plain {{ steps.<name>.output.<key> }}.
+// Pipelines, functions, and conditionals over step outputs are not rendered,
+// so they would otherwise reach the handler as literal text.
+func checkStepTemplates(v any) error {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if err := checkStepTemplates(child); err != nil {
+				return fmt.Errorf("param %q: %w", k, err)
+			}
+		}
+	case []any:
+		for _, child := range node {
+			if err := checkStepTemplates(child); err != nil {
+				return err
+			}
+		}
+	case string:
+		for _, action := range templateActionPattern.FindAllString(node, -1) {
+			if stepRefPattern.MatchString(action) && !stepTemplatePattern.MatchString(action) {
+				return fmt.Errorf("unsupported step output template %s: only {{ steps.<name>.output.<key> }} is rendered", action)
+			}
+		}
+	}
+	return nil
+}
+
+// renderStepTemplates returns a copy of params with every step output
+// reference in a string value, such as "{{ steps.build.output.tag }}",
+// replaced by the recorded output, printed as text/template would.
+// Referencing a step that has not run or an output key it did not record is
+// an error, as is any other action over steps (see checkStepTemplates).
+func renderStepTemplates(params map[string]any, outputs map[string]map[string]any) (map[string]any, error) {
+	if err := checkStepTemplates(params); err != nil {
+		return nil, err
+	}
+	out, err := renderStepValue(params, outputs)
+	if err != nil {
+		return nil, err
+	}
+	return out.(map[string]any), nil
+}
+
+func renderStepValue(v any, outputs map[string]map[string]any) (any, error) {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			rendered, err := renderStepValue(child, outputs)
+			if err != nil {
+				return nil, fmt.Errorf("param %q: %w", k, err)
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			rendered, err := renderStepValue(child, outputs)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	case string:
+		var err error
+		rendered := stepTemplatePattern.ReplaceAllStringFunc(node, func(ref string) string {
+			if err != nil {
+				return ref
+			}
+			m := stepTemplatePattern.FindStringSubmatch(ref)
+			output, ok := outputs[m[1]]
+			if !ok {
+				err = fmt.Errorf("unknown step %q in %s (it has not run)", m[1], ref)
+				return ref
+			}
+			value, ok := lookupPath(output, strings.Split(m[2], "."))
+			if !ok {
+				err = fmt.Errorf("step %q has no output %q", m[1], m[2])
+				return ref
+			}
+			return fmt.Sprint(value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return rendered, nil
+	default:
+		return v, nil
+	}
+}
+
+func resolveFileToken(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func resolveEnvToken(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+	return v, nil
+}
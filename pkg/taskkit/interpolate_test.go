@@ -0,0 +1,285 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fileSecretWorkflow reads the step's env secret from envPath and its param
+// secret from paramPath
+func fileSecretWorkflow(envPath, paramPath string) string {
+	return `
+name: file-secrets
+platform: test
+steps:
+  - name: login
+    env:
+      API_TOKEN: ${FILE:` + envPath + `}
+    params:
+      token: ${FILE:` + paramPath + `}
+      url: https://example.test
+`
+}
+
+func TestFileSecretParamsAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	envSecret := writeFile(t, dir, "env-token", "env-s3cret\n")
+	paramSecret := writeFile(t, dir, "param-token", "param-s3cret\n")
+	var param, env string
+	registerHandlers(t, map[string]StepHandler{
+		"test-login": func(input StepInput, deps Deps) StepResult {
+			param = input.GetParamString("token")
+			env = deps.Env["API_TOKEN"]
+			result := NewStepResult()
+			result.SetOutput("echo", "token is "+param+", env is "+env)
+			result.AddInfo("logged in with "+env, "test")
+			return result
+		},
+	})
+
+	workdir := t.TempDir()
+	result, out := runTestWorkflow(t, fileSecretWorkflow(envSecret, paramSecret), LocalRunnerConfig{
+		Workdir:      workdir,
+		DumpParams:   true,
+		Trace:        true,
+		RecordInputs: true,
+	})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	// Handlers get the trimmed file contents
+	if param != "param-s3cret" || env != "env-s3cret" {
+		t.Errorf("param = %q, env = %q, want the trimmed secrets", param, env)
+	}
+
+	// The secret never reaches the console, the result file, or recorded inputs
+	secrets := []string{"env-s3cret", "param-s3cret"}
+	for _, secret := range secrets {
+		if strings.Contains(out, secret) {
+			t.Errorf("console output leaks %s:\n%s", secret, out)
+		}
+	}
+	for _, name := range []string{"execution-result.json", "inputs/login.json"} {
+		data, err := os.ReadFile(filepath.Join(workdir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s leaks %s:\n%s", name, secret, data)
+			}
+		}
+	}
+	rec, err := LoadRecordedInput(workdir, "login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Redacted || rec.Env["API_TOKEN"] != redactedValue {
+		t.Errorf("recorded env = %v (redacted %v), want API_TOKEN redacted", rec.Env, rec.Redacted)
+	}
+}
+
+func TestFileSecretMissingFile(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-login": succeed})
+	dir := t.TempDir()
+	present := writeFile(t, dir, "token", "value")
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name     string
+		workflow string
+		kind     FailureKind
+	}{
+		{"param", fileSecretWorkflow(present, missing), FailureParams},
+		{"env", fileSecretWorkflow(missing, present), FailureEnv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _ := runTestWorkflow(t, tt.workflow, LocalRunnerConfig{})
+			step := stepByName(t, result, "login")
+			if step.Status != "Failed" || step.FailureKind != tt.kind {
+				t.Fatalf("login = %s/%s, want Failed/%s", step.Status, step.FailureKind, tt.kind)
+			}
+			if !strings.Contains(step.Error, "failed to read secret file") {
+				t.Errorf("error = %q, want a secret file read error", step.Error)
+			}
+		})
+	}
+}
+
+const stepOutputWorkflow = `
+name: step-outputs
+platform: test
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+    params:
+      tag: "{{ steps.build.output.tag }}"
+      image: "{{steps.build.output.image.repo}}:{{ steps.build.output.tag }}"
+      body: |
+        {{ range .steps }}{{ .name }}{{ end }} built {{ steps.build.output.tag }}
+      plain: "{{ .steps }}"
+  - name: report
+    template: finalize
+`
+
+// stepOutputHandlers record deploy's params; build outputs a tag and image
+func stepOutputHandlers(t *testing.T) *map[string]any {
+	t.Helper()
+	params := new(map[string]any)
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("tag", "v1.2")
+			result.SetOutput("image", map[string]any{"repo": "registry/app"})
+			return result
+		},
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			*params = input.Params
+			return NewStepResult()
+		},
+		"test-report": succeed,
+	})
+	return params
+}
+
+func TestStepOutputTemplates(t *testing.T) {
+	params := stepOutputHandlers(t)
+	result, _ := runTestWorkflow(t, stepOutputWorkflow, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	want := map[string]any{
+		"tag":   "v1.2",
+		"image": "registry/app:v1.2",
+		// Only step output references are rendered; the rest of a
+		// render-template body is left for the handler
+		"body":  "{{ range .steps }}{{ .name }}{{ end }} built v1.2\n",
+		"plain": "{{ .steps }}",
+	}
+	for key, value := range want {
+		if (*params)[key] != value {
+			t.Errorf("%s = %q, want %q", key, (*params)[key], value)
+		}
+	}
+}
+
+func TestStepOutputTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{"unknown step", "{{ steps.nope.output.tag }}", `unknown step "nope"`},
+		{"unknown key", "{{ steps.build.output.digest }}", `step "build" has no output "digest"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stepOutputHandlers(t)
+			workflow := strings.Replace(stepOutputWorkflow, "{{ steps.build.output.tag }}\"", tt.ref+"\"", 1)
+			result, _ := runTestWorkflow(t, workflow, LocalRunnerConfig{})
+			deploy := stepByName(t, result, "deploy")
+			if deploy.Status != "Failed" || deploy.FailureKind != FailureParams {
+				t.Fatalf("deploy = %s/%s, want Failed/%s", deploy.Status, deploy.FailureKind, FailureParams)
+			}
+			if !strings.Contains(deploy.Error, tt.want) {
+				t.Errorf("error = %q, want it to contain %q", deploy.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestStepOutputTemplateUnsupported(t *testing.T) {
+	refs := []string{
+		"{{ if steps.build.output.tag }}latest{{ end }}",
+		"{{ steps.build.output.tag | printf \\\"%s-rc\\\" }}",
+		"{{ printf \\\"%s\\\" steps.build.output.tag }}",
+	}
+	for _, ref := range refs {
+		workflow := strings.Replace(stepOutputWorkflow, "{{ steps.build.output.tag }}\"", ref+"\"", 1)
+		_, err := LoadWorkflow(writeFile(t, t.TempDir(), "workflow.yaml", workflow))
+		if err == nil || !strings.Contains(err.Error(), "unsupported step output template") {
+			t.Errorf("LoadWorkflow with %s error = %v, want an unsupported template error", ref, err)
+		}
+	}
+
+	// Overrides are not validated with the workflow, so the step fails
+	params := stepOutputHandlers(t)
+	result, _ := runTestWorkflow(t, stepOutputWorkflow, LocalRunnerConfig{
+		ParamOverrides: map[string]any{"tag": "{{ steps.build.output.tag | upper }}"},
+	})
+	deploy := stepByName(t, result, "deploy")
+	if deploy.Status != "Failed" || deploy.FailureKind != FailureParams || *params != nil {
+		t.Errorf("deploy = %s/%s, want Failed/%s before the handler runs", deploy.Status, deploy.FailureKind, FailureParams)
+	}
+}
+
+// tokenParams runs a one-step workflow with the given delimiters line and
+// params, returning the params the handler received
+func tokenParams(t *testing.T, delimiters, params string) map[string]any {
+	t.Helper()
+	var got map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-echo": func(input StepInput, deps Deps) StepResult {
+			got = input.Params
+			return NewStepResult()
+		},
+	})
+	result, _ := runTestWorkflow(t, `
+name: tokens
+platform: test
+`+delimiters+`
+steps:
+  - name: echo
+    params:
+`+params, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s (%s), want Succeeded", result.Result, result.ErrorMessage)
+	}
+	return got
+}
+
+func TestTokenEscaping(t *testing.T) {
+	t.Setenv("TASKKIT_TEST_REGION", "eu-west")
+	got := tokenParams(t, "", `
+      plain: ${ENV:TASKKIT_TEST_REGION}
+      escaped: $${ENV:TASKKIT_TEST_REGION}
+      mixed: $${ENV:TASKKIT_TEST_REGION} is ${ENV:TASKKIT_TEST_REGION}
+`)
+	want := map[string]any{
+		"plain":   "eu-west",
+		"escaped": "${ENV:TASKKIT_TEST_REGION}",
+		"mixed":   "${ENV:TASKKIT_TEST_REGION} is eu-west",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
+
+func TestCustomTokenDelimiters(t *testing.T) {
+	t.Setenv("TASKKIT_TEST_REGION", "eu-west")
+	got := tokenParams(t, `
+token_delimiters:
+  open: "<<<"
+  close: ">>"`, `
+      custom: <<<ENV:TASKKIT_TEST_REGION>>
+      escaped: <<<<ENV:TASKKIT_TEST_REGION>>
+      default: ${ENV:TASKKIT_TEST_REGION}
+`)
+	want := map[string]any{
+		"custom":  "eu-west",
+		"escaped": "<<<ENV:TASKKIT_TEST_REGION>>",
+		// The default syntax is plain text once the delimiters change
+		"default": "${ENV:TASKKIT_TEST_REGION}",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package taskkit
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Lint rules
+const (
+	LintMissingTemplate    = "missing-template"
+	LintNoFinalize         = "no-finalize"
+	LintHighRetries        = "high-retries"
+	LintMissingDescription = "missing-description"
+	LintUnusedParam        = "unused-param"
+)
+
+// LintMaxRetries is the retry count above which high-retries fires
+const LintMaxRetries = 5
+
+// LintWarning is a best-practice finding for a valid workflow
+type LintWarning struct {
+	Rule    string `json:"rule"`
+	Step    string `json:"step,omitempty"`
+	Message string `json:"message"`
+}
+
+func (l LintWarning) String() string {
+	if l.Step != "" {
+		return fmt.Sprintf("[%s] step %s: %s", l.Rule, l.Step, l.Message)
+	}
+	return fmt.Sprintf("[%s] %s", l.Rule, l.Message)
+}
+
+// Lint returns opinionated warnings for a workflow that already passed
+// Validate, in rule order. The unused-param rule looks handlers up in the
+// registry, so task packages should be imported first.
+func (w *WorkflowDefinition) Lint() []LintWarning {
+	var warnings []LintWarning
+	add := func(rule, step, format string, args ...any) {
+		warnings = append(warnings, LintWarning{Rule: rule, Step: step, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if w.Description == "" {
+		add(LintMissingDescription, "", "workflow has no description")
+	}
+
+	hasFinalize := false
+	for _, step := range w.Steps {
+		if step.Description == "" {
+			add(LintMissingDescription, step.Name, "step has no description")
+		}
+		if step.Template == "" {
+			add(LintMissingTemplate, step.Name, "no template set (defaults to action)")
+		}
+		if step.Template == TemplateFinalize {
+			hasFinalize = true
+		}
+		if step.Retries > LintMaxRetries {
+			add(LintHighRetries, step.Name, "%d retries (more than %d)", step.Retries, LintMaxRetries)
+		}
+	}
+	if !hasFinalize {
+		add(LintNoFinalize, "", "no finalize step, so failures are not reported or cleaned up")
+	}
+	if w.DefaultRetries > LintMaxRetries {
+		add(LintHighRetries, "", "default_retries is %d (more than %d)", w.DefaultRetries, LintMaxRetries)
+	}
+
+	keys := make([]string, 0, len(w.DefaultParams))
+	for key := range w.DefaultParams {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !w.paramReferenced(key) {
+			add(LintUnusedParam, "", "default_params.%s is not referenced by any step or handler", key)
+		}
+	}
+	return warnings
+}
+
+// paramReferenced reports whether a default param reaches something that
+// reads it: an enabled step's when condition, or the handler of an enabled
+// step that does not override it. Handlers that do not declare their params
+// (see HandlerParams) may read any param, so they count as references.
+func (w *WorkflowDefinition) paramReferenced(key string) bool {
+	for _, step := range w.Steps {
+		if step.Disabled {
+			continue
+		}
+		if step.When != "" {
+			if cond, err := ParseCondition(step.When); err == nil {
+				for _, ref := range cond.Refs("params") {
+					if ref == key {
+						return true
+					}
+				}
+			}
+		}
+		if _, overridden := step.Params[key]; overridden {
+			continue
+		}
+		declared, ok := HandlerParams(w.GetHandlerName(step))
+		if !ok {
+			return true
+		}
+		for _, name := range declared {
+			if name == key {
+				return true
+			}
+		}
+	}
+	return false
+}
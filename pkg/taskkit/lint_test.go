@@ -0,0 +1,212 @@
+package taskkit
+
+import (
+	"context"
+	"testing"
+)
+
+type deployParams struct {
+	Image   string `json:"image"`
+	Timeout int    `json:"timeout,omitempty"`
+	Ignored string `json:"-"`
+}
+
+func registerLintHandlers(t *testing.T) {
+	t.Helper()
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	deploy := func(ctx context.Context, p deployParams, deps Deps) (map[string]any, error) {
+		return nil, nil
+	}
+	RegisterTyped("test-deploy", deploy)
+	RegisterTyped("test-verify", deploy)
+	Register("test-report", succeed)
+}
+
+const cleanLintWorkflow = `
+name: clean
+description: Deploys and verifies an image
+platform: test
+default_params:
+  image: app:1.0
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+  - name: report
+    description: Report the result
+    template: finalize
+`
+
+func TestLintCleanWorkflow(t *testing.T) {
+	registerLintHandlers(t)
+	if warnings := loadTestWorkflow(t, cleanLintWorkflow).Lint(); len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     []LintWarning
+	}{
+		{
+			name: "missing template and descriptions",
+			workflow: `
+name: bare
+platform: test
+steps:
+  - name: deploy
+  - name: report
+    description: Report the result
+    template: finalize
+`,
+			want: []LintWarning{
+				{Rule: LintMissingDescription},
+				{Rule: LintMissingDescription, Step: "deploy"},
+				{Rule: LintMissingTemplate, Step: "deploy"},
+			},
+		},
+		{
+			name: "no finalize",
+			workflow: `
+name: no-finalize
+description: Deploys an image
+platform: test
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+`,
+			want: []LintWarning{{Rule: LintNoFinalize}},
+		},
+		{
+			name: "high retries",
+			workflow: `
+name: retries
+description: Deploys an image
+platform: test
+default_retries: 8
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+    retries: 10
+  - name: report
+    description: Report the result
+    template: finalize
+`,
+			want: []LintWarning{
+				{Rule: LintHighRetries, Step: "deploy"},
+				{Rule: LintHighRetries},
+			},
+		},
+		{
+			name: "unused params",
+			workflow: `
+name: params
+description: Deploys an image
+platform: test
+default_params:
+  image: app:1.0
+  region: us-east
+  stage: prod
+  timeout: 30
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+    params:
+      timeout: 60
+  - name: verify
+    description: Check the rollout
+    template: action
+    when: params.stage == 'prod'
+    params:
+      timeout: 60
+  - name: report
+    description: Report the result
+    template: finalize
+    handler: test-verify
+    params:
+      timeout: 60
+`,
+			// region is read by no handler or condition, and every step
+			// overrides timeout; image reaches deploy and stage is in a when
+			want: []LintWarning{
+				{Rule: LintUnusedParam},
+				{Rule: LintUnusedParam},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerLintHandlers(t)
+			got := loadTestWorkflow(t, tt.workflow).Lint()
+			if len(got) != len(tt.want) {
+				t.Fatalf("warnings = %v, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Rule != want.Rule || got[i].Step != want.Step {
+					t.Errorf("warning %d = %s, want rule %s on step %q", i, got[i], want.Rule, want.Step)
+				}
+			}
+		})
+	}
+}
+
+func TestLintUnusedParamMessages(t *testing.T) {
+	registerLintHandlers(t)
+	warnings := loadTestWorkflow(t, `
+name: params
+description: Deploys an image
+platform: test
+default_params:
+  region: us-east
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+  - name: report
+    description: Report the result
+    template: finalize
+    handler: test-verify
+`).Lint()
+	if len(warnings) != 1 || warnings[0].Message != "default_params.region is not referenced by any step or handler" {
+		t.Errorf("warnings = %v, want region unused", warnings)
+	}
+}
+
+func TestLintUndeclaredHandlerParams(t *testing.T) {
+	// report's handler is a plain StepHandler, which may read any param
+	registerLintHandlers(t)
+	warnings := loadTestWorkflow(t, `
+name: params
+description: Deploys an image
+platform: test
+default_params:
+  region: us-east
+steps:
+  - name: deploy
+    description: Roll out the image
+    template: action
+  - name: report
+    description: Report the result
+    template: finalize
+`).Lint()
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+}
+
+func TestHandlerParams(t *testing.T) {
+	registerLintHandlers(t)
+	keys, ok := HandlerParams("test-deploy")
+	if !ok || len(keys) != 2 || keys[0] != "image" || keys[1] != "timeout" {
+		t.Errorf("HandlerParams(test-deploy) = %v, %v; want [image timeout]", keys, ok)
+	}
+	if _, ok := HandlerParams("test-report"); ok {
+		t.Error("plain handler has declared params")
+	}
+}
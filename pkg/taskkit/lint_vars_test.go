@@ -0,0 +1,55 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+const lintVarsWorkflow = `
+name: lint-vars
+platform: test
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+  - name: report
+    template: finalize
+`
+
+func lintVarsHandlers(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("image", "app:1.2")
+			result.SetVar("build_log", "/tmp/build.log")
+			return result
+		},
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			input.GetVar("image")
+			return NewStepResult()
+		},
+		"test-report": func(StepInput, Deps) StepResult {
+			// Finalize vars are meant for later runs
+			result := NewStepResult()
+			result.SetVar("last_report", "ok")
+			return result
+		},
+	})
+}
+
+func TestLintVarsFlagsUnreadVars(t *testing.T) {
+	lintVarsHandlers(t)
+	result, _ := runTestWorkflow(t, lintVarsWorkflow, LocalRunnerConfig{LintVars: true})
+	want := []string{`var "build_log" set by step "build" is never read by a later step`}
+	if !reflect.DeepEqual(result.Warnings, want) {
+		t.Errorf("warnings = %q, want %q", result.Warnings, want)
+	}
+}
+
+func TestLintVarsOffByDefault(t *testing.T) {
+	lintVarsHandlers(t)
+	result, _ := runTestWorkflow(t, lintVarsWorkflow, LocalRunnerConfig{})
+	if len(result.Warnings) != 0 {
+		t.Errorf("warnings = %q, want none", result.Warnings)
+	}
+}
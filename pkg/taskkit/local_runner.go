@@ -1,13 +1,17 @@
 package taskkit
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 // LocalRunnerConfig holds configuration for the runner
@@ -17,25 +21,281 @@ type LocalRunnerConfig struct {
 	Workdir      string
 	TaskID       string
 	Verbose      bool
+
+	// ImportVarsPath seeds vars from a prior run's execution-result.json
+	ImportVarsPath string
+
+	// RetryFromPath re-runs only the steps that failed in a prior run's
+	// execution result, or were skipped because of a failure or a status
+	// condition, plus their dependents, seeding vars from its FinalVars.
+	// Other steps are recorded as Cached with their prior output, or
+	// Skipped if they were skipped before.
+	RetryFromPath string
+
+	// SetVars override vars from every other source
+	SetVars map[string]any
+
+	// ParamOverrides override top-level params from the params file and
+	// step params (highest precedence)
+	ParamOverrides map[string]any
+
+	// ParamEnvPrefix enables env fallback params: an environment variable
+	// named prefix + NAME supplies param "name" when no other source sets
+	// it (lowest precedence). Empty disables the fallback.
+	ParamEnvPrefix string
+
+	// VarStore persists vars between runs (defaults to <workdir>/vars.yaml)
+	VarStore VarStore
+
+	// StrictVars fails initialization when the vars file is malformed,
+	// and fails the run without saving vars when they exceed MaxVars;
+	// otherwise both are reported as warnings
+	StrictVars bool
+
+	// MaxVars caps the number of vars saved after a run (0 means no
+	// limit). PruneVars drops reserved ("__" prefixed) and stale vars
+	// once the cap is exceeded, where stale vars are neither declared by
+	// the workflow nor set by --set, and were not read or set this run.
+	MaxVars   int
+	PruneVars bool
+
+	// LintVars reports vars that are set but never read by a later step.
+	// Only reads through StepInput.GetVar are observed.
+	LintVars bool
+
+	// AllowMissing skips the up-front check that all step handlers are registered
+	AllowMissing bool
+
+	// Stdout receives console output (defaults to os.Stdout)
+	Stdout io.Writer
+
+	// Logger, if set, receives every handler's Deps.Logger lines (as DEBUG,
+	// regardless of Verbose) and each step's result messages. When nil,
+	// Deps.Logger lines are printed to the console under Verbose.
+	Logger Logger
+
+	// CaptureStdout stores each step's streamed output in StepExec.Output["stdout"]
+	CaptureStdout bool
+
+	// RecordInputs writes each step's input to inputs/<step>.json for replay
+	RecordInputs bool
+
+	// Seed seeds the run's random source, from which retry jitter and
+	// each step's Deps.Rand are drawn. Zero uses a time-based seed, which
+	// is recorded in the result.
+	Seed int64
+
+	// StrictWarnings treats WARNING messages as step failures
+	StrictWarnings bool
+
+	// Trace prints each attempt's full StepInput and StepResult as JSON
+	Trace bool
+
+	// DumpParams prints each step's merged params, with sensitive keys
+	// redacted, before its handler runs; Verbose implies it
+	DumpParams bool
+
+	// LogFile also writes all console output to this file, truncating it
+	// unless LogAppend is set. LogFormat is "text" (default) or "json".
+	LogFile   string
+	LogAppend bool
+	LogFormat string
+
+	// Progress shows an in-place step progress line instead of per-step
+	// banners. Callers should enable it only for interactive terminals.
+	Progress bool
+
+	// Quiet suppresses console output except the final workflow status.
+	// The log file, if any, still receives everything.
+	Quiet bool
+
+	// LogThrottle collapses identical consecutive output lines and step
+	// messages that repeat within this window into one "(repeated Nx)"
+	// line. Zero disables throttling.
+	LogThrottle time.Duration
+
+	// Context is passed to handlers as Deps.Context (defaults to
+	// context.Background); cancel it to interrupt waiting handlers
+	Context context.Context
+
+	// Stdin is passed to handlers as Deps.Stdin (defaults to os.Stdin)
+	Stdin io.Reader
+
+	// Approve pre-approves gate steps such as manual-approve
+	Approve bool
+
+	// Tags runs only steps carrying one of these tags, and SkipTags skips
+	// steps carrying any of them. Filtered and disabled steps are recorded
+	// as Skipped, as are steps that depend on them (reported up front as
+	// unreachable); finalize steps are never filtered.
+	Tags     []string
+	SkipTags []string
+
+	// ResultSink, if set, receives the execution result after the run.
+	// Delivery failures are logged as warnings and never fail the run.
+	ResultSink *ResultSink
+
+	// ResultStore, if set, records the execution result after the run.
+	// Failures are logged as warnings and never fail the run.
+	ResultStore ResultStore
+
+	// MaxSteps caps the number of step executions in a run as a guard
+	// against runaway workflows; exceeding it aborts the run as Failed.
+	// Zero uses DefaultMaxSteps and a negative value disables the cap.
+	MaxSteps int
+
+	// WriteState keeps <workdir>/run-state.json updated as steps start and
+	// finish, for polling with `taskkit workflow status`
+	WriteState bool
+
+	// CleanupTemp removes files and directories created through
+	// Deps.TempFile and Deps.TempDir when the run ends
+	CleanupTemp bool
+
+	// FailurePolicy overrides the workflow's failure_policy when set
+	FailurePolicy string
+
+	// Values are passed to every handler as Deps.Values, letting embedding
+	// programs inject shared clients without package globals
+	Values map[string]any
+
+	// ProfileRuntime records Go memory and goroutine stats before and
+	// after the run in ExecutionResult.Runtime
+	ProfileRuntime bool
+
+	// ExpectHash refuses to run unless the workflow file's SHA-256 (hex)
+	// matches, guarding against a changed definition
+	ExpectHash string
+
+	// Confirm allows a workflow marked destructive to run
+	Confirm bool
+
+	// Explain prints a line per step saying why it ran or was skipped
+	Explain bool
+
+	// ResultEncoder serializes the execution result, which is written as
+	// <workdir>/execution-result<ext> (defaults to JSON)
+	ResultEncoder ResultEncoder
+
+	// SnapshotVars records the vars after each executed step in
+	// StepExec.VarsSnapshot
+	SnapshotVars bool
+
+	// MaxOutputBytes caps a step's marshaled output; larger outputs lose
+	// their biggest keys, with a warning, before being recorded (0 means
+	// no limit)
+	MaxOutputBytes int
+
+	// FinalizeOnEmpty controls whether finalize steps run when no init or
+	// action step ran (a NoOp run). Nil means true, so finalize steps
+	// always run unless this is set to false.
+	FinalizeOnEmpty *bool
+
+	// MetricsFile, if set, receives a JSON snapshot of per-handler step
+	// counts, durations, and retries after the run (see MetricsSnapshot).
+	// A relative path is resolved against the workdir.
+	MetricsFile string
+
+	// SlowStepFactor is how far past its expected_seconds a step may run
+	// before it is flagged as slow (defaults to DefaultSlowStepFactor)
+	SlowStepFactor float64
+
+	// FailSteps forces the named steps to fail with the given message
+	// instead of calling their handler, for testing retry, failure policy,
+	// and finalize behavior. Every attempt of the step fails.
+	FailSteps map[string]string
 }
 
+// ConfirmEnv confirms destructive workflows when set to 1, like --confirm
+const ConfirmEnv = "TASKKIT_CONFIRM"
+
+// DefaultMaxSteps is the step execution cap used when MaxSteps is zero
+const DefaultMaxSteps = 1000
+
+// DefaultSlowStepFactor is the slow step factor used when SlowStepFactor is zero
+const DefaultSlowStepFactor = 1.5
+
 // LocalRunner executes workflows locally
 type LocalRunner struct {
-	config   LocalRunnerConfig
-	workflow *WorkflowDefinition
-	params   map[string]any
-	vars     map[string]any
-	deps     Deps
+	config    LocalRunnerConfig
+	workflow  *WorkflowDefinition
+	params    map[string]any
+	envParams map[string]any
+	vars      *SyncVars
+	deps      Deps
+	out       io.Writer
+	rand      *rand.Rand
+	sleep     func(time.Duration)
+	redactor  *redactor
+	logSink   *logSink
+	progress  *progressRenderer
+	console   io.Writer
+	throttle  *lineThrottle
+	state     *RunState
+
+	// workflowResult is the workflow status so far, passed to handlers as
+	// StepInput.WorkflowResult
+	workflowResult string
+
+	// unreadVars maps var keys to the step that set them, pending a later read
+	unreadVars map[string]string
+
+	// usedVars holds the var keys read or set by steps during this run
+	usedVars map[string]bool
+
+	// rationale is the --explain line for the step about to be recorded,
+	// printed after its header
+	rationale string
+
+	// initWarnings are problems found by NewLocalRunner, reported by Run
+	initWarnings []string
+
+	// reuse holds the prior run's records of steps a retry does not re-run
+	reuse map[string]StepExec
+
+	// stepOutputs holds each recorded step's output for step output
+	// templates in params
+	stepOutputs map[string]map[string]any
 }
 
 // NewLocalRunner creates a new runner instance
 func NewLocalRunner(config LocalRunnerConfig) (*LocalRunner, error) {
+	if HandlerCount() == 0 {
+		return nil, ErrNoHandlers
+	}
+
 	// Load workflow
 	wf, err := LoadWorkflow(config.WorkflowPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load workflow: %w", err)
 	}
 
+	if config.ExpectHash != "" && !strings.EqualFold(config.ExpectHash, wf.Hash) {
+		return nil, fmt.Errorf("workflow hash mismatch: expected %s, got %s", config.ExpectHash, wf.Hash)
+	}
+
+	// Refuse destructive workflows unless confirmed
+	if wf.Destructive && !config.Confirm && os.Getenv(ConfirmEnv) != "1" {
+		return nil, fmt.Errorf("workflow %s is marked destructive: rerun with --confirm or %s=1", wf.Name, ConfirmEnv)
+	}
+
+	// Check required environment before any step runs
+	if missing := wf.MissingEnv(); len(missing) > 0 {
+		return nil, fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	if config.FailurePolicy == "" {
+		config.FailurePolicy = wf.FailurePolicy
+	}
+	if err := ValidateFailurePolicy(config.FailurePolicy); err != nil {
+		return nil, err
+	}
+	for name := range config.FailSteps {
+		if !wf.hasStep(name) {
+			return nil, fmt.Errorf("cannot inject failure: unknown step %q", name)
+		}
+	}
+
 	// Load params
 	params := make(map[string]any)
 	if config.ParamsPath != "" {
@@ -56,40 +316,207 @@ func NewLocalRunner(config LocalRunnerConfig) (*LocalRunner, error) {
 		return nil, fmt.Errorf("failed to create workdir: %w", err)
 	}
 
-	// Load existing vars if present
+	console := config.Stdout
+	if console == nil {
+		console = os.Stdout
+	}
+	out := console
+	var progress *progressRenderer
+	if config.Quiet {
+		out = io.Discard
+	} else if config.Progress {
+		progress = newProgressRenderer(console)
+		out = progress
+	}
+	var sink *logSink
+	if config.LogFile != "" {
+		sink, err = openLogSink(config.LogFile, config.LogFormat, config.LogAppend)
+		if err != nil {
+			return nil, err
+		}
+		out = io.MultiWriter(out, sink)
+	}
+	var throttle *lineThrottle
+	if config.LogThrottle > 0 {
+		throttle = newLineThrottle(out, config.LogThrottle)
+		out = throttle
+	}
+
+	var initWarnings []string
+
+	// Vars precedence (lowest first): workflow vars, retried run's vars,
+	// imported vars, workdir vars.yaml, SetVars
 	vars := make(map[string]any)
-	varsPath := filepath.Join(config.Workdir, "vars.yaml")
-	if data, err := os.ReadFile(varsPath); err == nil {
-		yaml.Unmarshal(data, &vars)
+	for k, v := range wf.Vars {
+		vars[k] = v
 	}
 
+	// Reuse what a prior run completed if retrying
+	var reuse map[string]StepExec
+	if config.RetryFromPath != "" {
+		prior, err := LoadExecutionResult(config.RetryFromPath)
+		if err != nil {
+			return nil, err
+		}
+		if prior.WorkflowName != wf.Name {
+			return nil, fmt.Errorf("cannot retry: %s is a result of workflow %s, not %s", config.RetryFromPath, prior.WorkflowName, wf.Name)
+		}
+		if prior.WorkflowHash != "" && prior.WorkflowHash != wf.Hash {
+			initWarnings = append(initWarnings, "workflow changed since the retried run")
+		}
+		if reuse, err = retryPlan(wf, prior); err != nil {
+			return nil, fmt.Errorf("failed to plan retry: %w", err)
+		}
+		for k, v := range prior.FinalVars {
+			vars[k] = v
+		}
+	}
+
+	// Seed vars from a prior run if requested
+	if config.ImportVarsPath != "" {
+		imported, warning, err := loadImportedVars(config.ImportVarsPath)
+		if err != nil {
+			return nil, err
+		}
+		if warning != "" {
+			initWarnings = append(initWarnings, warning)
+		}
+		for k, v := range imported {
+			vars[k] = v
+		}
+	}
+
+	// Load existing vars if present
+	if config.VarStore == nil {
+		config.VarStore = NewFileVarStore(filepath.Join(config.Workdir, "vars.yaml"))
+	}
+	existing, err := config.VarStore.Load()
+	if errors.Is(err, ErrMalformedVars) && !config.StrictVars {
+		initWarnings = append(initWarnings, fmt.Sprintf("ignoring vars: %v", err))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load vars: %w", err)
+	}
+	for k, v := range existing {
+		vars[k] = v
+	}
+	for k, v := range config.SetVars {
+		vars[k] = v
+	}
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	config.Seed = seed
+	rng := rand.New(rand.NewSource(seed))
+
+	ctx := config.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stdin := config.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+
+	redact := newRedactor(wf.Sensitive)
+	envParams := paramsFromEnv(config.ParamEnvPrefix, os.Environ())
+	redact.collect(params)
+	redact.collect(envParams)
+	redact.collect(config.ParamOverrides)
+	redact.collect(vars)
+
 	logger := func(format string, args ...any) {
 		if config.Verbose {
-			fmt.Printf("[DEBUG] "+format+"\n", args...)
+			fmt.Fprint(out, redact.redactText(fmt.Sprintf("[DEBUG] "+format+"\n", args...)))
+		}
+	}
+	if config.Logger != nil {
+		logger = func(format string, args ...any) {
+			config.Logger.Log(LogEntry{Severity: SeverityDebug, Message: redact.redactText(fmt.Sprintf(format, args...))})
 		}
 	}
 
 	return &LocalRunner{
-		config:   config,
-		workflow: wf,
-		params:   params,
-		vars:     vars,
+		config:    config,
+		workflow:  wf,
+		params:    params,
+		envParams: envParams,
+		vars:      NewSyncVars(vars),
+		out:       out,
+		rand:      rng,
+		sleep:     time.Sleep,
+		redactor:  redact,
+		logSink:   sink,
+		progress:  progress,
+		console:   console,
+		throttle:  throttle,
 		deps: Deps{
 			Workdir: config.Workdir,
 			Logger:  logger,
+			Stdout:  out,
+			Rand:    rng,
+
+			Context:  ctx,
+			Stdin:    stdin,
+			Approved: config.Approve,
+			Values:   config.Values,
+			temps:    &tempTracker{},
+
+			handlerConfig: wf.HandlerConfig,
 		},
+		workflowResult: "Running",
+		unreadVars:     make(map[string]string),
+		usedVars:       make(map[string]bool),
+		initWarnings:   initWarnings,
+		reuse:          reuse,
+		stepOutputs:    make(map[string]map[string]any),
 	}, nil
 }
 
 // Run executes the workflow and returns the final result
 func (r *LocalRunner) Run() ExecutionResult {
 	startTime := time.Now()
+	defer r.closeLog()
+	if r.config.CleanupTemp {
+		defer r.cleanupTemp()
+	}
+	if r.config.WriteState {
+		r.state = &RunState{
+			Workflow:  r.workflow.Name,
+			TaskID:    r.config.TaskID,
+			PID:       os.Getpid(),
+			Status:    "Running",
+			StartedAt: startTime,
+			Total:     len(r.workflow.Steps),
+			Steps:     make([]StepState, 0),
+		}
+		r.writeState()
+	}
+
+	var runtimeBefore RuntimeSnapshot
+	if r.config.ProfileRuntime {
+		runtimeBefore = CaptureRuntime()
+	}
 
 	result := ExecutionResult{
 		TaskID:       r.config.TaskID,
 		WorkflowName: r.workflow.Name,
+		WorkflowHash: r.workflow.Hash,
 		StartTime:    startTime,
 		Steps:        make([]StepExec, 0),
+		Seed:         r.config.Seed,
+		Warnings:     append([]string(nil), r.initWarnings...),
+	}
+
+	// Fail fast if any handler is not registered
+	if !r.config.AllowMissing {
+		if _, missing := r.workflow.ResolveHandlers(); len(missing) > 0 {
+			result.Result = "Error"
+			result.ErrorMessage = fmt.Sprintf("Missing step handlers: %s", strings.Join(r.workflow.describeMissing(missing), ", "))
+			r.printf("ERROR: %s\n", result.ErrorMessage)
+			return r.abortRun(result)
+		}
 	}
 
 	// Get execution order
@@ -97,20 +524,96 @@ func (r *LocalRunner) Run() ExecutionResult {
 	if err != nil {
 		result.Result = "Error"
 		result.ErrorMessage = fmt.Sprintf("Failed to determine execution order: %v", err)
-		result.EndTime = time.Now()
-		result.Duration = result.EndTime.Sub(startTime).String()
-		r.saveResult(result)
-		return result
+		r.printf("ERROR: %s\n", result.ErrorMessage)
+		return r.abortRun(result)
 	}
 
-	fmt.Printf("=== Executing workflow: %s ===\n", r.workflow.Name)
-	fmt.Printf("Steps: %d\n", len(steps))
+	r.printf("=== Executing workflow: %s ===\n", r.workflow.Name)
+	r.stepHeader("Steps: %d\n", len(steps))
 
 	// Execute each step
 	workflowFailed := false
-	for _, step := range steps {
-		stepExec := r.executeStep(step)
+	halted := false
+	statuses := make(map[string]string)
+	exclusions := r.reportExclusions(&result)
+	maxSteps := r.config.MaxSteps
+	if maxSteps == 0 {
+		maxSteps = DefaultMaxSteps
+	}
+	executed := 0
+	ranWork := false
+	stage := ""
+	for i, step := range steps {
+		// After a failure with no finalize step, only steps conditioned on
+		// prior step statuses (e.g. cleanup on failure) still run
+		if halted && len(step.IfStepStatus) == 0 {
+			continue
+		}
+		// A handler requested an exit: only finalize steps still run
+		if result.ExitCode != nil && step.Template != TemplateFinalize {
+			continue
+		}
+
+		if workflowFailed {
+			r.workflowResult = "Failed"
+		}
+		if step.Stage != "" && step.Stage != stage {
+			r.stepHeader("\n=== Stage: %s ===\n", step.Stage)
+		}
+		stage = step.Stage
+		if r.progress != nil {
+			r.progress.begin(i+1, len(steps), step.Name)
+		}
+		r.stateBegin(step.Name)
+		var stepExec StepExec
+		capped := false
+		if prior, ok := r.reuse[step.Name]; ok {
+			stepExec = r.reuseStep(step, prior)
+		} else if step.Template == TemplateFinalize && !ranWork && r.config.FinalizeOnEmpty != nil && !*r.config.FinalizeOnEmpty {
+			stepExec = r.skipStep(step, "no init or action step ran")
+		} else if workflowFailed && r.skipAfterFailure(step) {
+			stepExec = r.skipStep(step, skipUpstreamFailure)
+		} else if ex, ok := exclusions[step.Name]; ok {
+			stepExec = r.skipStep(step, ex.Reason)
+		} else if ok, reason := step.CheckStepStatus(statuses); !ok {
+			stepExec = r.skipStep(step, reason)
+		} else if ok, reason := step.CheckWhen(r.vars.Snapshot(), r.whenParams(step)); !ok {
+			stepExec = r.skipStep(step, reason)
+		} else if maxSteps > 0 && executed >= maxSteps {
+			capped = true
+			stepExec = r.skipStep(step, fmt.Sprintf("max steps (%d) exceeded", maxSteps))
+		} else {
+			executed++
+			if step.Template != TemplateFinalize {
+				ranWork = true
+			}
+			if r.config.Explain {
+				r.rationale = r.explainRun(step, statuses)
+			}
+			stepExec = r.executeStep(step)
+		}
+		if r.progress != nil {
+			r.progress.end(stepExec.Status, stepExec.Duration)
+		}
+		stepExec.Stage = step.Stage
 		result.Steps = append(result.Steps, stepExec)
+		statuses[step.Name] = stepExec.Status
+		r.stepOutputs[step.Name] = stepExec.Output
+		r.stateEnd(stepExec)
+
+		// Abort a runaway workflow without running anything further
+		if capped {
+			workflowFailed = true
+			result.ErrorMessage = fmt.Sprintf("aborted: exceeded max steps (%d)", maxSteps)
+			r.printf("ERROR: %s\n", result.ErrorMessage)
+			break
+		}
+
+		if stepExec.ExitCode != nil && result.ExitCode == nil {
+			result.ExitCode = stepExec.ExitCode
+			result.ExitReason = stepExec.ExitReason
+			r.printf("  Exit requested with code %d: %s\n", *stepExec.ExitCode, stepExec.ExitReason)
+		}
 
 		if stepExec.Status == "Failed" {
 			workflowFailed = true
@@ -125,31 +628,177 @@ func (r *LocalRunner) Run() ExecutionResult {
 					}
 				}
 				if !hasFinalize {
-					break
+					halted = true
 				}
 			}
 		}
 	}
 
-	// Determine final result
+	// Determine final result. A run where every init/action step was
+	// skipped, filtered, or disabled is a NoOp rather than a success.
 	if workflowFailed {
 		result.Result = "Failed"
+	} else if !ranWork {
+		result.Result = "NoOp"
 	} else {
 		result.Result = "Succeeded"
 	}
 
+	// Keep the vars to persist within MaxVars
+	saveVars := true
+	if r.config.MaxVars > 0 {
+		if err := r.limitVars(&result); err != nil {
+			result.Result = "Failed"
+			result.ErrorMessage = err.Error()
+			r.printf("ERROR: %s\n", result.ErrorMessage)
+			saveVars = false
+		}
+	}
+
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(startTime).String()
-	result.FinalVars = r.vars
+	result.FinalVars = r.vars.Snapshot()
+	result.Retries = NewRetryStats(result.Steps)
+	result.Stages = NewStageResults(result.Steps)
+	if r.config.ProfileRuntime {
+		result.Runtime = NewRuntimeStats(runtimeBefore, CaptureRuntime())
+	}
+
+	// Resolve declared workflow outputs. Missing outputs are an error for
+	// an otherwise successful run and a warning for a failed one.
+	outputs, err := r.workflow.ResolveOutputs(result.Steps)
+	result.Outputs = outputs
+	if err != nil {
+		if result.Result == "Succeeded" {
+			result.Result = "Error"
+			result.ErrorMessage = err.Error()
+		} else {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+
+	if r.config.LintVars {
+		result.Warnings = append(result.Warnings, r.lintUnreadVars()...)
+	}
+	for _, w := range result.Warnings {
+		r.printf("Warning: %s\n", w)
+	}
 
 	// Save results
 	r.saveResult(result)
-	r.saveVars()
+	if r.config.MetricsFile != "" {
+		path := r.config.MetricsFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.config.Workdir, path)
+		}
+		if err := WriteMetricsFile(path, result); err != nil {
+			r.printf("Warning: %v\n", err)
+		}
+	}
+	if saveVars {
+		r.saveVars()
+	}
+
+	if len(result.Stages) > 0 {
+		r.printf("\nStages:\n")
+		for _, s := range result.Stages {
+			r.printf("  %s: %s\n", s.Name, s.Status)
+		}
+	}
+	r.printf("\n=== Workflow %s: %s ===\n", r.workflow.Name, result.Result)
+	if r.config.Quiet {
+		fmt.Fprintf(r.console, "=== Workflow %s: %s ===\n", r.workflow.Name, result.Result)
+	}
+	return result
+}
 
-	fmt.Printf("\n=== Workflow %s: %s ===\n", r.workflow.Name, result.Result)
+// abortRun finishes a run that errored before any step ran. Finalize steps
+// whose handlers are registered still run so failure notifications fire.
+func (r *LocalRunner) abortRun(result ExecutionResult) ExecutionResult {
+	r.workflowResult = "Error"
+	for _, step := range r.workflow.Steps {
+		if step.Template != TemplateFinalize {
+			continue
+		}
+		if _, ok := Get(r.workflow.GetHandlerName(step)); !ok {
+			continue
+		}
+		result.Steps = append(result.Steps, r.executeStep(step))
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime).String()
+	result.Retries = NewRetryStats(result.Steps)
+	r.saveResult(result)
 	return result
 }
 
+// checkExpectedDuration returns a warning when a step with an
+// expected_seconds hint ran longer than the hint times the slow step factor
+func (r *LocalRunner) checkExpectedDuration(step WorkflowStep, elapsed time.Duration) string {
+	if step.ExpectedSeconds <= 0 {
+		return ""
+	}
+	factor := r.config.SlowStepFactor
+	if factor <= 0 {
+		factor = DefaultSlowStepFactor
+	}
+	expected := time.Duration(step.ExpectedSeconds * float64(time.Second))
+	if float64(elapsed) <= float64(expected)*factor {
+		return ""
+	}
+	return fmt.Sprintf("Step took %s, more than %.1fx its expected %s", elapsed.Round(time.Millisecond), factor, expected)
+}
+
+// skipAfterFailure reports whether the skip-to-finalize policy skips a step
+// once the workflow has failed. Finalize steps and steps conditioned on
+// prior step statuses (failure handlers) still run.
+func (r *LocalRunner) skipAfterFailure(step WorkflowStep) bool {
+	return r.config.FailurePolicy == FailurePolicySkipToFinalize &&
+		step.Template != TemplateFinalize && len(step.IfStepStatus) == 0
+}
+
+// reportExclusions works out which steps the disabled flags and tag filters
+// keep from running, warning up front about enabled steps that became
+// unreachable through their dependencies
+func (r *LocalRunner) reportExclusions(result *ExecutionResult) map[string]StepExclusion {
+	exclusions := make(map[string]StepExclusion)
+	list, err := r.workflow.ExcludedSteps(r.config.Tags, r.config.SkipTags)
+	if err != nil {
+		// GetExecutionOrder already succeeded, so this cannot happen
+		return exclusions
+	}
+	for _, ex := range list {
+		exclusions[ex.Step] = ex
+		if ex.Unreachable {
+			warning := fmt.Sprintf("step %q is unreachable: %s", ex.Step, ex.Reason)
+			result.Warnings = append(result.Warnings, warning)
+			r.printf("Warning: %s\n", warning)
+		}
+	}
+	return exclusions
+}
+
+// skipUpstreamFailure is the skip reason for steps passed over after an
+// earlier step failed
+const skipUpstreamFailure = "upstream failure"
+
+// skipStep records a step as skipped without invoking its handler
+func (r *LocalRunner) skipStep(step WorkflowStep, reason string) StepExec {
+	r.stepHeader("\n--- Step: %s (skipped: %s) ---\n", step.Name, reason)
+	if r.config.Explain {
+		r.rationale = r.explainSkip(reason)
+	}
+	r.printRationale()
+	return StepExec{
+		Name:       step.Name,
+		Handler:    r.workflow.GetHandlerName(step),
+		Status:     "Skipped",
+		Duration:   "0s",
+		SkipReason: reason,
+	}
+}
+
 func (r *LocalRunner) executeStep(step WorkflowStep) StepExec {
 	stepStart := time.Now()
 	handlerName := r.workflow.GetHandlerName(step)
@@ -159,15 +808,47 @@ func (r *LocalRunner) executeStep(step WorkflowStep) StepExec {
 		Handler: handlerName,
 	}
 
-	fmt.Printf("\n--- Step: %s (handler: %s) ---\n", step.Name, handlerName)
+	r.stepHeader("\n--- Step: %s (handler: %s) ---\n", step.Name, handlerName)
+	r.printRationale()
 
 	// Get handler
 	handler, ok := Get(handlerName)
 	if !ok {
 		exec.Status = "Failed"
+		exec.FailureKind = FailureHandlerNotFound
 		exec.Error = fmt.Sprintf("handler not found: %s", handlerName)
+		if suggestion := r.workflow.HandlerSuggestion(step); suggestion != "" {
+			exec.Error += fmt.Sprintf(" (did you mean %s?)", suggestion)
+		}
 		exec.Duration = time.Since(stepStart).String()
-		fmt.Printf("ERROR: %s\n", exec.Error)
+		r.printf("ERROR: %s\n", exec.Error)
+		return exec
+	}
+
+	// Resolve step environment; file contents are secrets, as in params
+	env, err := r.workflow.GetEnv(step, r.redactor.addValue)
+	if err != nil {
+		exec.Status = "Failed"
+		exec.FailureKind = FailureEnv
+		exec.Error = fmt.Sprintf("failed to resolve env: %v", err)
+		exec.Duration = time.Since(stepStart).String()
+		r.printf("ERROR: %s\n", exec.Error)
+		return exec
+	}
+
+	// Resolve step output templates, then ${ENV:...} and ${FILE:...}
+	// tokens in params; file contents are secrets and are redacted
+	// wherever they appear
+	params, err := r.mergeParams(step.Params)
+	if err == nil {
+		params, err = r.workflow.tokens().expandParams(params, r.redactor.addValue)
+	}
+	if err != nil {
+		exec.Status = "Failed"
+		exec.FailureKind = FailureParams
+		exec.Error = fmt.Sprintf("failed to resolve params: %v", err)
+		exec.Duration = time.Since(stepStart).String()
+		r.printf("ERROR: %s\n", exec.Error)
 		return exec
 	}
 
@@ -178,22 +859,224 @@ func (r *LocalRunner) executeStep(step WorkflowStep) StepExec {
 		WorkflowName: r.workflow.Name,
 		Attempt:      1,
 		TotalRetries: r.workflow.GetRetries(step),
-		Params:       r.mergeParams(step.Params),
-		Vars:         r.vars,
+		Params:       params,
+		Vars:         r.vars.Snapshot(),
+		varReads:     make(map[string]bool),
+
+		WorkflowResult: r.workflowResult,
+	}
+
+	r.redactor.collect(input.Params)
+	r.dumpParams(input.Params)
+
+	// Stream handler output to the console as it is produced. Each step
+	// draws its own random source from the run's, so a recorded input can
+	// reproduce it.
+	stdout := newStepWriter(r.out, step.Name, r.config.CaptureStdout, r.redactor.redactText)
+	stepSeed := r.rand.Int63()
+	deps := r.deps
+	deps.Stdout = stdout
+	deps.Env = env
+	deps.Rand = rand.New(rand.NewSource(stepSeed))
+
+	if r.config.RecordInputs {
+		r.recordInput(handlerName, input, deps, stepSeed)
+	}
+
+	// Reuse a cached result when the cache_key param matches, or for
+	// memoized steps when the inputs are unchanged
+	var stepResult StepResult
+	memo := ""
+	if key, ok := input.Params[CacheKeyParam]; ok && key != nil && key != "" {
+		if memo, err = explicitCacheKey(handlerName, step.Name, key); err != nil {
+			r.printf("Warning: %v\n", err)
+		}
+	} else if step.Memoize {
+		if memo, err = memoKey(handlerName, input, step.MemoizeVars); err != nil {
+			r.printf("Warning: %v\n", err)
+		}
+	}
+	var cached StepResult
+	hit := false
+	if memo != "" {
+		cached, hit = loadCachedResult(r.config.Workdir, memo)
+	}
+	if hit {
+		r.printf("  Cache hit: %s\n", memo[:12])
+		exec.Status = "Cached"
+		stepResult = cached
+	} else {
+		stepResult = r.runWithHooks(step, handler, input, deps, &exec)
+		if r.workflow.OnStepGiveUp != "" && exec.Status == "Failed" && lastAttemptFailed(exec) {
+			if warning := r.runGiveUp(step, &exec, stepResult, deps); warning != "" {
+				stepResult.AddWarning(warning, "taskkit")
+			}
+		}
+		if memo != "" && exec.Status == "Succeeded" {
+			r.storeMemo(memo, stepResult)
+		}
+	}
+
+	r.redactor.collect(stepResult.Output)
+	r.redactor.collect(stepResult.ContextUpdates)
+	stdout.Flush()
+
+	// Record results
+	if code, ok := stepResult.RequestedExitCode(); ok {
+		exec.ExitCode = &code
+		exec.ExitReason, _ = stepResult.FlowControl["exit_reason"].(string)
+	}
+	if r.config.LogThrottle > 0 {
+		stepResult.Messages = collapseMessages(stepResult.Messages, r.config.LogThrottle)
+	}
+	exec.Messages = stepResult.Messages
+	exec.Output = stepResult.Output
+	if captured := stdout.Captured(); captured != "" {
+		if exec.Output == nil {
+			exec.Output = make(map[string]any)
+		}
+		exec.Output["stdout"] = captured
+	}
+	if r.config.MaxOutputBytes > 0 {
+		if warning := truncateOutput(exec.Output, r.config.MaxOutputBytes); warning != "" {
+			stepResult.AddWarning(warning, "taskkit")
+			exec.Messages = stepResult.Messages
+		}
+	}
+	elapsed := time.Since(stepStart)
+	exec.Duration = elapsed.String()
+	if exec.Status != "Cached" {
+		if warning := r.checkExpectedDuration(step, elapsed); warning != "" {
+			stepResult.AddWarning(warning, "taskkit")
+			exec.Messages = stepResult.Messages
+			exec.SlowerThanExpected = true
+		}
+	}
+
+	// Track var reads/writes for the unused vars lint and MaxVars pruning.
+	// Vars set by finalize steps are intended for later runs, so they are
+	// not expected to be read.
+	for k := range input.varReads {
+		delete(r.unreadVars, k)
+		r.usedVars[k] = true
+	}
+	for k := range stepResult.ContextUpdates {
+		if step.Template != TemplateFinalize {
+			r.unreadVars[k] = step.Name
+		}
+		r.usedVars[k] = true
+	}
+
+	// Apply context updates to vars, through any declared reducers
+	for k, v := range stepResult.ContextUpdates {
+		if err := r.applyUpdate(stepResult, k, v); err != nil {
+			r.printf("Warning: %v\n", err)
+		}
+	}
+
+	// Promote outputs to vars as "<step>.<key>"
+	if step.PromoteOutputs {
+		for k, v := range stepResult.Output {
+			r.vars.Set(step.Name+"."+k, v)
+			r.usedVars[step.Name+"."+k] = true
+		}
+	}
+	if r.config.SnapshotVars {
+		exec.VarsSnapshot = r.vars.Snapshot()
+	}
+
+	// Print messages
+	for _, msg := range stepResult.Messages {
+		r.printf("  %s\n", formatMessage(msg))
 	}
+	r.logMessages(exec)
+
+	r.stepHeader("  Status: %s (duration: %s)\n", exec.Status, exec.Duration)
+	return exec
+}
 
-	// Execute with retries
+// runAttempts invokes the handler, retrying failed attempts with backoff,
+// and records the attempt history and final status on exec
+func (r *LocalRunner) runAttempts(step WorkflowStep, handler StepHandler, input StepInput, deps Deps, exec *StepExec) StepResult {
 	maxAttempts := r.workflow.GetRetries(step) + 1
 	var stepResult StepResult
 
+	backoff := r.workflow.GetBackoff(step)
+	var delay time.Duration
+
+	// Retries see the step params with retry_params layered on top
+	baseParams := input.Params
+	var retryParams map[string]any
+	if len(step.RetryParams) > 0 {
+		stepParams := make(map[string]any)
+		for k, v := range step.Params {
+			stepParams[k] = v
+		}
+		for k, v := range step.RetryParams {
+			stepParams[k] = v
+		}
+		resolved, err := r.mergeParams(stepParams)
+		if err == nil {
+			resolved, err = r.workflow.tokens().expandParams(resolved, r.redactor.addValue)
+		}
+		if err != nil {
+			stepResult = NewStepResult()
+			stepResult.AddError(fmt.Sprintf("failed to resolve retry_params: %v", err), "taskkit")
+			exec.Status = "Failed"
+			exec.FailureKind = FailureParams
+			return stepResult
+		}
+		retryParams = resolved
+		r.redactor.collect(retryParams)
+	}
+
+	budget := time.Duration(step.RetryBudgetSeconds) * time.Second
+	stepStart := time.Now()
+	var lastKind FailureKind
+
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		input.Attempt = attempt
+		input.Params = baseParams
+		if attempt > 1 && retryParams != nil {
+			input.Params = retryParams
+		}
+		record := AttemptRecord{Attempt: attempt}
 
 		if attempt > 1 {
-			fmt.Printf("  Retry attempt %d/%d\n", attempt, maxAttempts)
+			if backoff != nil {
+				delay = backoff.Delay(attempt-1, delay, r.rand)
+			}
+			// Stop retrying once the next attempt would start past the budget
+			if budget > 0 && time.Since(stepStart)+delay >= budget {
+				r.printf("  Retry budget of %s exhausted after %d attempts\n", budget, attempt-1)
+				exec.Status = "Failed"
+				exec.FailureKind = lastKind
+				break
+			}
+			if delay > 0 {
+				r.printf("  Retry attempt %d/%d (after %s)\n", attempt, maxAttempts, delay)
+				r.sleep(delay)
+				record.BackoffDelay = delay.String()
+			} else {
+				r.printf("  Retry attempt %d/%d\n", attempt, maxAttempts)
+			}
 		}
 
-		stepResult = handler(input, r.deps)
+		if r.config.Logger != nil {
+			deps.Logger = r.handlerLogger(step.Name, exec.Handler, attempt)
+		}
+		r.trace("input", attempt, input)
+		attemptStart := time.Now()
+		var kind FailureKind
+		if message, ok := r.config.FailSteps[step.Name]; ok {
+			stepResult = NewStepResult()
+			stepResult.AddError(fmt.Sprintf("Injected failure: %s", message), "taskkit")
+			kind = FailureInjected
+		} else {
+			stepResult, kind = r.callHandler(handler, input, deps, r.workflow.GetTimeout(step, attempt))
+		}
+		record.Duration = time.Since(attemptStart).String()
+		r.trace("result", attempt, stepResult)
 
 		// Check for skip
 		if skip, ok := stepResult.FlowControl["skip"].(bool); ok && skip {
@@ -202,72 +1085,406 @@ func (r *LocalRunner) executeStep(step WorkflowStep) StepExec {
 			if r, ok := stepResult.FlowControl["skip_reason"].(string); ok {
 				reason = r
 			}
-			exec.Error = reason
+			exec.SkipReason = reason
+			record.Status = "Skipped"
+			exec.Attempts = append(exec.Attempts, record)
 			break
 		}
 
-		// Check for errors
-		if !stepResult.HasErrors() {
+		// Check for errors (and warnings in strict mode)
+		failed := stepResult.HasErrors() || (r.config.StrictWarnings && stepResult.HasWarnings())
+		if !failed {
 			exec.Status = "Succeeded"
+			record.Status = "Succeeded"
+			exec.Attempts = append(exec.Attempts, record)
 			break
 		}
 
+		if kind == "" {
+			kind = FailureHandlerError
+			if !stepResult.HasErrors() {
+				kind = FailureStrictWarning
+			}
+		}
+		record.Status = "Failed"
+		record.Error = attemptError(stepResult)
+		record.FailureKind = kind
+		exec.Attempts = append(exec.Attempts, record)
+		lastKind = kind
+
 		// Last attempt failed
 		if attempt == maxAttempts {
 			exec.Status = "Failed"
+			exec.FailureKind = kind
 		}
 	}
 
-	// Record results
-	exec.Messages = stepResult.Messages
-	exec.Output = stepResult.Output
-	exec.Duration = time.Since(stepStart).String()
+	return stepResult
+}
 
-	// Apply context updates to vars
-	for k, v := range stepResult.ContextUpdates {
-		r.vars[k] = v
+// callHandler runs one attempt, converting a panic into an error result
+// and abandoning the handler if it outlives the timeout or the run's
+// context. The returned kind is empty unless one of those happened.
+func (r *LocalRunner) callHandler(handler StepHandler, input StepInput, deps Deps, timeout time.Duration) (StepResult, FailureKind) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(deps.Context, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(deps.Context)
 	}
+	defer cancel()
+	deps.Context = ctx
 
-	// Print messages
-	for _, msg := range stepResult.Messages {
-		fmt.Printf("  [%s] %s\n", msg.Severity, msg.Text)
+	// The handler gets its own read set so an abandoned handler cannot
+	// race with the runner; it is merged back once the handler returns
+	outerReads := input.varReads
+	reads := make(map[string]bool)
+	input.varReads = reads
+
+	type outcome struct {
+		result StepResult
+		kind   FailureKind
 	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				result := NewStepResult()
+				result.AddError(fmt.Sprintf("handler panicked: %v", p), "taskkit")
+				done <- outcome{result, FailurePanic}
+			}
+		}()
+		done <- outcome{result: handler(input, deps)}
+	}()
 
-	fmt.Printf("  Status: %s (duration: %s)\n", exec.Status, exec.Duration)
-	return exec
+	select {
+	case o := <-done:
+		if outerReads != nil {
+			for k := range reads {
+				outerReads[k] = true
+			}
+		}
+		return o.result, o.kind
+	case <-ctx.Done():
+		result := NewStepResult()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.AddError(fmt.Sprintf("handler timed out after %s", timeout), "taskkit")
+			return result, FailureTimeout
+		}
+		result.AddError("handler cancelled", "taskkit")
+		return result, FailureCancelled
+	}
 }
 
-func (r *LocalRunner) mergeParams(stepParams map[string]any) map[string]any {
-	merged := make(map[string]any)
-	for k, v := range r.params {
-		merged[k] = v
+// storeMemo caches a successful result unless it holds sensitive values,
+// which must never be written to disk
+func (r *LocalRunner) storeMemo(key string, result StepResult) {
+	if r.redactor.containsSensitive(map[string]any{
+		"output":          result.Output,
+		"context_updates": result.ContextUpdates,
+	}) {
+		r.printf("  Not caching result: it contains sensitive values\n")
+		return
 	}
-	for k, v := range stepParams {
-		merged[k] = v
+	if err := storeCachedResult(r.config.Workdir, key, result); err != nil {
+		r.printf("Warning: %v\n", err)
 	}
-	return merged
 }
 
-func (r *LocalRunner) saveResult(result ExecutionResult) {
-	path := filepath.Join(r.config.Workdir, "execution-result.json")
-	data, err := json.MarshalIndent(result, "", "  ")
+// recordInput writes a step's input and Deps for replay. Sensitive values
+// are redacted, and the recording notes whether there were any.
+func (r *LocalRunner) recordInput(handler string, input StepInput, deps Deps, seed int64) {
+	env := make(map[string]any, len(deps.Env))
+	for k, v := range deps.Env {
+		env[k] = v
+	}
+	config := deps.HandlerConfig(handler)
+	rec := RecordedInput{
+		Handler:       handler,
+		Input:         input,
+		Seed:          seed,
+		Env:           make(map[string]string, len(env)),
+		HandlerConfig: r.redactor.redactMap(config),
+		Redacted: r.redactor.containsSensitive(input.Params) || r.redactor.containsSensitive(input.Vars) ||
+			r.redactor.containsSensitive(env) || r.redactor.containsSensitive(config),
+	}
+	rec.Input.Params = r.redactor.redactMap(input.Params)
+	rec.Input.Vars = r.redactor.redactMap(input.Vars)
+	for k, v := range r.redactor.redactMap(env) {
+		rec.Env[k], _ = v.(string)
+	}
+	if err := RecordInput(r.config.Workdir, rec); err != nil {
+		r.printf("Warning: %v\n", err)
+	}
+}
+
+// truncateOutput drops the largest output keys, in place, until the
+// marshaled output fits in limit bytes. It returns a warning describing
+// what was dropped, or "" if the output already fit.
+func truncateOutput(output map[string]any, limit int) string {
+	size := func(v any) int {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return 0
+		}
+		return len(data)
+	}
+	total := size(output)
+	if total <= limit {
+		return ""
+	}
+
+	keys := make([]string, 0, len(output))
+	sizes := make(map[string]int, len(output))
+	for k, v := range output {
+		keys = append(keys, k)
+		sizes[k] = size(v)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sizes[keys[i]] != sizes[keys[j]] {
+			return sizes[keys[i]] > sizes[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	var dropped []string
+	for _, k := range keys {
+		if size(output) <= limit {
+			break
+		}
+		delete(output, k)
+		dropped = append(dropped, k)
+	}
+	return fmt.Sprintf("output of %d bytes exceeds max output size of %d bytes; dropped keys: %s",
+		total, limit, strings.Join(dropped, ", "))
+}
+
+// lastAttemptFailed reports whether the step's final attempt failed, as
+// opposed to a step that failed in a hook or before any attempt
+func lastAttemptFailed(exec StepExec) bool {
+	n := len(exec.Attempts)
+	return n > 0 && exec.Attempts[n-1].Status == "Failed"
+}
+
+// attemptError summarizes why an attempt failed from its messages
+func attemptError(result StepResult) string {
+	var errs, warnings []string
+	for _, m := range result.Messages {
+		switch m.Severity {
+		case SeverityError:
+			errs = append(errs, m.Text)
+		case SeverityWarning:
+			warnings = append(warnings, m.Text)
+		}
+	}
+	if len(errs) > 0 {
+		return strings.Join(errs, "; ")
+	}
+	return "warning in strict mode: " + strings.Join(warnings, "; ")
+}
+
+// lintUnreadVars returns a warning for each var set but never read afterwards
+func (r *LocalRunner) lintUnreadVars() []string {
+	keys := make([]string, 0, len(r.unreadVars))
+	for k := range r.unreadVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	warnings := make([]string, 0, len(keys))
+	for _, k := range keys {
+		warnings = append(warnings, fmt.Sprintf("var %q set by step %q is never read by a later step", k, r.unreadVars[k]))
+	}
+	return warnings
+}
+
+// cleanupTemp removes temp paths handlers created through Deps
+func (r *LocalRunner) cleanupTemp() {
+	for _, err := range r.deps.temps.cleanup() {
+		r.printf("Warning: failed to remove temp path: %v\n", err)
+	}
+}
+
+// closeLog reports pending throttled repeats and closes the log file, if any
+func (r *LocalRunner) closeLog() {
+	if r.throttle != nil {
+		r.throttle.Flush()
+	}
+	if r.logSink == nil {
+		return
+	}
+	if err := r.logSink.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to close log file: %v\n", err)
+	}
+	r.logSink = nil
+}
+
+// stepHeader prints per-step banner lines. The progress line replaces them
+// on the console, but the log file still records them.
+func (r *LocalRunner) stepHeader(format string, args ...any) {
+	if r.progress == nil {
+		r.printf(format, args...)
+		return
+	}
+	if r.logSink != nil {
+		fmt.Fprint(r.logSink, r.redactor.redactText(fmt.Sprintf(format, args...)))
+	}
+}
+
+// trace dumps a step input or result as JSON when tracing is enabled
+func (r *LocalRunner) trace(label string, attempt int, v any) {
+	if !r.config.Trace {
+		return
+	}
+	data, err := r.redactor.marshalIndent(v)
 	if err != nil {
-		fmt.Printf("Warning: failed to marshal result: %v\n", err)
+		r.printf("  [TRACE] %s (attempt %d): failed to marshal: %v\n", label, attempt, err)
 		return
 	}
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		fmt.Printf("Warning: failed to write result: %v\n", err)
+	r.printf("  [TRACE] %s (attempt %d):\n%s\n", label, attempt, data)
+}
+
+// dumpParams prints a step's merged params as JSON under DumpParams or
+// Verbose
+func (r *LocalRunner) dumpParams(params map[string]any) {
+	if !r.config.DumpParams && !r.config.Verbose {
+		return
+	}
+	data, err := r.redactor.marshalIndent(params)
+	if err != nil {
+		r.printf("  Params: failed to marshal: %v\n", err)
+		return
 	}
+	r.printf("  Params:\n%s\n", data)
 }
 
-func (r *LocalRunner) saveVars() {
-	path := filepath.Join(r.config.Workdir, "vars.yaml")
-	data, err := yaml.Marshal(r.vars)
+// printf writes console output, masking sensitive values
+func (r *LocalRunner) printf(format string, args ...any) {
+	fmt.Fprint(r.out, r.redactor.redactText(fmt.Sprintf(format, args...)))
+}
+
+// loadImportedVars reads FinalVars from a saved execution result.
+// A missing or malformed final_vars section yields empty vars and a
+// warning, which Run reports with the other initialization warnings.
+func loadImportedVars(path string) (map[string]any, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read import-vars file: %w", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, "", fmt.Errorf("failed to parse import-vars file: %w", err)
+	}
+
+	vars := make(map[string]any)
+	finalVars, ok := raw["final_vars"]
+	if !ok || string(finalVars) == "null" {
+		return vars, fmt.Sprintf("no final_vars in %s, nothing imported", path), nil
+	}
+	if err := json.Unmarshal(finalVars, &vars); err != nil {
+		return make(map[string]any), fmt.Sprintf("ignoring malformed final_vars in %s: %v", path, err), nil
+	}
+	return vars, "", nil
+}
+
+// paramsFromEnv collects params from environment entries (KEY=value) named
+// prefix + NAME, keyed by the lowercased NAME. Values are decoded as JSON
+// scalars where possible, otherwise kept as strings.
+func paramsFromEnv(prefix string, environ []string) map[string]any {
+	params := make(map[string]any)
+	if prefix == "" {
+		return params
+	}
+	for _, entry := range environ {
+		key, raw, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			value = raw
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			value = raw
+		}
+		params[strings.ToLower(strings.TrimPrefix(key, prefix))] = value
+	}
+	return params
+}
+
+// mergeParams layers params in precedence order (see
+// WorkflowDefinition.DefaultParams) and renders step output templates
+func (r *LocalRunner) mergeParams(stepParams map[string]any) (map[string]any, error) {
+	merged := r.workflow.layerParams(r.envParams, r.params, stepParams, r.config.ParamOverrides)
+	return renderStepTemplates(merged, r.stepOutputs)
+}
+
+// whenParams returns the merged params for evaluating a step's when
+// condition; a step output template error is reported once the step runs
+func (r *LocalRunner) whenParams(step WorkflowStep) map[string]any {
+	params, err := r.mergeParams(step.Params)
 	if err != nil {
-		fmt.Printf("Warning: failed to marshal vars: %v\n", err)
+		return nil
+	}
+	return params
+}
+
+func (r *LocalRunner) saveResult(result ExecutionResult) {
+	r.stateFinish(result.Result)
+
+	enc := r.config.ResultEncoder
+	if enc == nil {
+		enc = JSONResultEncoder{}
+	}
+	path := filepath.Join(r.config.Workdir, ResultFileBase+enc.Extension())
+	data, err := r.redactor.encode(enc, result)
+	if err != nil {
+		r.printf("Warning: failed to marshal result: %v\n", err)
 		return
 	}
 	if err := os.WriteFile(path, data, 0644); err != nil {
-		fmt.Printf("Warning: failed to write vars: %v\n", err)
+		r.printf("Warning: failed to write result: %v\n", err)
+	}
+
+	if r.config.ResultSink != nil {
+		// The sink always receives JSON
+		if _, ok := enc.(JSONResultEncoder); !ok {
+			if data, err = r.redactor.marshalIndent(result); err != nil {
+				r.printf("Warning: failed to marshal result: %v\n", err)
+				return
+			}
+		}
+		if err := r.config.ResultSink.Send(data, r.sleep); err != nil {
+			r.printf("Warning: failed to send result to %s: %v\n", r.config.ResultSink.URL, err)
+		} else {
+			r.printf("Result sent to %s\n", r.config.ResultSink.URL)
+		}
+	}
+
+	if r.config.ResultStore != nil {
+		if err := r.storeResult(result); err != nil {
+			r.printf("Warning: failed to store result: %v\n", err)
+		}
+	}
+}
+
+// storeResult passes a redacted copy of result to the ResultStore
+func (r *LocalRunner) storeResult(result ExecutionResult) error {
+	data, err := r.redactor.marshalIndent(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	var redacted ExecutionResult
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return fmt.Errorf("failed to unmarshal result: %w", err)
+	}
+	return r.config.ResultStore.SaveResult(redacted)
+}
+
+func (r *LocalRunner) saveVars() {
+	if err := r.config.VarStore.Save(r.vars.Snapshot()); err != nil {
+		r.printf("Warning: %v\n", err)
 	}
 }
@@ -0,0 +1,104 @@
+package taskkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log file formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logSink tees console output into a log file, either verbatim or as
+// one JSON object per line
+type logSink struct {
+	mu      sync.Mutex
+	file    *os.File
+	format  string
+	pending bytes.Buffer
+}
+
+func openLogSink(path, format string, appendMode bool) (*logSink, error) {
+	switch format {
+	case "":
+		format = LogFormatText
+	case LogFormatText, LogFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if appendMode {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return &logSink{file: f, format: format}, nil
+}
+
+// Write implements io.Writer
+func (s *logSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.format == LogFormatText {
+		return s.file.Write(p)
+	}
+
+	s.pending.Write(p)
+	for {
+		line, err := s.pending.ReadBytes('\n')
+		if err != nil {
+			s.pending.Reset()
+			s.pending.Write(line)
+			break
+		}
+		if err := s.writeJSONLine(string(line)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *logSink) writeJSONLine(line string) error {
+	line = strings.TrimRight(line, "\n")
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	data, err := json.Marshal(map[string]string{
+		"time":    time.Now().Format(time.RFC3339Nano),
+		"message": line,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close flushes any partial line and closes the file
+func (s *logSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.format == LogFormatJSON && s.pending.Len() > 0 {
+		s.writeJSONLine(s.pending.String())
+		s.pending.Reset()
+	}
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,95 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const logFileWorkflow = `
+name: log-file
+platform: test
+steps:
+  - name: build
+  - name: report
+    template: finalize
+`
+
+func logFileHandlers(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build":  failWith("compile error"),
+		"test-report": succeed,
+	})
+}
+
+func TestLogFileTeesConsole(t *testing.T) {
+	logFileHandlers(t)
+	path := filepath.Join(t.TempDir(), "run.log")
+	writeFile(t, filepath.Dir(path), "run.log", "previous run\n")
+
+	// The run fails, and the file must still be complete and closed
+	result, out := runTestWorkflow(t, logFileWorkflow, LocalRunnerConfig{LogFile: path})
+	if result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	if strings.Contains(log, "previous run") {
+		t.Error("log file was appended to without LogAppend")
+	}
+	for _, line := range []string{"--- Step: build", "--- Step: report", "=== Workflow log-file: Failed ==="} {
+		if !strings.Contains(log, line) {
+			t.Errorf("log file missing %q:\n%s", line, log)
+		}
+	}
+	if log != out {
+		t.Errorf("log file differs from the console output")
+	}
+}
+
+func TestLogFileAppendJSON(t *testing.T) {
+	logFileHandlers(t)
+	path := writeFile(t, t.TempDir(), "run.log", "{\"message\":\"previous run\"}\n")
+	runTestWorkflow(t, logFileWorkflow, LocalRunnerConfig{LogFile: path, LogAppend: true, LogFormat: LogFormatJSON})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) < 3 || !strings.Contains(lines[0], "previous run") {
+		t.Fatalf("log = %s, want the previous line kept", data)
+	}
+	var sawStep bool
+	for _, line := range lines {
+		var entry map[string]string
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		if strings.Contains(entry["message"], "--- Step: build") {
+			sawStep = true
+		}
+	}
+	if !sawStep {
+		t.Errorf("log has no step line:\n%s", data)
+	}
+}
+
+func TestLogFileUnknownFormat(t *testing.T) {
+	logFileHandlers(t)
+	dir := t.TempDir()
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath: writeFile(t, dir, "workflow.yaml", logFileWorkflow),
+		Workdir:      dir,
+		LogFile:      filepath.Join(dir, "run.log"),
+		LogFormat:    "xml",
+	})
+	if err == nil {
+		t.Error("NewLocalRunner with an unknown log format succeeded")
+	}
+}
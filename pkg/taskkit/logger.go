@@ -0,0 +1,102 @@
+package taskkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// LogEntry is one line logged by a handler through Deps.Logger, or one of a
+// step's result messages
+type LogEntry struct {
+	Severity Severity
+	Step     string
+	Handler  string
+	Attempt  int
+	Message  string
+}
+
+// Logger receives a run's log entries, letting embedders route them into
+// their own logging framework via LocalRunnerConfig.Logger
+type Logger interface {
+	Log(entry LogEntry)
+}
+
+// LoggerFunc adapts a function to the Logger interface
+type LoggerFunc func(entry LogEntry)
+
+// Log calls f(entry)
+func (f LoggerFunc) Log(entry LogEntry) {
+	f(entry)
+}
+
+// handlerLogger returns a Deps.Logger that forwards to the configured
+// Logger as DEBUG entries for one attempt of a step
+func (r *LocalRunner) handlerLogger(step, handler string, attempt int) func(string, ...any) {
+	return func(format string, args ...any) {
+		r.config.Logger.Log(LogEntry{
+			Severity: SeverityDebug,
+			Step:     step,
+			Handler:  handler,
+			Attempt:  attempt,
+			Message:  r.redactor.redactText(fmt.Sprintf(format, args...)),
+		})
+	}
+}
+
+// logMessages forwards a step's result messages to the configured Logger
+func (r *LocalRunner) logMessages(exec StepExec) {
+	if r.config.Logger == nil {
+		return
+	}
+	for _, msg := range exec.Messages {
+		r.config.Logger.Log(LogEntry{
+			Severity: msg.Severity,
+			Step:     exec.Name,
+			Handler:  exec.Handler,
+			Attempt:  len(exec.Attempts),
+			Message:  r.redactor.redactText(msg.Text),
+		})
+	}
+}
+
+// slogLogger adapts a *slog.Logger to Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that emits entries as slog records, with
+// the severity as the record level and step, handler, and attempt as
+// attributes
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+// Log emits entry as a slog record
+func (l slogLogger) Log(entry LogEntry) {
+	attrs := make([]slog.Attr, 0, 3)
+	if entry.Step != "" {
+		attrs = append(attrs, slog.String("step", entry.Step))
+	}
+	if entry.Handler != "" {
+		attrs = append(attrs, slog.String("handler", entry.Handler))
+	}
+	if entry.Attempt > 0 {
+		attrs = append(attrs, slog.Int("attempt", entry.Attempt))
+	}
+	l.logger.LogAttrs(context.Background(), slogLevel(entry.Severity), entry.Message, attrs...)
+}
+
+// slogLevel maps a message severity to a slog level
+func slogLevel(severity Severity) slog.Level {
+	switch severity {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
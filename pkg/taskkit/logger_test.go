@@ -0,0 +1,171 @@
+package taskkit
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const loggerWorkflow = `
+name: logger
+platform: test
+sensitive: [password]
+steps:
+  - name: deploy
+    retries: 1
+    params:
+      password: pw-s3cret
+`
+
+// flakyDeploy logs through Deps.Logger, fails its first attempt, and
+// reports a warning on the second
+func flakyDeploy(input StepInput, deps Deps) StepResult {
+	deps.Logger("attempt %d with %s", input.Attempt, input.Params["password"])
+	result := NewStepResult()
+	if input.Attempt == 1 {
+		result.AddError("not ready", "test")
+		return result
+	}
+	result.AddWarning("deployed slowly", "test")
+	return result
+}
+
+func TestInjectedLoggerReceivesEntries(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": flakyDeploy})
+
+	var entries []LogEntry
+	result, out := runTestWorkflow(t, loggerWorkflow, LocalRunnerConfig{
+		Logger: LoggerFunc(func(entry LogEntry) { entries = append(entries, entry) }),
+	})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	var debug []LogEntry
+	var warned bool
+	for _, entry := range entries {
+		if strings.Contains(entry.Message, "pw-s3cret") {
+			t.Errorf("entry not redacted: %+v", entry)
+		}
+		switch entry.Severity {
+		case SeverityDebug:
+			debug = append(debug, entry)
+		case SeverityWarning:
+			warned = entry.Message == "deployed slowly" && entry.Step == "deploy" && entry.Attempt == 2
+		}
+	}
+	if len(debug) != 2 {
+		t.Fatalf("debug entries = %+v, want one per attempt", debug)
+	}
+	for i, entry := range debug {
+		if entry.Step != "deploy" || entry.Handler != "test-deploy" || entry.Attempt != i+1 {
+			t.Errorf("debug entry %d = %+v, want step deploy, handler test-deploy, attempt %d", i, entry, i+1)
+		}
+	}
+	if !warned {
+		t.Errorf("warning message not forwarded: %+v", entries)
+	}
+	if strings.Contains(out, "[DEBUG]") {
+		t.Errorf("debug lines printed despite an injected logger:\n%s", out)
+	}
+}
+
+func TestDefaultLoggerPrintsUnderVerbose(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": flakyDeploy})
+
+	_, quiet := runTestWorkflow(t, loggerWorkflow, LocalRunnerConfig{})
+	if strings.Contains(quiet, "[DEBUG]") {
+		t.Errorf("debug lines printed without Verbose:\n%s", quiet)
+	}
+	_, verbose := runTestWorkflow(t, loggerWorkflow, LocalRunnerConfig{Verbose: true})
+	if !strings.Contains(verbose, "[DEBUG] attempt 1 with ***") {
+		t.Errorf("redacted debug line not printed under Verbose:\n%s", verbose)
+	}
+}
+
+// captureHandler is a slog.Handler that records every record it handles
+type captureHandler struct {
+	records *[]slog.Record
+}
+
+func (h captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h captureHandler) Handle(_ context.Context, record slog.Record) error {
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h captureHandler) WithGroup(string) slog.Handler { return h }
+
+// recordAttrs flattens a record's attributes into a map
+func recordAttrs(record slog.Record) map[string]any {
+	attrs := make(map[string]any, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.Any()
+		return true
+	})
+	return attrs
+}
+
+func TestSlogLoggerAttributes(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": flakyDeploy,
+		"test-verify": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.AddInfo("verified", "test")
+			return result
+		},
+	})
+
+	var records []slog.Record
+	result, _ := runTestWorkflow(t, loggerWorkflow+`
+  - name: verify
+    depends: [deploy]
+`, LocalRunnerConfig{Logger: NewSlogLogger(slog.New(captureHandler{records: &records}))})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	type want struct {
+		level   slog.Level
+		message string
+		step    string
+		handler string
+		attempt int64
+	}
+	var got []want
+	for _, record := range records {
+		attrs := recordAttrs(record)
+		step, _ := attrs["step"].(string)
+		handler, _ := attrs["handler"].(string)
+		attempt, _ := attrs["attempt"].(int64)
+		got = append(got, want{record.Level, record.Message, step, handler, attempt})
+	}
+	expected := []want{
+		{slog.LevelDebug, "attempt 1 with ***", "deploy", "test-deploy", 1},
+		{slog.LevelDebug, "attempt 2 with ***", "deploy", "test-deploy", 2},
+		{slog.LevelWarn, "deployed slowly", "deploy", "test-deploy", 2},
+		{slog.LevelInfo, "verified", "verify", "test-verify", 1},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("records =\n%+v\nwant\n%+v", got, expected)
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	tests := map[Severity]slog.Level{
+		SeverityDebug:   slog.LevelDebug,
+		SeverityInfo:    slog.LevelInfo,
+		SeverityWarning: slog.LevelWarn,
+		SeverityError:   slog.LevelError,
+	}
+	for severity, level := range tests {
+		if got := slogLevel(severity); got != level {
+			t.Errorf("slogLevel(%s) = %v, want %v", severity, got, level)
+		}
+	}
+}
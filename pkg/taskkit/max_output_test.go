@@ -0,0 +1,63 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaxOutputBytesDropsLargeKeys(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-fetch": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("status", 200)
+			result.SetOutput("body", strings.Repeat("x", 10_000))
+			return result
+		},
+	})
+	workdir := t.TempDir()
+
+	result, out := runTestWorkflow(t, `
+name: big-output
+platform: test
+steps:
+  - name: fetch
+`, LocalRunnerConfig{Workdir: workdir, MaxOutputBytes: 1024})
+	fetch := stepByName(t, result, "fetch")
+	if _, ok := fetch.Output["body"]; ok {
+		t.Error("oversized body kept in the output")
+	}
+	if fetch.Output["status"] != 200 {
+		t.Errorf("status = %v, want the small key kept", fetch.Output["status"])
+	}
+
+	const warning = "exceeds max output size of 1024 bytes; dropped keys: body"
+	found := false
+	for _, msg := range fetch.Messages {
+		if msg.Severity == SeverityWarning && strings.Contains(msg.Text, warning) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("messages = %+v, want a truncation warning", fetch.Messages)
+	}
+	if !strings.Contains(out, warning) {
+		t.Errorf("warning not printed:\n%s", out)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(workdir, "execution-result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) > 5_000 {
+		t.Errorf("execution-result.json is %d bytes, want the body left out", len(saved))
+	}
+}
+
+func TestTruncateOutputWithinLimit(t *testing.T) {
+	output := map[string]any{"status": 200}
+	if warning := truncateOutput(output, 1024); warning != "" || len(output) != 1 {
+		t.Errorf("truncateOutput = %q leaving %v, want no change", warning, output)
+	}
+}
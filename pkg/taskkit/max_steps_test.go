@@ -0,0 +1,49 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const maxStepsWorkflow = `
+name: max-steps
+platform: test
+steps:
+  - name: one
+  - name: two
+  - name: three
+  - name: four
+`
+
+func TestMaxStepsAbortsRun(t *testing.T) {
+	calls := 0
+	handlers := map[string]StepHandler{}
+	for _, name := range []string{"one", "two", "three", "four"} {
+		handlers["test-"+name] = func(StepInput, Deps) StepResult {
+			calls++
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, handlers)
+
+	result, _ := runTestWorkflow(t, maxStepsWorkflow, LocalRunnerConfig{MaxSteps: 2})
+	if result.Result != "Failed" || !strings.Contains(result.ErrorMessage, "exceeded max steps (2)") {
+		t.Fatalf("result = %s (%s), want Failed for exceeding max steps", result.Result, result.ErrorMessage)
+	}
+	if calls != 2 {
+		t.Errorf("handlers ran %d times, want 2", calls)
+	}
+	// The step over the cap is recorded as skipped and nothing runs after it
+	statuses := stepStatuses(result)
+	if statuses["three"] != "Skipped" {
+		t.Errorf("three status = %s, want Skipped", statuses["three"])
+	}
+	if _, ok := statuses["four"]; ok {
+		t.Errorf("four recorded after the abort: %s", statuses["four"])
+	}
+
+	calls = 0
+	if result, _ := runTestWorkflow(t, maxStepsWorkflow, LocalRunnerConfig{MaxSteps: -1}); result.Result != "Succeeded" || calls != 4 {
+		t.Errorf("uncapped run = %s after %d calls, want Succeeded after 4", result.Result, calls)
+	}
+}
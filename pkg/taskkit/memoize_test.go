@@ -0,0 +1,114 @@
+package taskkit
+
+import "testing"
+
+const memoizeWorkflow = `
+name: memoize
+platform: test
+steps:
+  - name: digest
+    memoize: true
+    params:
+      input: hello
+`
+
+func TestMemoizeReusesResultForSameInputs(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-digest": func(input StepInput, deps Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			result.SetOutput("digest", "sha:"+input.Params["input"].(string))
+			return result
+		},
+	})
+	workdir := t.TempDir()
+	run := func(overrides map[string]any) StepExec {
+		t.Helper()
+		result, _ := runTestWorkflow(t, memoizeWorkflow, LocalRunnerConfig{
+			Workdir:        workdir,
+			ParamOverrides: overrides,
+		})
+		return stepByName(t, result, "digest")
+	}
+
+	if got := run(nil).Status; got != "Succeeded" {
+		t.Fatalf("first run status = %s, want Succeeded", got)
+	}
+
+	second := run(nil)
+	if second.Status != "Cached" || calls != 1 {
+		t.Errorf("second run status = %s after %d calls, want Cached after 1", second.Status, calls)
+	}
+	if second.Output["digest"] != "sha:hello" {
+		t.Errorf("cached output = %v, want the first run's digest", second.Output)
+	}
+
+	// Changing an input misses the cache and runs the handler again
+	changed := run(map[string]any{"input": "world"})
+	if changed.Status != "Succeeded" || calls != 2 {
+		t.Errorf("changed input status = %s after %d calls, want Succeeded after 2", changed.Status, calls)
+	}
+	if changed.Output["digest"] != "sha:world" {
+		t.Errorf("changed output = %v, want sha:world", changed.Output)
+	}
+}
+
+const memoizeVarsWorkflow = `
+name: memoize-vars
+platform: test
+steps:
+  - name: init
+  - name: digest
+    depends: [init]
+    memoize: true
+    memoize_vars: [region]
+`
+
+func TestMemoizeKeysOnlyOnDeclaredVars(t *testing.T) {
+	runs, calls := 0, 0
+	region := "us-east"
+	registerHandlers(t, map[string]StepHandler{
+		"test-init": func(input StepInput, deps Deps) StepResult {
+			runs++
+			result := NewStepResult()
+			result.SetVar("start_time", runs)
+			result.SetVar("region", region)
+			return result
+		},
+		"test-digest": func(input StepInput, deps Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			result.SetOutput("digest", "sha:"+input.GetVar("region").(string))
+			// Persisted to vars.yaml, so the next run sees a new value
+			result.SetVar("digested_at", calls)
+			return result
+		},
+	})
+	workdir := t.TempDir()
+	run := func() StepExec {
+		t.Helper()
+		result, _ := runTestWorkflow(t, memoizeVarsWorkflow, LocalRunnerConfig{Workdir: workdir})
+		return stepByName(t, result, "digest")
+	}
+
+	if got := run().Status; got != "Succeeded" {
+		t.Fatalf("first run status = %s, want Succeeded", got)
+	}
+
+	// start_time and digested_at change every run but are not declared
+	second := run()
+	if second.Status != "Cached" || calls != 1 {
+		t.Errorf("second run status = %s after %d calls, want Cached after 1", second.Status, calls)
+	}
+
+	// A declared var changing misses the cache
+	region = "eu-west"
+	changed := run()
+	if changed.Status != "Succeeded" || calls != 2 {
+		t.Errorf("changed var status = %s after %d calls, want Succeeded after 2", changed.Status, calls)
+	}
+	if changed.Output["digest"] != "sha:eu-west" {
+		t.Errorf("changed output = %v, want sha:eu-west", changed.Output)
+	}
+}
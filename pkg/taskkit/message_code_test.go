@@ -0,0 +1,65 @@
+package taskkit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMessageCodesSurviveSerialization(t *testing.T) {
+	for _, format := range []string{ResultFormatJSON, ResultFormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			registerHandlers(t, map[string]StepHandler{
+				"test-check": func(StepInput, Deps) StepResult {
+					result := NewStepResult()
+					result.AddInfoCode("CHECK_STARTED", "Checking 3 hosts", "smoke-test")
+					result.AddWarning("no code here", "smoke-test")
+					result.AddErrorCode("CHECK_FAILED", "nas is unreachable", "smoke-test")
+					return result
+				},
+			})
+			enc, err := NewResultEncoder(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			workdir := t.TempDir()
+
+			runTestWorkflow(t, `
+name: codes
+platform: test
+steps:
+  - name: check
+`, LocalRunnerConfig{Workdir: workdir, ResultEncoder: enc})
+			saved, err := LoadExecutionResult(filepath.Join(workdir, ResultFileBase+enc.Extension()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			messages := stepByName(t, *saved, "check").Messages
+			want := []struct {
+				severity Severity
+				code     string
+			}{
+				{SeverityInfo, "CHECK_STARTED"},
+				{SeverityWarning, ""},
+				{SeverityError, "CHECK_FAILED"},
+			}
+			if len(messages) != len(want) {
+				t.Fatalf("messages = %+v, want %d", messages, len(want))
+			}
+			for i, w := range want {
+				if messages[i].Severity != w.severity || messages[i].Code != w.code {
+					t.Errorf("message %d = %s/%q, want %s/%q", i, messages[i].Severity, messages[i].Code, w.severity, w.code)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatMessageIncludesCode(t *testing.T) {
+	if got, want := formatMessage(Message{Severity: SeverityError, Code: "CHECK_FAILED", Text: "nas is unreachable"}), "[ERROR] CHECK_FAILED: nas is unreachable"; got != want {
+		t.Errorf("formatMessage = %q, want %q", got, want)
+	}
+	if got, want := formatMessage(Message{Severity: SeverityInfo, Text: "ok"}), "[INFO] ok"; got != want {
+		t.Errorf("formatMessage = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// HandlerMetrics aggregates the steps that used one handler
+type HandlerMetrics struct {
+	Steps              int            `json:"steps"`
+	Statuses           map[string]int `json:"statuses"`
+	Attempts           int            `json:"attempts"`
+	Retries            int            `json:"retries"`
+	DurationSeconds    float64        `json:"duration_seconds"`
+	MaxDurationSeconds float64        `json:"max_duration_seconds"`
+}
+
+// MetricsSnapshot aggregates a run's steps per handler, as written by
+// LocalRunnerConfig.MetricsFile
+type MetricsSnapshot struct {
+	Workflow        string                     `json:"workflow"`
+	TaskID          string                     `json:"task_id,omitempty"`
+	Result          string                     `json:"result"`
+	DurationSeconds float64                    `json:"duration_seconds"`
+	Steps           int                        `json:"steps"`
+	Handlers        map[string]*HandlerMetrics `json:"handlers"`
+	Retries         RetryStats                 `json:"retries"`
+}
+
+// NewMetricsSnapshot computes the metrics snapshot of an execution result
+func NewMetricsSnapshot(result ExecutionResult) MetricsSnapshot {
+	snap := MetricsSnapshot{
+		Workflow:        result.WorkflowName,
+		TaskID:          result.TaskID,
+		Result:          result.Result,
+		DurationSeconds: parseDurationSeconds(result.Duration),
+		Steps:           len(result.Steps),
+		Handlers:        make(map[string]*HandlerMetrics),
+		Retries:         NewRetryStats(result.Steps),
+	}
+	for _, step := range result.Steps {
+		m, ok := snap.Handlers[step.Handler]
+		if !ok {
+			m = &HandlerMetrics{Statuses: make(map[string]int)}
+			snap.Handlers[step.Handler] = m
+		}
+		m.Steps++
+		m.Statuses[step.Status]++
+		m.Attempts += len(step.Attempts)
+		if len(step.Attempts) > 1 {
+			m.Retries += len(step.Attempts) - 1
+		}
+		d := parseDurationSeconds(step.Duration)
+		m.DurationSeconds += d
+		if d > m.MaxDurationSeconds {
+			m.MaxDurationSeconds = d
+		}
+	}
+	return snap
+}
+
+// WriteMetricsFile writes the metrics snapshot of result to path as JSON
+func WriteMetricsFile(path string, result ExecutionResult) error {
+	data, err := json.MarshalIndent(NewMetricsSnapshot(result), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return nil
+}
+
+// parseDurationSeconds converts a recorded duration string to seconds,
+// treating an unparsable one as zero
+func parseDurationSeconds(s string) float64 {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d.Seconds()
+}
@@ -0,0 +1,109 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewMetricsSnapshotAggregatesPerHandler(t *testing.T) {
+	snap := NewMetricsSnapshot(ExecutionResult{
+		WorkflowName: "metrics",
+		Result:       "Failed",
+		Duration:     "4s",
+		Steps: []StepExec{
+			{Name: "build-a", Handler: "build", Status: "Succeeded", Duration: "1.5s",
+				Attempts: []AttemptRecord{{Attempt: 1}}},
+			{Name: "build-b", Handler: "build", Status: "Failed", Duration: "2s",
+				Attempts: []AttemptRecord{{Attempt: 1}, {Attempt: 2}, {Attempt: 3}}},
+			{Name: "notify", Handler: "notify", Status: "Skipped"},
+		},
+	})
+
+	if snap.Workflow != "metrics" || snap.Result != "Failed" || snap.DurationSeconds != 4 || snap.Steps != 3 {
+		t.Errorf("snapshot = %+v, want workflow metrics, Failed, 4s and 3 steps", snap)
+	}
+	build := snap.Handlers["build"]
+	if build == nil {
+		t.Fatalf("handlers = %v, want a build entry", snap.Handlers)
+	}
+	if build.Steps != 2 || build.Attempts != 4 || build.Retries != 2 {
+		t.Errorf("build = %+v, want 2 steps, 4 attempts, 2 retries", build)
+	}
+	if build.Statuses["Succeeded"] != 1 || build.Statuses["Failed"] != 1 {
+		t.Errorf("build statuses = %v, want one Succeeded and one Failed", build.Statuses)
+	}
+	if build.DurationSeconds != 3.5 || build.MaxDurationSeconds != 2 {
+		t.Errorf("build durations = %v total, %v max, want 3.5 and 2", build.DurationSeconds, build.MaxDurationSeconds)
+	}
+	notify := snap.Handlers["notify"]
+	if notify == nil || notify.Steps != 1 || notify.Statuses["Skipped"] != 1 || notify.Attempts != 0 || notify.DurationSeconds != 0 {
+		t.Errorf("notify = %+v, want one skipped step with no attempts", notify)
+	}
+	if snap.Retries.TotalRetries != 2 || snap.Retries.StepsRetried != 1 {
+		t.Errorf("retries = %+v, want 2 retries over 1 step", snap.Retries)
+	}
+}
+
+func TestMetricsFileWrittenToWorkdir(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": succeed,
+		"test-flaky": func(StepInput, Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			if calls < 2 {
+				result.AddError("connection reset", "test")
+			}
+			return result
+		},
+	})
+	workdir := t.TempDir()
+	result, _ := runTestWorkflow(t, `
+name: metrics
+platform: test
+steps:
+  - name: build-a
+    handler: test-build
+  - name: build-b
+    handler: test-build
+  - name: flaky
+    retries: 1
+    retry_backoff:
+      base_seconds: 0.01
+      jitter: none
+`, LocalRunnerConfig{Workdir: workdir, MetricsFile: "metrics.json"})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	// A relative path lands in the workdir, not the current directory
+	data, err := os.ReadFile(filepath.Join(workdir, "metrics.json"))
+	if err != nil {
+		t.Fatalf("metrics file: %v", err)
+	}
+	if _, err := os.Stat("metrics.json"); !os.IsNotExist(err) {
+		t.Errorf("metrics.json written to the current directory (%v)", err)
+	}
+	var snap MetricsSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("metrics file is not a snapshot: %v", err)
+	}
+	if snap.Workflow != "metrics" || snap.Result != "Succeeded" || snap.Steps != 3 {
+		t.Errorf("snapshot = %+v, want 3 steps of a Succeeded metrics run", snap)
+	}
+	build, flaky := snap.Handlers["test-build"], snap.Handlers["test-flaky"]
+	if build == nil || build.Steps != 2 || build.Attempts != 2 || build.Retries != 0 || build.Statuses["Succeeded"] != 2 {
+		t.Errorf("test-build = %+v, want 2 succeeded steps in 2 attempts", build)
+	}
+	if flaky == nil || flaky.Steps != 1 || flaky.Attempts != 2 || flaky.Retries != 1 || flaky.Statuses["Succeeded"] != 1 {
+		t.Errorf("test-flaky = %+v, want 1 succeeded step in 2 attempts", flaky)
+	}
+	if flaky != nil && (flaky.DurationSeconds <= 0 || flaky.MaxDurationSeconds != flaky.DurationSeconds) {
+		t.Errorf("test-flaky durations = %v total, %v max, want a positive total equal to the max", flaky.DurationSeconds, flaky.MaxDurationSeconds)
+	}
+	if snap.Retries.TotalRetries != 1 {
+		t.Errorf("retries = %+v, want 1", snap.Retries)
+	}
+}
@@ -2,7 +2,12 @@
 package taskkit
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +27,10 @@ type Message struct {
 	Text      string    `json:"text"`
 	System    string    `json:"system,omitempty"`
 	Timestamp time.Time `json:"timestamp,omitempty"`
+
+	// Code is a stable machine-readable identifier (e.g. CHECK_FAILED) for
+	// alerting rules to match instead of Text
+	Code string `json:"code,omitempty"`
 }
 
 // StepInput contains all context passed to a step handler
@@ -34,6 +43,9 @@ type StepInput struct {
 	Params         map[string]any `json:"params"`
 	Vars           map[string]any `json:"vars"`
 	WorkflowResult string         `json:"workflow_result,omitempty"`
+
+	// varReads records keys looked up via GetVar (used by the vars lint)
+	varReads map[string]bool
 }
 
 // GetParam retrieves a parameter by key, returning the zero value if not found
@@ -56,8 +68,100 @@ func (s *StepInput) GetParamString(key string) string {
 	return ""
 }
 
+// GetParamPath retrieves a nested parameter. The path may be dotted
+// ("db.host", "hosts.0") or a JSON pointer ("/db/host", "/hosts/0").
+func (s *StepInput) GetParamPath(path string) (any, bool) {
+	if s.Params == nil {
+		return nil, false
+	}
+	return lookupPath(s.Params, splitPath(path))
+}
+
+// GetParamPathString retrieves a nested string parameter
+func (s *StepInput) GetParamPathString(path string) (string, bool) {
+	v, ok := s.GetParamPath(path)
+	if !ok {
+		return "", false
+	}
+	str, ok := v.(string)
+	return str, ok
+}
+
+// GetParamPathInt retrieves a nested integer parameter
+func (s *StepInput) GetParamPathInt(path string) (int, bool) {
+	v, ok := s.GetParamPath(path)
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		if n == float64(int(n)) {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// GetParamPathBool retrieves a nested boolean parameter
+func (s *StepInput) GetParamPathBool(path string) (bool, bool) {
+	v, ok := s.GetParamPath(path)
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// splitPath splits a dotted path or JSON pointer into segments
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	if !strings.HasPrefix(path, "/") {
+		return strings.Split(path, ".")
+	}
+	parts := strings.Split(path[1:], "/")
+	for i, p := range parts {
+		// JSON pointer escapes: ~1 is '/', ~0 is '~'
+		p = strings.ReplaceAll(p, "~1", "/")
+		parts[i] = strings.ReplaceAll(p, "~0", "~")
+	}
+	return parts
+}
+
+// lookupPath walks nested maps and slices following the given segments
+func lookupPath(root any, segments []string) (any, bool) {
+	cur := root
+	for _, seg := range segments {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
 // GetVar retrieves a workflow variable by key
 func (s *StepInput) GetVar(key string) any {
+	if s.varReads != nil {
+		s.varReads[key] = true
+	}
 	if s.Vars == nil {
 		return nil
 	}
@@ -92,6 +196,12 @@ func (r *StepResult) AddMessage(severity Severity, text, system string) {
 	})
 }
 
+// AddMessageCode adds a message carrying a machine-readable code
+func (r *StepResult) AddMessageCode(severity Severity, code, text, system string) {
+	r.AddMessage(severity, text, system)
+	r.Messages[len(r.Messages)-1].Code = code
+}
+
 // AddInfo adds an info message
 func (r *StepResult) AddInfo(text, system string) {
 	r.AddMessage(SeverityInfo, text, system)
@@ -112,6 +222,21 @@ func (r *StepResult) AddDebug(text, system string) {
 	r.AddMessage(SeverityDebug, text, system)
 }
 
+// AddInfoCode adds an info message with a code
+func (r *StepResult) AddInfoCode(code, text, system string) {
+	r.AddMessageCode(SeverityInfo, code, text, system)
+}
+
+// AddWarningCode adds a warning message with a code
+func (r *StepResult) AddWarningCode(code, text, system string) {
+	r.AddMessageCode(SeverityWarning, code, text, system)
+}
+
+// AddErrorCode adds an error message with a code
+func (r *StepResult) AddErrorCode(code, text, system string) {
+	r.AddMessageCode(SeverityError, code, text, system)
+}
+
 // HasErrors returns true if the result contains any error messages
 func (r *StepResult) HasErrors() bool {
 	for _, m := range r.Messages {
@@ -122,11 +247,35 @@ func (r *StepResult) HasErrors() bool {
 	return false
 }
 
+// HasWarnings returns true if the result contains any warning messages
+func (r *StepResult) HasWarnings() bool {
+	for _, m := range r.Messages {
+		if m.Severity == SeverityWarning {
+			return true
+		}
+	}
+	return false
+}
+
 // SetVar sets a workflow variable (will be persisted to vars.yaml)
 func (r *StepResult) SetVar(key string, value any) {
 	r.ContextUpdates[key] = value
 }
 
+// AppendVar appends value to a list var instead of replacing it, whatever
+// reducer the workflow declares for key. Repeated calls in one step append
+// each value in order.
+func (r *StepResult) AppendVar(key string, value any) {
+	list, _ := r.ContextUpdates[key].([]any)
+	r.ContextUpdates[key] = append(list, value)
+	stepReducers, ok := r.FlowControl[reduceFlowControl].(map[string]any)
+	if !ok {
+		stepReducers = make(map[string]any)
+		r.FlowControl[reduceFlowControl] = stepReducers
+	}
+	stepReducers[key] = ReducerAppend
+}
+
 // SetOutput sets an output value (available to dependent steps)
 func (r *StepResult) SetOutput(key string, value any) {
 	r.Output[key] = value
@@ -138,34 +287,182 @@ func (r *StepResult) Skip(reason string) {
 	r.FlowControl["skip_reason"] = reason
 }
 
+// ExitWith aborts the workflow after this step and requests a specific
+// process exit code. Remaining non-finalize steps are not run.
+func (r *StepResult) ExitWith(code int, reason string) {
+	r.FlowControl["exit_code"] = code
+	r.FlowControl["exit_reason"] = reason
+}
+
+// RequestedExitCode returns the exit code set via ExitWith, if any
+func (r *StepResult) RequestedExitCode() (int, bool) {
+	switch code := r.FlowControl["exit_code"].(type) {
+	case int:
+		return code, true
+	case int64:
+		return int(code), true
+	case float64:
+		return int(code), true
+	}
+	return 0, false
+}
+
 // ExecutionResult is the final result of a workflow execution
 type ExecutionResult struct {
-	Result       string         `json:"result"` // Succeeded, Failed, Error
+	Result       string         `json:"result"` // Succeeded, NoOp, Failed, Error
 	TaskID       string         `json:"task_id"`
 	WorkflowName string         `json:"workflow_name"`
+	WorkflowHash string         `json:"workflow_hash,omitempty"`
 	StartTime    time.Time      `json:"start_time"`
 	EndTime      time.Time      `json:"end_time"`
 	Duration     string         `json:"duration"`
 	Steps        []StepExec     `json:"steps"`
 	FinalVars    map[string]any `json:"final_vars,omitempty"`
+	Outputs      map[string]any `json:"outputs,omitempty"`
 	ErrorMessage string         `json:"error_message,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+	Seed         int64          `json:"seed"`
+	ExitCode     *int           `json:"exit_code,omitempty"`
+	ExitReason   string         `json:"exit_reason,omitempty"`
+	Runtime      *RuntimeStats  `json:"runtime,omitempty"`
+	Retries      RetryStats     `json:"retries"`
+
+	// Stages aggregates step statuses per stage, for workflows whose
+	// steps declare one
+	Stages []StageResult `json:"stages,omitempty"`
+}
+
+// RetryStats aggregates retry behavior across all steps of a run
+type RetryStats struct {
+	TotalRetries   int    `json:"total_retries"`
+	StepsRetried   int    `json:"steps_retried"`
+	BackoffTotal   string `json:"backoff_total"`
+	BackoffTotalMs int64  `json:"backoff_total_ms"`
+}
+
+// NewRetryStats computes retry aggregates from the steps' attempt history
+func NewRetryStats(steps []StepExec) RetryStats {
+	var stats RetryStats
+	var backoff time.Duration
+	for _, step := range steps {
+		if len(step.Attempts) > 1 {
+			stats.TotalRetries += len(step.Attempts) - 1
+			stats.StepsRetried++
+		}
+		for _, a := range step.Attempts {
+			if a.BackoffDelay == "" {
+				continue
+			}
+			if d, err := time.ParseDuration(a.BackoffDelay); err == nil {
+				backoff += d
+			}
+		}
+	}
+	stats.BackoffTotal = backoff.String()
+	stats.BackoffTotalMs = backoff.Milliseconds()
+	return stats
 }
 
 // StepExec records the execution of a single step
 type StepExec struct {
-	Name      string        `json:"name"`
-	Handler   string        `json:"handler"`
-	Status    string        `json:"status"` // Succeeded, Failed, Skipped
-	Duration  string        `json:"duration"`
-	Messages  []Message     `json:"messages,omitempty"`
-	Output    map[string]any `json:"output,omitempty"`
-	Error     string        `json:"error,omitempty"`
+	Name       string          `json:"name"`
+	Handler    string          `json:"handler"`
+	Status     string          `json:"status"` // Succeeded, Failed, Skipped, Cached
+	Duration   string          `json:"duration"`
+	Messages   []Message       `json:"messages,omitempty"`
+	Output     map[string]any  `json:"output,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	SkipReason string          `json:"skip_reason,omitempty"`
+	ExitCode   *int            `json:"exit_code,omitempty"`
+	ExitReason string          `json:"exit_reason,omitempty"`
+	Attempts   []AttemptRecord `json:"attempts,omitempty"`
+
+	// FailureKind classifies why a failed step failed
+	FailureKind FailureKind `json:"failure_kind,omitempty"`
+
+	// VarsSnapshot is the vars state right after the step's updates were
+	// applied, recorded with LocalRunnerConfig.SnapshotVars
+	VarsSnapshot map[string]any `json:"vars_snapshot,omitempty"`
+
+	// Stage is the reporting stage the step belongs to, if any
+	Stage string `json:"stage,omitempty"`
+
+	// SlowerThanExpected is set when the step ran past its expected_seconds
+	// hint times the slow step factor
+	SlowerThanExpected bool `json:"slower_than_expected,omitempty"`
+}
+
+// FailureKind classifies a step failure so tooling can react per kind
+type FailureKind string
+
+// Failure kinds
+const (
+	FailureHandlerNotFound FailureKind = "handler_not_found"
+	FailureEnv             FailureKind = "env"
+	FailureParams          FailureKind = "params"
+	FailureHandlerError    FailureKind = "handler_error"
+	FailureStrictWarning   FailureKind = "strict_warning"
+	FailurePanic           FailureKind = "panic"
+	FailureTimeout         FailureKind = "timeout"
+	FailureCancelled       FailureKind = "cancelled"
+	FailureInjected        FailureKind = "injected"
+	FailureHook            FailureKind = "hook"
+)
+
+// AttemptRecord records a single attempt of a step, including retries
+type AttemptRecord struct {
+	Attempt      int    `json:"attempt"`
+	Status       string `json:"status"` // Succeeded, Failed, Skipped
+	Duration     string `json:"duration"`
+	BackoffDelay string `json:"backoff_delay,omitempty"`
+	Error        string `json:"error,omitempty"`
+
+	FailureKind FailureKind `json:"failure_kind,omitempty"`
 }
 
 // Deps provides external dependencies to step handlers
 type Deps struct {
 	Workdir string
 	Logger  func(format string, args ...any)
+
+	// Stdout streams live output to the console, prefixed with the step name
+	Stdout io.Writer
+
+	// Env is the step environment declared in the workflow
+	Env map[string]string
+
+	// Rand is the step's random source, seeded from the run's seed; use it
+	// instead of the global source so runs can be reproduced with the same
+	// seed
+	Rand *rand.Rand
+
+	// Context is cancelled when the run is interrupted; handlers that
+	// block or wait should return when it is done
+	Context context.Context
+
+	// Stdin is the console input, for handlers that prompt the user
+	Stdin io.Reader
+
+	// Approved is set when the run was started with pre-approval
+	// (taskkit workflow run --approve) for gate steps
+	Approved bool
+
+	// Values carries caller-supplied dependencies (an HTTP client, a DB
+	// handle) from LocalRunnerConfig.Values; treat it as read-only
+	Values map[string]any
+
+	// temps tracks TempFile/TempDir paths for cleanup at the end of a run
+	temps *tempTracker
+
+	// handlerConfig is the workflow's handler_config, read through
+	// HandlerConfig
+	handlerConfig map[string]any
+}
+
+// Value returns a caller-supplied dependency from Values
+func (d Deps) Value(key string) (any, bool) {
+	v, ok := d.Values[key]
+	return v, ok
 }
 
 // ToJSON serializes any value to JSON string
@@ -0,0 +1,49 @@
+package taskkit
+
+import "testing"
+
+func TestGetParamPath(t *testing.T) {
+	input := StepInput{Params: map[string]any{
+		"db":    map[string]any{"host": "db.local", "port": float64(5432), "tls": true},
+		"hosts": []any{"a", map[string]any{"name": "b"}},
+		"a/b":   map[string]any{"~c": "escaped"},
+	}}
+
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{"db.host", "db.local", true},
+		{"/db/host", "db.local", true},
+		{"hosts.0", "a", true},
+		{"/hosts/1/name", "b", true},
+		{"/a~1b/~0c", "escaped", true},
+		{"db.user", nil, false},
+		{"hosts.5", nil, false},
+		{"hosts.x", nil, false},
+		{"db.host.more", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := input.GetParamPath(tt.path)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("GetParamPath(%q) = %v, %v; want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if s, ok := input.GetParamPathString("db.host"); !ok || s != "db.local" {
+		t.Errorf("GetParamPathString = %q, %v", s, ok)
+	}
+	if _, ok := input.GetParamPathString("db.port"); ok {
+		t.Error("GetParamPathString accepted a number")
+	}
+	if n, ok := input.GetParamPathInt("db.port"); !ok || n != 5432 {
+		t.Errorf("GetParamPathInt = %d, %v", n, ok)
+	}
+	if b, ok := input.GetParamPathBool("/db/tls"); !ok || !b {
+		t.Errorf("GetParamPathBool = %v, %v", b, ok)
+	}
+	if _, ok := (&StepInput{}).GetParamPath("db.host"); ok {
+		t.Error("GetParamPath on nil params succeeded")
+	}
+}
@@ -0,0 +1,73 @@
+package taskkit
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RegisterAll calls each module's registration function in order. Task
+// packages normally register from init() via a blank import; RegisterAll is
+// for embedding programs whose modules expose an explicit register func.
+func RegisterAll(modules ...func()) {
+	for _, register := range modules {
+		register()
+	}
+}
+
+// ModuleInfo describes a task package compiled into the binary
+type ModuleInfo struct {
+	// Name is the last element of Package (e.g. "gate")
+	Name string `json:"name"`
+	// Package is the Go import path that registered the handlers
+	Package string `json:"package"`
+	// Handlers are the handler names the package registered, sorted
+	Handlers []string `json:"handlers"`
+}
+
+// Modules groups the registered handlers by the package that registered
+// them, sorted by module name
+func Modules() []ModuleInfo {
+	byPackage := make(map[string]*ModuleInfo)
+	for _, info := range Handlers() {
+		m, ok := byPackage[info.Package]
+		if !ok {
+			m = &ModuleInfo{Name: path.Base(info.Package), Package: info.Package}
+			byPackage[info.Package] = m
+		}
+		m.Handlers = append(m.Handlers, info.Name)
+	}
+
+	modules := make([]ModuleInfo, 0, len(byPackage))
+	for _, m := range byPackage {
+		modules = append(modules, *m)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Package < modules[j].Package })
+	sort.SliceStable(modules, func(i, j int) bool { return modules[i].Name < modules[j].Name })
+	return modules
+}
+
+// RequireModules returns an error naming any module that has no handlers
+// registered in this binary. Names match ModuleInfo.Name or Package.
+func RequireModules(names []string) error {
+	modules := Modules()
+	available := make([]string, 0, len(modules))
+	present := make(map[string]bool, 2*len(modules))
+	for _, m := range modules {
+		available = append(available, m.Name)
+		present[m.Name] = true
+		present[m.Package] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("modules not compiled in: %s (available: %s)", strings.Join(missing, ", "), strings.Join(available, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Registration is attributed to the calling package, so these tests
+// register from an external test package to tell it apart from taskkit.
+package taskkit_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+const testModule = "github.com/erauner/homelab-task-go/pkg/taskkit_test"
+
+func handleNamed(taskkit.StepInput, taskkit.Deps) taskkit.StepResult {
+	return taskkit.NewStepResult()
+}
+
+func registerModule() {
+	taskkit.Register("module-named", handleNamed)
+	taskkit.RegisterWithTest("module-tested", handleNamed, func() error { return nil })
+	taskkit.RegisterTyped("module-typed", func(ctx context.Context, p struct{}, deps taskkit.Deps) (struct{}, error) {
+		return struct{}{}, nil
+	})
+}
+
+func isolateRegistry(t *testing.T) {
+	t.Helper()
+	snap := taskkit.SnapshotRegistry()
+	t.Cleanup(func() { taskkit.RestoreRegistry(snap) })
+	taskkit.RestoreRegistry(taskkit.RegistrySnapshot{})
+}
+
+func TestModulesAttributeRegisteringPackage(t *testing.T) {
+	isolateRegistry(t)
+	taskkit.RegisterAll(registerModule)
+	if _, err := taskkit.StubHandlers("module-stubbed", taskkit.NewStepResult()); err != nil {
+		t.Fatal(err)
+	}
+
+	modules := taskkit.Modules()
+	if len(modules) != 1 {
+		t.Fatalf("modules = %+v, want one", modules)
+	}
+	m := modules[0]
+	want := "module-named,module-stubbed,module-tested,module-typed"
+	if m.Name != "taskkit_test" || m.Package != testModule || strings.Join(m.Handlers, ",") != want {
+		t.Errorf("module = %+v, want taskkit_test with %s", m, want)
+	}
+
+	functions := map[string]string{}
+	for _, info := range taskkit.Handlers() {
+		functions[info.Name] = info.Function
+	}
+	// Closures built inside taskkit are not the registering package's functions
+	wantFunctions := map[string]string{
+		"module-named":   "handleNamed",
+		"module-tested":  "handleNamed",
+		"module-typed":   "",
+		"module-stubbed": "",
+	}
+	for name, fn := range wantFunctions {
+		if functions[name] != fn {
+			t.Errorf("%s function = %q, want %q", name, functions[name], fn)
+		}
+	}
+}
+
+func TestStubKeepsRegisteringPackage(t *testing.T) {
+	isolateRegistry(t)
+	registerModule()
+	if _, err := taskkit.StubHandlers("module-*", taskkit.NewStepResult()); err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range taskkit.Handlers() {
+		if info.Package != testModule {
+			t.Errorf("%s package = %q after stubbing, want %s", info.Name, info.Package, testModule)
+		}
+	}
+}
+
+func TestRequireModules(t *testing.T) {
+	isolateRegistry(t)
+	registerModule()
+
+	if err := taskkit.RequireModules([]string{"taskkit_test", testModule}); err != nil {
+		t.Errorf("RequireModules(registered) = %v, want nil", err)
+	}
+	err := taskkit.RequireModules([]string{"taskkit_test", "gate", "nope"})
+	if err == nil {
+		t.Fatal("RequireModules with unknown names succeeded, want error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "gate, nope") || !strings.Contains(msg, "available: taskkit_test") {
+		t.Errorf("error = %q, want the unknown names and available modules", msg)
+	}
+}
+
+func TestExportHandlersRoundTrips(t *testing.T) {
+	isolateRegistry(t)
+	taskkit.RegisterAll(registerModule)
+	path := filepath.Join(t.TempDir(), "handlers.json")
+
+	if err := taskkit.ExportHandlers(path); err != nil {
+		t.Fatalf("ExportHandlers: %v", err)
+	}
+	export, err := taskkit.LoadHandlerExport(path)
+	if err != nil {
+		t.Fatalf("LoadHandlerExport: %v", err)
+	}
+	if export.SchemaVersion != taskkit.HandlerExportVersion {
+		t.Errorf("schema_version = %d, want %d", export.SchemaVersion, taskkit.HandlerExportVersion)
+	}
+	if want := taskkit.Handlers(); !reflect.DeepEqual(export.Handlers, want) {
+		t.Errorf("exported handlers = %+v\nwant %+v", export.Handlers, want)
+	}
+}
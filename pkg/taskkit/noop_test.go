@@ -0,0 +1,54 @@
+package taskkit
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAllStepsFilteredIsNoOp(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-ping":   succeed,
+		"test-wipe":   succeed,
+		"test-legacy": succeed,
+	})
+	workdir := t.TempDir()
+
+	result, _ := runTestWorkflow(t, `
+name: noop
+platform: test
+steps:
+  - name: ping
+    tags: [network]
+  - name: wipe
+    tags: [destructive]
+  - name: legacy
+    disabled: true
+`, LocalRunnerConfig{Workdir: workdir, Tags: []string{"storage"}})
+	if result.Result != "NoOp" {
+		t.Fatalf("result = %s, want NoOp", result.Result)
+	}
+	for name, status := range stepStatuses(result) {
+		if status != "Skipped" {
+			t.Errorf("%s status = %s, want Skipped", name, status)
+		}
+	}
+
+	// The result is still written, and maps to its own exit code
+	saved, err := LoadExecutionResult(filepath.Join(workdir, "execution-result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Result != "NoOp" {
+		t.Errorf("saved result = %s, want NoOp", saved.Result)
+	}
+	codes := DefaultExitCodeMap()
+	if err := codes.Set("NoOp=5"); err != nil {
+		t.Fatal(err)
+	}
+	if got := codes.Code(result); got != 5 {
+		t.Errorf("exit code = %d, want 5", got)
+	}
+	if got := DefaultExitCodeMap().Code(result); got != 0 {
+		t.Errorf("default exit code = %d, want 0", got)
+	}
+}
@@ -0,0 +1,104 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// executionOrder returns the names of the workflow's steps in execution order
+func executionOrder(t *testing.T, workflow string) []string {
+	t.Helper()
+	steps, err := loadTestWorkflow(t, workflow).GetExecutionOrder()
+	if err != nil {
+		t.Fatalf("GetExecutionOrder: %v", err)
+	}
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+func TestFinalizeRunsLast(t *testing.T) {
+	got := executionOrder(t, `
+name: finalize-last
+platform: test
+steps:
+  - name: report
+    template: finalize
+  - name: notify
+    template: finalize
+    depends: [report]
+  - name: build
+  - name: deploy
+    depends: [build]
+`)
+	// Finalize steps follow every other step, in their own declared order
+	want := []string{"build", "deploy", "report", "notify"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestInitRunsFirst(t *testing.T) {
+	got := executionOrder(t, `
+name: init-first
+platform: test
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+  - name: setup
+    template: init
+  - name: credentials
+    template: init
+    depends: [setup]
+`)
+	want := []string{"setup", "credentials", "build", "deploy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestInitConflictingOrdering(t *testing.T) {
+	wf := loadTestWorkflow(t, `
+name: init-conflict
+platform: test
+steps:
+  - name: setup
+    template: init
+    depends: [credentials]
+  - name: credentials
+    template: init
+    depends: [setup]
+  - name: build
+`)
+	_, err := wf.GetExecutionOrder()
+	if err == nil || !strings.Contains(err.Error(), "init steps have conflicting ordering: setup, credentials") {
+		t.Errorf("GetExecutionOrder error = %v, want the conflicting init steps named", err)
+	}
+}
+
+func TestPriorityOrdersReadySteps(t *testing.T) {
+	got := executionOrder(t, `
+name: priority
+platform: test
+steps:
+  - name: fetch
+  - name: lint
+  - name: compile
+    priority: 10
+  - name: test
+    depends: [fetch]
+    priority: 5
+  - name: docs
+    priority: -1
+`)
+	// compile and test outrank their peers once ready; equal priorities
+	// keep declaration order
+	want := []string{"compile", "fetch", "test", "lint", "docs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func outputHandlers(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("image", map[string]any{"tag": "v1.2", "digest": "sha256:abc"})
+			return result
+		},
+		"test-deploy": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("url", "https://app.local")
+			return result
+		},
+	})
+}
+
+func TestWorkflowOutputs(t *testing.T) {
+	outputHandlers(t)
+	result, _ := runTestWorkflow(t, `
+name: outputs
+platform: test
+outputs:
+  tag: build.image.tag
+  url: deploy.url
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s (%s), want Succeeded", result.Result, result.ErrorMessage)
+	}
+	want := map[string]any{"tag": "v1.2", "url": "https://app.local"}
+	if !reflect.DeepEqual(result.Outputs, want) {
+		t.Errorf("outputs = %v, want %v", result.Outputs, want)
+	}
+}
+
+func TestWorkflowOutputsMissing(t *testing.T) {
+	outputHandlers(t)
+	result, _ := runTestWorkflow(t, `
+name: outputs
+platform: test
+outputs:
+  tag: build.image.tag
+  version: build.version
+steps:
+  - name: build
+`, LocalRunnerConfig{})
+	if result.Result != "Error" {
+		t.Errorf("result = %s, want Error", result.Result)
+	}
+	if !strings.Contains(result.ErrorMessage, "missing workflow outputs: version (build.version)") {
+		t.Errorf("error = %q", result.ErrorMessage)
+	}
+	// Outputs that resolved are still reported
+	if result.Outputs["tag"] != "v1.2" {
+		t.Errorf("outputs = %v, want tag kept", result.Outputs)
+	}
+}
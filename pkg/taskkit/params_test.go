@@ -0,0 +1,112 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParamOverridesBeatParamsFile(t *testing.T) {
+	var seen map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			seen = input.Params
+			return NewStepResult()
+		},
+	})
+	params := writeFile(t, t.TempDir(), "params.json", `{"replicas": 1, "image": "app:1.0"}`)
+
+	result, _ := runTestWorkflow(t, `
+name: override
+platform: test
+steps:
+  - name: deploy
+    params:
+      replicas: 2
+`, LocalRunnerConfig{
+		ParamsPath:     params,
+		ParamOverrides: map[string]any{"replicas": float64(3)},
+	})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	if seen["replicas"] != float64(3) {
+		t.Errorf("replicas = %v (%T), want the override 3 over file and step params", seen["replicas"], seen["replicas"])
+	}
+	// Keys that are not overridden keep their file value
+	if seen["image"] != "app:1.0" {
+		t.Errorf("image = %v, want app:1.0 from the params file", seen["image"])
+	}
+}
+
+func TestParamEnvFallback(t *testing.T) {
+	var seen map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			seen = input.Params
+			return NewStepResult()
+		},
+	})
+	t.Setenv("TASKKIT_PARAM_REGION", "eu-west")
+	t.Setenv("TASKKIT_PARAM_REPLICAS", "5")
+	t.Setenv("TASKKIT_PARAM_IMAGE", "app:env")
+	params := writeFile(t, t.TempDir(), "params.json", `{"image": "app:1.0"}`)
+	const workflow = `
+name: env-fallback
+platform: test
+steps:
+  - name: deploy
+`
+
+	runTestWorkflow(t, workflow, LocalRunnerConfig{ParamsPath: params, ParamEnvPrefix: "TASKKIT_PARAM_"})
+	if seen["region"] != "eu-west" || seen["replicas"] != float64(5) {
+		t.Errorf("region, replicas = %v, %v (%T), want eu-west and 5 from the environment",
+			seen["region"], seen["replicas"], seen["replicas"])
+	}
+	// The params file wins over the environment
+	if seen["image"] != "app:1.0" {
+		t.Errorf("image = %v, want app:1.0 from the params file", seen["image"])
+	}
+
+	// Without a prefix the environment is ignored
+	runTestWorkflow(t, workflow, LocalRunnerConfig{ParamsPath: params})
+	if _, ok := seen["region"]; ok {
+		t.Errorf("region = %v with no prefix set, want unset", seen["region"])
+	}
+}
+
+func TestDefaultParamsPrecedence(t *testing.T) {
+	seen := map[string]map[string]any{}
+	record := func(name string) StepHandler {
+		return func(input StepInput, deps Deps) StepResult {
+			seen[name] = input.Params
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-web": record("web"),
+		"test-api": record("api"),
+	})
+
+	runTestWorkflow(t, `
+name: defaults
+platform: test
+default_params:
+  region: eu-west
+  replicas: 1
+  tier: standard
+steps:
+  - name: web
+  - name: api
+    params:
+      replicas: 3
+`, LocalRunnerConfig{ParamOverrides: map[string]any{"tier": "premium"}})
+
+	want := map[string]map[string]any{
+		"web": {"region": "eu-west", "replicas": 1, "tier": "premium"},
+		// Step params beat defaults; --param overrides beat both
+		"api": {"region": "eu-west", "replicas": 3, "tier": "premium"},
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("params = %v, want %v", seen, want)
+	}
+}
@@ -0,0 +1,175 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Plan actions
+const (
+	PlanRun         = "run"
+	PlanSkip        = "skip"
+	PlanConditional = "conditional"
+)
+
+// StepPlan describes what a run would do with a step
+type StepPlan struct {
+	Step    string `json:"step"`
+	Handler string `json:"handler"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// PlanInputs are the starting values of a run to plan for
+type PlanInputs struct {
+	// Vars are the starting vars, layered over the workflow's vars
+	Vars map[string]any
+
+	// Params are the run's params, as from --params
+	Params map[string]any
+
+	// ParamOverrides override step params, as --param does
+	ParamOverrides map[string]any
+
+	// ParamEnvPrefix enables env fallback params, as in LocalRunnerConfig
+	ParamEnvPrefix string
+}
+
+// PlanWithConditions predicts which steps would run or be skipped given the
+// starting vars and params, in execution order. The params act as
+// overrides, like --param in a run, so they take precedence over step
+// params. It returns an error when the steps cannot be ordered (an unknown
+// dependency or a cycle), since a run would then execute nothing but
+// finalize steps and there is no plan to report.
+func (w *WorkflowDefinition) PlanWithConditions(vars, params map[string]any) ([]StepPlan, error) {
+	return w.PlanFor(PlanInputs{Vars: vars, ParamOverrides: params})
+}
+
+// PlanFor is PlanWithConditions for all of a run's param sources, which are
+// merged exactly as LocalRunner merges them. When conditions see only the
+// starting vars, not vars set by earlier steps, and steps gated on
+// IfStepStatus are reported as conditional since they depend on runtime
+// results.
+func (w *WorkflowDefinition) PlanFor(in PlanInputs) ([]StepPlan, error) {
+	steps, err := w.GetExecutionOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions, err := w.ExcludedSteps(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[string]string, len(exclusions))
+	for _, ex := range exclusions {
+		excluded[ex.Step] = ex.Reason
+	}
+
+	combined := make(map[string]any)
+	for k, v := range w.Vars {
+		combined[k] = v
+	}
+	for k, v := range in.Vars {
+		combined[k] = v
+	}
+	envParams := paramsFromEnv(in.ParamEnvPrefix, os.Environ())
+
+	plan := make([]StepPlan, 0, len(steps))
+	for _, step := range steps {
+		sp := StepPlan{
+			Step:    step.Name,
+			Handler: w.GetHandlerName(step),
+			Action:  PlanRun,
+		}
+		stepParams := w.layerParams(envParams, in.Params, step.Params, in.ParamOverrides)
+
+		if reason, ok := excluded[step.Name]; ok {
+			sp.Action = PlanSkip
+			sp.Reason = reason
+		} else if ok, reason := step.CheckWhen(combined, stepParams); !ok {
+			sp.Action = PlanSkip
+			sp.Reason = reason
+		} else if len(step.IfStepStatus) > 0 {
+			var conds []string
+			for _, name := range sortedKeys(step.IfStepStatus) {
+				conds = append(conds, fmt.Sprintf("%s=%s", name, step.IfStepStatus[name]))
+			}
+			sp.Action = PlanConditional
+			sp.Reason = "depends on step status " + strings.Join(conds, ", ")
+		}
+		plan = append(plan, sp)
+	}
+	return plan, nil
+}
+
+// PlannedStep is one step of an ExecutionPlan
+type PlannedStep struct {
+	Name     string       `json:"name"`
+	Handler  string       `json:"handler"`
+	Template StepTemplate `json:"template,omitempty"`
+
+	// Batch is the index of the step's batch in ExecutionPlan.Batches
+	Batch int `json:"batch"`
+
+	// WaitsFor lists every step that must finish first: depends,
+	// if_step_status and present soft_depends steps, and all steps of an
+	// earlier phase (init before action before finalize)
+	WaitsFor []string `json:"waits_for,omitempty"`
+
+	// Definition is the step as declared, for executors that run it
+	Definition WorkflowStep `json:"-"`
+}
+
+// ExecutionPlan is a workflow's schedule, for external schedulers that
+// drive execution themselves instead of using LocalRunner
+type ExecutionPlan struct {
+	Workflow string `json:"workflow"`
+
+	// Steps are in the order LocalRunner executes them
+	Steps []PlannedStep `json:"steps"`
+
+	// Batches group step names that may run in parallel; every step of a
+	// batch waits only for steps of earlier batches
+	Batches [][]string `json:"batches"`
+}
+
+// Plan computes the workflow's ExecutionPlan. It does not evaluate when
+// conditions, tags, or disabled flags; see PlanWithConditions for that.
+func (w *WorkflowDefinition) Plan() (*ExecutionPlan, error) {
+	order, err := w.GetExecutionOrder()
+	if err != nil {
+		return nil, err
+	}
+	stepMap := make(map[string]WorkflowStep, len(w.Steps))
+	for _, step := range w.Steps {
+		stepMap[step.Name] = step
+	}
+	waits := w.schedulingDeps(stepMap)
+
+	plan := &ExecutionPlan{Workflow: w.Name, Steps: make([]PlannedStep, 0, len(order))}
+	batch := make(map[string]int, len(order))
+	for _, step := range order {
+		// Dependencies come earlier in the order, so their batches are known
+		b := 0
+		for _, dep := range waits[step.Name] {
+			if batch[dep]+1 > b {
+				b = batch[dep] + 1
+			}
+		}
+		batch[step.Name] = b
+		for len(plan.Batches) <= b {
+			plan.Batches = append(plan.Batches, nil)
+		}
+		plan.Batches[b] = append(plan.Batches[b], step.Name)
+		plan.Steps = append(plan.Steps, PlannedStep{
+			Name:       step.Name,
+			Handler:    w.GetHandlerName(step),
+			Template:   step.Template,
+			Batch:      b,
+			WaitsFor:   waits[step.Name],
+			Definition: step,
+		})
+	}
+	return plan, nil
+}
@@ -0,0 +1,133 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+const planWorkflow = `
+name: plan
+platform: test
+vars:
+  enabled: false
+steps:
+  - name: always
+  - name: feature
+    when: vars.enabled
+  - name: prod-only
+    when: params.env == 'prod'
+    params:
+      env: staging
+  - name: regional
+    when: params.region == 'eu'
+  - name: legacy
+    disabled: true
+  - name: after-legacy
+    depends: [legacy]
+  - name: on-failure
+    if_step_status:
+      always: Failed
+`
+
+func planActions(plan []StepPlan) map[string]string {
+	actions := make(map[string]string, len(plan))
+	for _, sp := range plan {
+		actions[sp.Step] = sp.Action
+	}
+	return actions
+}
+
+func TestPlanWithConditions(t *testing.T) {
+	wf := loadTestWorkflow(t, planWorkflow)
+
+	plan, err := wf.PlanWithConditions(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"always":       PlanRun,
+		"feature":      PlanSkip,
+		"prod-only":    PlanSkip,
+		"regional":     PlanSkip,
+		"legacy":       PlanSkip,
+		"after-legacy": PlanSkip,
+		"on-failure":   PlanConditional,
+	}
+	if got := planActions(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("actions = %v, want %v", got, want)
+	}
+	for _, sp := range plan {
+		if sp.Action != PlanRun && sp.Reason == "" {
+			t.Errorf("step %s: %s without a reason", sp.Step, sp.Action)
+		}
+	}
+
+	plan, err = wf.PlanWithConditions(map[string]any{"enabled": true}, map[string]any{"region": "eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := planActions(plan)
+	if got["feature"] != PlanRun || got["regional"] != PlanRun {
+		t.Errorf("actions with vars and params = %v, want feature and regional to run", got)
+	}
+}
+
+// The plan must agree with a run on param precedence: --param overrides
+// beat step params, and env fallback params apply
+func TestPlanMatchesRunParams(t *testing.T) {
+	t.Setenv("TEST_PLAN_REGION", "eu")
+	wf := loadTestWorkflow(t, planWorkflow)
+	inputs := PlanInputs{
+		Params:         map[string]any{"env": "dev"},
+		ParamOverrides: map[string]any{"env": "prod"},
+		ParamEnvPrefix: "TEST_PLAN_",
+	}
+	plan, err := wf.PlanFor(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	predicted := planActions(plan)
+
+	handlers := make(map[string]StepHandler)
+	for _, step := range wf.Steps {
+		handlers[wf.GetHandlerName(step)] = succeed
+	}
+	registerHandlers(t, handlers)
+	result, _ := runTestWorkflow(t, planWorkflow, LocalRunnerConfig{
+		ParamOverrides: inputs.ParamOverrides,
+		ParamEnvPrefix: inputs.ParamEnvPrefix,
+	})
+
+	for _, name := range []string{"prod-only", "regional"} {
+		if predicted[name] != PlanRun {
+			t.Errorf("plan for %s = %s, want run", name, predicted[name])
+		}
+		if got := stepByName(t, result, name).Status; got != "Succeeded" {
+			t.Errorf("run status of %s = %s, want Succeeded", name, got)
+		}
+	}
+	for _, sp := range plan {
+		if sp.Action == PlanConditional {
+			continue
+		}
+		status := stepByName(t, result, sp.Step).Status
+		if (sp.Action == PlanSkip) != (status == "Skipped") {
+			t.Errorf("step %s: plan %s but run %s", sp.Step, sp.Action, status)
+		}
+	}
+}
+
+func TestPlanWithConditionsCycle(t *testing.T) {
+	wf := loadTestWorkflow(t, `
+name: cycle
+platform: test
+steps:
+  - name: a
+    depends: [b]
+  - name: b
+    depends: [a]
+`)
+	if _, err := wf.PlanWithConditions(nil, nil); err == nil {
+		t.Error("plan of a cyclic workflow succeeded, want error")
+	}
+}
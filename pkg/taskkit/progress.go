@@ -0,0 +1,117 @@
+package taskkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// IsTerminal reports whether w is an interactive terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progressRenderer draws an in-place "[3/7] check" status line with a
+// spinner while a step runs. Other console output written through it
+// clears the status line first and redraws it afterwards.
+type progressRenderer struct {
+	mu     sync.Mutex
+	out    io.Writer
+	total  int
+	index  int
+	name   string
+	frame  int
+	active bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newProgressRenderer(out io.Writer) *progressRenderer {
+	return &progressRenderer{out: out}
+}
+
+// begin starts the status line for a step and animates it until end
+func (p *progressRenderer) begin(index, total int, name string) {
+	p.mu.Lock()
+	p.index, p.total, p.name = index, total, name
+	p.frame = 0
+	p.active = true
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	p.draw()
+	p.mu.Unlock()
+
+	go p.animate(p.stop, p.done)
+}
+
+func (p *progressRenderer) animate(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.frame++
+			p.draw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// end replaces the status line with the step's final status
+func (p *progressRenderer) end(status, duration string) {
+	p.mu.Lock()
+	if !p.active {
+		p.mu.Unlock()
+		return
+	}
+	p.active = false
+	stop, done := p.stop, p.done
+	p.mu.Unlock()
+
+	close(stop)
+	<-done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.out, "\r\033[K[%d/%d] %s: %s (%s)\n", p.index, p.total, p.name, status, duration)
+}
+
+// draw renders the status line; callers hold p.mu
+func (p *progressRenderer) draw() {
+	if !p.active {
+		return
+	}
+	frame := spinnerFrames[p.frame%len(spinnerFrames)]
+	fmt.Fprintf(p.out, "\r\033[K[%d/%d] %s %s", p.index, p.total, frame, p.name)
+}
+
+// Write implements io.Writer for output interleaved with the status line
+func (p *progressRenderer) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active {
+		fmt.Fprint(p.out, "\r\033[K")
+	}
+	n, err := p.out.Write(b)
+	if p.active && len(b) > 0 && b[len(b)-1] == '\n' {
+		p.draw()
+	}
+	return n, err
+}
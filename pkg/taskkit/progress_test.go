@@ -0,0 +1,61 @@
+package taskkit
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+const progressWorkflow = `
+name: progress
+platform: test
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+`
+
+func TestIsTerminalFalseForNonTTY(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	for name, out := range map[string]io.Writer{"buffer": &bytes.Buffer{}, "file": file, "pipe": w} {
+		if IsTerminal(out) {
+			t.Errorf("IsTerminal(%s) = true", name)
+		}
+	}
+}
+
+func TestPlainOutputWithoutProgress(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed, "test-deploy": succeed})
+	_, out := runTestWorkflow(t, progressWorkflow, LocalRunnerConfig{})
+	if strings.Contains(out, "\r") || strings.Contains(out, "\033[K") {
+		t.Errorf("plain output contains terminal control sequences: %q", out)
+	}
+	for _, line := range []string{"--- Step: build", "--- Step: deploy"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("plain output missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestProgressOutput(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed, "test-deploy": succeed})
+	_, out := runTestWorkflow(t, progressWorkflow, LocalRunnerConfig{Progress: true})
+	for _, line := range []string{"[1/2] build: Succeeded", "[2/2] deploy: Succeeded"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("progress output missing %q:\n%q", line, out)
+		}
+	}
+}
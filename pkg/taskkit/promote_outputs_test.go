@@ -0,0 +1,47 @@
+package taskkit
+
+import "testing"
+
+func TestPromoteOutputs(t *testing.T) {
+	var seen map[string]any
+	var image any
+	output := func(key string, value any) StepHandler {
+		return func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput(key, value)
+			return result
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": output("image", "app:1.2"),
+		"test-scan":  output("findings", 0),
+		"test-deploy": func(input StepInput, deps Deps) StepResult {
+			seen = input.Vars
+			image = input.GetVar("build.image")
+			return NewStepResult()
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: promote
+platform: test
+steps:
+  - name: build
+    promote_outputs: true
+  - name: scan
+  - name: deploy
+    depends: [build, scan]
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	if image != "app:1.2" {
+		t.Errorf("GetVar(build.image) = %v, want app:1.2 promoted from build's output", image)
+	}
+	// Outputs of steps without promote_outputs stay out of vars
+	for _, key := range []string{"scan.findings", "findings", "image"} {
+		if v, ok := seen[key]; ok {
+			t.Errorf("var %s = %v, want unset", key, v)
+		}
+	}
+}
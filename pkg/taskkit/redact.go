@@ -0,0 +1,179 @@
+package taskkit
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces sensitive values in persisted and printed output
+const redactedValue = "***"
+
+// redactor masks values stored under sensitive keys. It also remembers the
+// string values it has seen under those keys, and secrets resolved from
+// ${FILE:...} params, so they can be masked wherever they show up.
+type redactor struct {
+	mu     sync.Mutex
+	keys   map[string]bool
+	values map[string]bool
+}
+
+func newRedactor(keys []string) *redactor {
+	r := &redactor{
+		keys:   make(map[string]bool, len(keys)),
+		values: make(map[string]bool),
+	}
+	for _, k := range keys {
+		r.keys[strings.ToLower(k)] = true
+	}
+	return r
+}
+
+func (r *redactor) enabled() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.keys) > 0 || len(r.values) > 0
+}
+
+// addValue records a sensitive value regardless of the key it appears under
+func (r *redactor) addValue(v string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[v] = true
+}
+
+func (r *redactor) isSensitive(key string) bool {
+	return r.keys[strings.ToLower(key)]
+}
+
+// collect records string values found under sensitive keys in v
+func (r *redactor) collect(v any) {
+	if !r.enabled() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.walk(v, false)
+}
+
+func (r *redactor) walk(v any, sensitive bool) {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			r.walk(child, sensitive || r.isSensitive(k))
+		}
+	case []any:
+		for _, child := range node {
+			r.walk(child, sensitive)
+		}
+	case string:
+		if sensitive && node != "" {
+			r.values[node] = true
+		}
+	}
+}
+
+// redactValue returns a copy of v with values under sensitive keys replaced
+// and known sensitive values masked inside other strings. Nested maps and
+// slices are copied; other values are shared.
+func (r *redactor) redactValue(v any) any {
+	if !r.enabled() {
+		return v
+	}
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			if r.isSensitive(k) {
+				out[k] = redactedValue
+			} else {
+				out[k] = r.redactValue(child)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = r.redactValue(child)
+		}
+		return out
+	case string:
+		return r.redactText(node)
+	default:
+		return v
+	}
+}
+
+// redactMap is redactValue for the common map case
+func (r *redactor) redactMap(m map[string]any) map[string]any {
+	if !r.enabled() || m == nil {
+		return m
+	}
+	return r.redactValue(m).(map[string]any)
+}
+
+// marshalIndent serializes v as indented JSON with sensitive keys redacted
+func (r *redactor) marshalIndent(v any) ([]byte, error) {
+	return r.encode(JSONResultEncoder{}, v)
+}
+
+// encode serializes v with enc, with sensitive keys redacted
+func (r *redactor) encode(enc ResultEncoder, v any) ([]byte, error) {
+	if !r.enabled() {
+		return enc.Encode(v)
+	}
+	// Round-trip through generic JSON so struct fields and nested maps are
+	// redacted uniformly
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return enc.Encode(r.redactValue(generic))
+}
+
+// containsSensitive reports whether v holds a sensitive key or value
+func (r *redactor) containsSensitive(v any) bool {
+	if !r.enabled() {
+		return false
+	}
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if r.isSensitive(k) || r.containsSensitive(child) {
+				return true
+			}
+		}
+	case []any:
+		for _, child := range node {
+			if r.containsSensitive(child) {
+				return true
+			}
+		}
+	case string:
+		return r.redactText(node) != node
+	}
+	return false
+}
+
+// redactText masks any collected sensitive values appearing in s
+func (r *redactor) redactText(s string) string {
+	if !r.enabled() {
+		return s
+	}
+	r.mu.Lock()
+	values := make([]string, 0, len(r.values))
+	for v := range r.values {
+		values = append(values, v)
+	}
+	r.mu.Unlock()
+
+	// Replace longer values first so overlapping secrets are fully masked
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redactedValue)
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSensitiveKeysRedacted(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-connect": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("session", map[string]any{"token": "tok-abc123", "user": "admin"})
+			result.SetVar("api_key", "key-xyz789")
+			result.AddInfo("connected with pw-s3cret", "test")
+			return result
+		},
+	})
+
+	workdir := t.TempDir()
+	result, out := runTestWorkflow(t, `
+name: redact
+platform: test
+sensitive: [password, token, API_KEY]
+steps:
+  - name: connect
+    params:
+      db:
+        host: db.local
+        password: pw-s3cret
+`, LocalRunnerConfig{Workdir: workdir, Trace: true, DumpParams: true, RecordInputs: true})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	secrets := []string{"pw-s3cret", "tok-abc123", "key-xyz789"}
+	outputs := map[string]string{"console": out}
+	for _, name := range []string{"execution-result.json", "inputs/connect.json"} {
+		data, err := os.ReadFile(filepath.Join(workdir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[name] = string(data)
+	}
+	for name, content := range outputs {
+		for _, secret := range secrets {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s leaks %s:\n%s", name, secret, content)
+			}
+		}
+	}
+	// Non-sensitive siblings of redacted keys are kept
+	if !strings.Contains(outputs["execution-result.json"], `"user": "admin"`) {
+		t.Errorf("execution-result.json lost non-sensitive output:\n%s", outputs["execution-result.json"])
+	}
+	if !strings.Contains(outputs["execution-result.json"], `"token": "***"`) {
+		t.Errorf("execution-result.json does not mask the token:\n%s", outputs["execution-result.json"])
+	}
+}
@@ -0,0 +1,125 @@
+// Package redisstore provides a Redis-backed taskkit.VarStore so vars can be
+// shared across machines instead of living in a local vars.yaml.
+//
+// Vars are stored as a single JSON document under "<prefix><task id>".
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+// DefaultKeyPrefix is prepended to the task ID to form the Redis key
+const DefaultKeyPrefix = "taskkit:vars:"
+
+// Options configures a Store
+type Options struct {
+	// URL is a redis:// or rediss:// connection URL
+	URL string
+	// TaskID scopes the stored vars; required
+	TaskID string
+	// KeyPrefix defaults to DefaultKeyPrefix
+	KeyPrefix string
+	// TTL expires the stored vars after this long (0 keeps them forever)
+	TTL time.Duration
+	// Timeout bounds each Redis call (defaults to 5s)
+	Timeout time.Duration
+	// Fallback, if set, is used when Redis is unreachable instead of failing
+	Fallback taskkit.VarStore
+	// Logger receives fallback warnings
+	Logger func(format string, args ...any)
+}
+
+// Store is a taskkit.VarStore backed by Redis
+type Store struct {
+	client *redis.Client
+	opts   Options
+}
+
+var _ taskkit.VarStore = (*Store)(nil)
+
+// New creates a Store from options
+func New(opts Options) (*Store, error) {
+	if opts.TaskID == "" {
+		return nil, fmt.Errorf("redis var store requires a task ID")
+	}
+	redisOpts, err := redis.ParseURL(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	return NewWithClient(redis.NewClient(redisOpts), opts), nil
+}
+
+// NewWithClient creates a Store around an existing client
+func NewWithClient(client *redis.Client, opts Options) *Store {
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = DefaultKeyPrefix
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = func(string, ...any) {}
+	}
+	return &Store{client: client, opts: opts}
+}
+
+// Key returns the Redis key holding this store's vars
+func (s *Store) Key() string {
+	return s.opts.KeyPrefix + s.opts.TaskID
+}
+
+// Load reads vars from Redis. A missing key yields empty vars.
+func (s *Store) Load() (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	vars := make(map[string]any)
+	data, err := s.client.Get(ctx, s.Key()).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return vars, nil
+	}
+	if err != nil {
+		if s.opts.Fallback != nil {
+			s.opts.Logger("redis load failed, using fallback store: %v", err)
+			return s.opts.Fallback.Load()
+		}
+		return nil, fmt.Errorf("failed to load vars from redis: %w", err)
+	}
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return nil, fmt.Errorf("failed to parse vars from redis: %w", err)
+	}
+	return vars, nil
+}
+
+// Save writes vars to Redis, applying the configured TTL
+func (s *Store) Save(vars map[string]any) error {
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vars: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	if err := s.client.Set(ctx, s.Key(), data, s.opts.TTL).Err(); err != nil {
+		if s.opts.Fallback != nil {
+			s.opts.Logger("redis save failed, using fallback store: %v", err)
+			return s.opts.Fallback.Save(vars)
+		}
+		return fmt.Errorf("failed to save vars to redis: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying client
+func (s *Store) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,149 @@
+package redisstore
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func newTestStore(t *testing.T, opts Options) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	opts.URL = "redis://" + server.Addr()
+	if opts.TaskID == "" {
+		opts.TaskID = "task-1"
+	}
+	store, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	store, server := newTestStore(t, Options{})
+
+	vars, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load before save: %v", err)
+	}
+	if len(vars) != 0 {
+		t.Errorf("vars before save = %v, want none", vars)
+	}
+
+	want := map[string]any{"image": "app:1.2", "count": float64(3), "hosts": []any{"a", "b"}}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !server.Exists(DefaultKeyPrefix + "task-1") {
+		t.Fatalf("key %s not written", store.Key())
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %v, want %v", got, want)
+	}
+}
+
+func TestStoreKeyedByTaskID(t *testing.T) {
+	server := miniredis.RunT(t)
+	stores := make(map[string]*Store)
+	for _, id := range []string{"a", "b"} {
+		store, err := New(Options{URL: "redis://" + server.Addr(), TaskID: id, KeyPrefix: "test:"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		if err := store.Save(map[string]any{"task": id}); err != nil {
+			t.Fatal(err)
+		}
+		stores[id] = store
+	}
+	for id, store := range stores {
+		vars, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vars["task"] != id {
+			t.Errorf("store %s loaded task = %v", id, vars["task"])
+		}
+	}
+	if !server.Exists("test:a") || !server.Exists("test:b") {
+		t.Errorf("keys = %v, want test:a and test:b", server.Keys())
+	}
+}
+
+func TestStoreTTL(t *testing.T) {
+	store, server := newTestStore(t, Options{TTL: time.Hour})
+	if err := store.Save(map[string]any{"k": "v"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.TTL(store.Key()); got != time.Hour {
+		t.Errorf("TTL = %s, want 1h", got)
+	}
+
+	server.FastForward(2 * time.Hour)
+	vars, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vars) != 0 {
+		t.Errorf("vars after expiry = %v, want none", vars)
+	}
+}
+
+func TestStoreConnectionErrors(t *testing.T) {
+	store, server := newTestStore(t, Options{Timeout: time.Second})
+	server.Close()
+
+	if _, err := store.Load(); err == nil {
+		t.Error("Load with Redis down succeeded, want error")
+	}
+	if err := store.Save(map[string]any{"k": "v"}); err == nil {
+		t.Error("Save with Redis down succeeded, want error")
+	}
+}
+
+func TestStoreFallback(t *testing.T) {
+	fallback := taskkit.NewFileVarStore(filepath.Join(t.TempDir(), "vars.yaml"))
+	var warnings []string
+	store, server := newTestStore(t, Options{
+		Timeout:  time.Second,
+		Fallback: fallback,
+		Logger: func(format string, args ...any) {
+			warnings = append(warnings, format)
+		},
+	})
+	server.Close()
+
+	if err := store.Save(map[string]any{"k": "v"}); err != nil {
+		t.Fatalf("Save with fallback: %v", err)
+	}
+	vars, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load with fallback: %v", err)
+	}
+	if vars["k"] != "v" {
+		t.Errorf("Load = %v, want the fallback's vars", vars)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("warnings = %v, want one per fallback", warnings)
+	}
+}
+
+func TestNewValidatesOptions(t *testing.T) {
+	if _, err := New(Options{URL: "redis://localhost:6379"}); err == nil {
+		t.Error("New without a task ID succeeded")
+	}
+	if _, err := New(Options{URL: "http://localhost", TaskID: "t"}); err == nil {
+		t.Error("New with a non-redis URL succeeded")
+	}
+}
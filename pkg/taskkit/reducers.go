@@ -0,0 +1,143 @@
+package taskkit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// VarReducer combines a var's current value with a step's update. ok is
+// false when the var is not set yet.
+type VarReducer func(current any, ok bool, update any) (any, error)
+
+// Built-in reducers
+const (
+	ReducerAppend = "append"
+	ReducerSum    = "sum"
+)
+
+// reduceFlowControl is the FlowControl key holding per-step reducers, set
+// by StepResult.AppendVar
+const reduceFlowControl = "var_reducers"
+
+var (
+	reducers = map[string]VarReducer{
+		ReducerAppend: reduceAppend,
+		ReducerSum:    reduceSum,
+	}
+	reducersLock sync.RWMutex
+)
+
+// RegisterReducer adds a named reducer that workflows can assign to vars
+// with var_reducers. Panics if the name is already registered.
+func RegisterReducer(name string, reducer VarReducer) {
+	reducersLock.Lock()
+	defer reducersLock.Unlock()
+
+	if _, exists := reducers[name]; exists {
+		panic(fmt.Sprintf("var reducer already registered: %s", name))
+	}
+	reducers[name] = reducer
+}
+
+// GetReducer retrieves a reducer by name
+func GetReducer(name string) (VarReducer, bool) {
+	reducersLock.RLock()
+	defer reducersLock.RUnlock()
+
+	reducer, ok := reducers[name]
+	return reducer, ok
+}
+
+// ListReducers returns the registered reducer names, sorted
+func ListReducers() []string {
+	reducersLock.RLock()
+	defer reducersLock.RUnlock()
+
+	names := make([]string, 0, len(reducers))
+	for name := range reducers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reduceAppend appends update to the current list; a list update appends
+// each of its elements. A current non-list value becomes the first element.
+func reduceAppend(current any, ok bool, update any) (any, error) {
+	var list []any
+	if ok && current != nil {
+		if existing, isList := current.([]any); isList {
+			list = append(list, existing...)
+		} else {
+			list = append(list, current)
+		}
+	}
+	if items, isList := update.([]any); isList {
+		return append(list, items...), nil
+	}
+	return append(list, update), nil
+}
+
+// reduceSum adds a numeric update to the current value, keeping integers
+// as int when both sides are integers
+func reduceSum(current any, ok bool, update any) (any, error) {
+	if !ok || current == nil {
+		current = 0
+	}
+	a, aInt, err := toNumber(current)
+	if err != nil {
+		return nil, fmt.Errorf("current value: %w", err)
+	}
+	b, bInt, err := toNumber(update)
+	if err != nil {
+		return nil, fmt.Errorf("update: %w", err)
+	}
+	if aInt && bInt {
+		return int(a) + int(b), nil
+	}
+	return a + b, nil
+}
+
+// toNumber converts a numeric var value, reporting whether it is integral
+func toNumber(v any) (float64, bool, error) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true, nil
+	case int64:
+		return float64(n), true, nil
+	case float64:
+		return n, n == float64(int64(n)), nil
+	}
+	return 0, false, fmt.Errorf("%v (%T) is not a number", v, v)
+}
+
+// applyUpdate writes one context update to vars, through the step's or
+// workflow's reducer for key when one is set
+func (r *LocalRunner) applyUpdate(result StepResult, key string, value any) error {
+	name := r.workflow.VarReducers[key]
+	if stepReducers, ok := result.FlowControl[reduceFlowControl].(map[string]any); ok {
+		if n, ok := stepReducers[key].(string); ok {
+			name = n
+		}
+	}
+	if name == "" {
+		r.vars.Set(key, value)
+		return nil
+	}
+	reducer, ok := GetReducer(name)
+	if !ok {
+		r.vars.Set(key, value)
+		return fmt.Errorf("unknown reducer %q for var %q", name, key)
+	}
+	var reduceErr error
+	r.vars.Update(key, func(current any, ok bool) any {
+		reduced, err := reducer(current, ok, value)
+		if err != nil {
+			reduceErr = fmt.Errorf("reducer %q for var %q failed: %w", name, key, err)
+			return current
+		}
+		return reduced
+	})
+	return reduceErr
+}
@@ -0,0 +1,85 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVarReducersAccumulateAcrossSteps(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-web": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("hosts", "web-1")
+			result.SetVar("checked", 2)
+			result.AppendVar("log", "web started")
+			result.AppendVar("log", "web healthy")
+			return result
+		},
+		"test-db": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("hosts", []any{"db-1", "db-2"})
+			result.SetVar("checked", 3)
+			result.AppendVar("log", "db healthy")
+			return result
+		},
+		"test-cache": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("checked", 0.5)
+			result.SetVar("last", "cache")
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: reducers
+platform: test
+var_reducers:
+  hosts: append
+  checked: sum
+steps:
+  - name: web
+  - name: db
+  - name: cache
+`, LocalRunnerConfig{SetVars: map[string]any{"last": "none"}})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	want := map[string]any{
+		"hosts":   []any{"web-1", "db-1", "db-2"},
+		"checked": 5.5,
+		"log":     []any{"web started", "web healthy", "db healthy"},
+		// Vars without a reducer are still last-writer-wins
+		"last": "cache",
+	}
+	for key, value := range want {
+		if got := result.FinalVars[key]; !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %v (%T), want %v (%T)", key, got, got, value, value)
+		}
+	}
+}
+
+func TestReduceSum(t *testing.T) {
+	if got, _ := reduceSum(2, true, 3); got != 5 {
+		t.Errorf("2 + 3 = %v (%T), want int 5", got, got)
+	}
+	if got, _ := reduceSum(nil, false, 4); got != 4 {
+		t.Errorf("unset + 4 = %v, want 4", got)
+	}
+	if _, err := reduceSum(1, true, "three"); err == nil {
+		t.Error("summing a string succeeded")
+	}
+}
+
+func TestUnknownReducerRejected(t *testing.T) {
+	err := loadError(t, `
+name: reducers
+platform: test
+var_reducers:
+  hosts: concat
+steps:
+  - name: web
+`)
+	if err == nil {
+		t.Error("LoadWorkflow accepted an unknown reducer")
+	}
+}
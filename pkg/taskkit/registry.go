@@ -1,23 +1,43 @@
 package taskkit
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // StepHandler is the function signature for step implementations
 type StepHandler func(input StepInput, deps Deps) StepResult
 
+// SelfTest verifies a handler works, typically by calling it with a canned input
+type SelfTest func() error
+
 var (
-	registry     = make(map[string]StepHandler)
-	registryLock sync.RWMutex
+	registry      = make(map[string]StepHandler)
+	selfTests     = make(map[string]SelfTest)
+	handlerParams = make(map[string][]string)
+	// handlerPackages records the import path that registered each handler
+	handlerPackages = make(map[string]string)
+	registryLock    sync.RWMutex
 )
 
+// ErrNoHandlers is returned by NewLocalRunner when the binary has no
+// handlers at all, usually because no task package was imported
+var ErrNoHandlers = errors.New("no handlers registered; did you import your task packages?")
+
 // Register adds a step handler to the global registry.
 // This is typically called from init() functions in step packages.
 // Panics if a handler with the same name is already registered.
 func Register(name string, handler StepHandler) {
+	pkg := callerPackage()
+
 	registryLock.Lock()
 	defer registryLock.Unlock()
 
@@ -25,6 +45,90 @@ func Register(name string, handler StepHandler) {
 		panic(fmt.Sprintf("step handler already registered: %s", name))
 	}
 	registry[name] = handler
+	handlerPackages[name] = pkg
+}
+
+// taskkitPackage is this package's import path
+var taskkitPackage = reflect.TypeOf(StepInput{}).PkgPath()
+
+// callerPackage returns the import path of the package that called the
+// registering function, skipping frames in taskkit's own wrappers (e.g.
+// RegisterTyped, RegisterWithTest). Test files of this package count as
+// callers. It must be called directly from the exported entry point.
+func callerPackage() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		pkg, _ := symbolLocation(frame.Function)
+		if pkg != taskkitPackage || strings.HasSuffix(frame.File, "_test.go") {
+			return pkg
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// RegisterWithTest adds a step handler along with a self-test that
+// `taskkit test-handlers` can run as a post-deploy health check.
+// Panics if a handler with the same name is already registered.
+func RegisterWithTest(name string, handler StepHandler, test SelfTest) {
+	Register(name, handler)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	selfTests[name] = test
+}
+
+// SelfTestResult is the outcome of running a handler's self-test
+type SelfTestResult struct {
+	Handler  string `json:"handler"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// RunSelfTests runs every registered self-test in name order
+func RunSelfTests() []SelfTestResult {
+	registryLock.RLock()
+	names := make([]string, 0, len(selfTests))
+	for name := range selfTests {
+		names = append(names, name)
+	}
+	tests := make(map[string]SelfTest, len(selfTests))
+	for name, test := range selfTests {
+		tests[name] = test
+	}
+	registryLock.RUnlock()
+	sort.Strings(names)
+
+	results := make([]SelfTestResult, 0, len(names))
+	for _, name := range names {
+		start := time.Now()
+		err := runSelfTest(tests[name])
+		result := SelfTestResult{
+			Handler:  name,
+			Passed:   err == nil,
+			Duration: time.Since(start).String(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runSelfTest runs a self-test, converting a panic into an error
+func runSelfTest(test SelfTest) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return test()
 }
 
 // Get retrieves a step handler by name
@@ -58,6 +162,155 @@ func ListHandlers() []string {
 	return names
 }
 
+// SuggestHandler returns the registered handler closest to name by edit
+// distance, or "" if none is close enough to be a likely typo
+func SuggestHandler(name string) string {
+	best, bestDist := "", -1
+	for _, candidate := range ListHandlers() {
+		d := editDistance(name, candidate)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	limit := len(name) / 4
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDist < 0 || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// HandlerInfo describes a registered handler for tooling outside the binary
+type HandlerInfo struct {
+	// Name is the registered handler name referenced by workflows
+	Name string `json:"name"`
+	// Package is the Go import path that called Register for the handler
+	Package string `json:"package,omitempty"`
+	// Function is the handler function name within Package, empty when the
+	// handler is a closure built elsewhere (e.g. by Typed or StubHandlers)
+	Function string `json:"function,omitempty"`
+	// HasSelfTest reports whether `taskkit test-handlers` covers the handler
+	HasSelfTest bool `json:"has_self_test"`
+}
+
+// HandlerExportVersion is the schema version of HandlerExport files
+const HandlerExportVersion = 1
+
+// HandlerExport is the file written by `taskkit list-handlers --export`:
+//
+//	{
+//	  "schema_version": 1,
+//	  "handlers": [
+//	    {"name": "smoke-test-init", "package": "github.com/.../tasks/smoke_test",
+//	     "function": "HandleInit", "has_self_test": true}
+//	  ]
+//	}
+//
+// Handlers are sorted by name.
+type HandlerExport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Handlers      []HandlerInfo `json:"handlers"`
+}
+
+// Handlers returns information about all registered handlers, sorted by name
+func Handlers() []HandlerInfo {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	infos := make([]HandlerInfo, 0, len(registry))
+	for name, handler := range registry {
+		info := HandlerInfo{Name: name, Package: handlerPackages[name]}
+		_, info.HasSelfTest = selfTests[name]
+		if pkg, fn := funcLocation(handler); pkg == info.Package {
+			info.Function = fn
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// funcLocation splits a function's symbol into import path and name
+func funcLocation(fn any) (string, string) {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "", ""
+	}
+	return symbolLocation(f.Name())
+}
+
+// symbolLocation splits a symbol such as "example.com/pkg.Func.func1" into
+// import path and name
+func symbolLocation(symbol string) (string, string) {
+	slash := strings.LastIndex(symbol, "/")
+	dot := strings.Index(symbol[slash+1:], ".")
+	if dot < 0 {
+		return "", symbol
+	}
+	return symbol[:slash+1+dot], symbol[slash+2+dot:]
+}
+
+// ExportHandlers writes the registered handlers to path as a HandlerExport
+func ExportHandlers(path string) error {
+	data, err := json.MarshalIndent(HandlerExport{
+		SchemaVersion: HandlerExportVersion,
+		Handlers:      Handlers(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal handlers: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write handler export: %w", err)
+	}
+	return nil
+}
+
+// LoadHandlerExport reads a file written by ExportHandlers
+func LoadHandlerExport(path string) (*HandlerExport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handler export: %w", err)
+	}
+	var export HandlerExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse handler export: %w", err)
+	}
+	return &export, nil
+}
+
 // HandlerCount returns the number of registered handlers
 func HandlerCount() int {
 	registryLock.RLock()
@@ -0,0 +1,92 @@
+package taskkit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LoadExecutionResult reads a saved execution result, choosing the format
+// from the file extension (.yaml/.yml, otherwise JSON)
+func LoadExecutionResult(path string) (*ExecutionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution result: %w", err)
+	}
+	var result ExecutionResult
+	if err := resultEncoderFor(path).Decode(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse execution result: %w", err)
+	}
+	return &result, nil
+}
+
+// FirstFailedStep returns the index of the first failed step, or -1
+func FirstFailedStep(result ExecutionResult) int {
+	for i, step := range result.Steps {
+		if strings.EqualFold(step.Status, "Failed") {
+			return i
+		}
+	}
+	return -1
+}
+
+// RenderOptions controls RenderResult
+type RenderOptions struct {
+	// SinceFailure starts at the first failed step, omitting earlier steps
+	SinceFailure bool
+}
+
+// RenderResult prints a saved execution result in the runner's console
+// format
+func RenderResult(w io.Writer, result ExecutionResult, opts RenderOptions) {
+	steps := result.Steps
+	if opts.SinceFailure {
+		first := FirstFailedStep(result)
+		if first < 0 {
+			fmt.Fprintf(w, "No failed steps in workflow %s (%s)\n", result.WorkflowName, result.Result)
+			return
+		}
+		if first > 0 {
+			fmt.Fprintf(w, "(%d earlier steps omitted)\n", first)
+		}
+		steps = steps[first:]
+	}
+
+	for _, step := range steps {
+		fmt.Fprintf(w, "\n--- Step: %s (handler: %s) ---\n", step.Name, step.Handler)
+		for _, msg := range step.Messages {
+			fmt.Fprintf(w, "  %s\n", formatMessage(msg))
+		}
+		if step.Error != "" {
+			fmt.Fprintf(w, "  Error: %s\n", step.Error)
+		}
+		if step.SkipReason != "" {
+			fmt.Fprintf(w, "  Skipped: %s\n", step.SkipReason)
+		}
+		fmt.Fprintf(w, "  Status: %s (duration: %s)\n", step.Status, step.Duration)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
+	if result.ErrorMessage != "" {
+		fmt.Fprintf(w, "ERROR: %s\n", result.ErrorMessage)
+	}
+	if len(result.Stages) > 0 {
+		fmt.Fprintf(w, "\nStages:\n")
+		for _, s := range result.Stages {
+			fmt.Fprintf(w, "  %s: %s\n", s.Name, s.Status)
+		}
+	}
+	fmt.Fprintf(w, "\n=== Workflow %s: %s ===\n", result.WorkflowName, result.Result)
+}
+
+// formatMessage renders a message as console output, e.g.
+// "[ERROR] CHECK_FAILED: Smoke test checks failed"
+func formatMessage(m Message) string {
+	if m.Code != "" {
+		return fmt.Sprintf("[%s] %s: %s", m.Severity, m.Code, m.Text)
+	}
+	return fmt.Sprintf("[%s] %s", m.Severity, m.Text)
+}
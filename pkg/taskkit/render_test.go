@@ -0,0 +1,57 @@
+package taskkit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderResultSinceFailure(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "execution-result.json", `{
+		"result": "Failed",
+		"workflow_name": "deploy",
+		"steps": [
+			{"name": "build", "handler": "test-build", "status": "Succeeded", "duration": "1s"},
+			{"name": "push", "handler": "test-push", "status": "Succeeded", "duration": "2s"},
+			{"name": "deploy", "handler": "test-deploy", "status": "Failed", "duration": "3s", "error": "rollout timed out"},
+			{"name": "verify", "handler": "test-verify", "status": "Skipped", "duration": "0s", "skip_reason": "upstream failure"}
+		]
+	}`)
+	result, err := LoadExecutionResult(path)
+	if err != nil {
+		t.Fatalf("LoadExecutionResult: %v", err)
+	}
+
+	var out bytes.Buffer
+	RenderResult(&out, *result, RenderOptions{SinceFailure: true})
+	rendered := out.String()
+	if !strings.HasPrefix(rendered, "(2 earlier steps omitted)\n\n--- Step: deploy (handler: test-deploy) ---\n") {
+		t.Errorf("output does not start at the failed step:\n%s", rendered)
+	}
+	for _, want := range []string{"Error: rollout timed out", "--- Step: verify", "Skipped: upstream failure", "=== Workflow deploy: Failed ==="} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("output missing %q:\n%s", want, rendered)
+		}
+	}
+	if strings.Contains(rendered, "Step: build") || strings.Contains(rendered, "Step: push") {
+		t.Errorf("output includes steps before the failure:\n%s", rendered)
+	}
+
+	out.Reset()
+	RenderResult(&out, *result, RenderOptions{})
+	if !strings.Contains(out.String(), "Step: build") {
+		t.Errorf("full render omits earlier steps:\n%s", out.String())
+	}
+}
+
+func TestRenderResultSinceFailureWithoutFailures(t *testing.T) {
+	var out bytes.Buffer
+	RenderResult(&out, ExecutionResult{
+		Result:       "Succeeded",
+		WorkflowName: "deploy",
+		Steps:        []StepExec{{Name: "build", Status: "Succeeded"}},
+	}, RenderOptions{SinceFailure: true})
+	if got, want := out.String(), "No failed steps in workflow deploy (Succeeded)\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package taskkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+// RecordedInput is the on-disk form of a step's input, written to
+// inputs/<step>.json when input recording is enabled. Besides the
+// StepInput it holds what the handler received through Deps, so a replay
+// sees the same environment, handler config, and random source.
+type RecordedInput struct {
+	Handler string    `json:"handler"`
+	Input   StepInput `json:"input"`
+
+	// Seed seeds the step's Deps.Rand
+	Seed int64 `json:"seed"`
+
+	// Env is the step's Deps.Env
+	Env map[string]string `json:"env,omitempty"`
+
+	// HandlerConfig is the handler's Deps.HandlerConfig entry
+	HandlerConfig map[string]any `json:"handler_config,omitempty"`
+
+	// Redacted is set when sensitive values were replaced with "***"
+	// before recording, so a replay cannot reproduce them
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// ErrRedactedInput is returned when replaying a recorded input whose
+// sensitive values were redacted, unless redacted replays are allowed
+var ErrRedactedInput = errors.New("recorded input has redacted sensitive values")
+
+// recordedInputPath returns the path of a step's recorded input under dir
+func recordedInputPath(dir, stepName string) string {
+	return filepath.Join(dir, "inputs", stepName+".json")
+}
+
+// RecordInput writes a step's recorded input to inputs/<step>.json under dir
+func RecordInput(dir string, rec RecordedInput) error {
+	path := recordedInputPath(dir, rec.Input.StepName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create inputs dir: %w", err)
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal input: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write input: %w", err)
+	}
+	return nil
+}
+
+// LoadRecordedInput reads a step's recorded input from a previous run's workdir
+func LoadRecordedInput(dir, stepName string) (*RecordedInput, error) {
+	data, err := os.ReadFile(recordedInputPath(dir, stepName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded input: %w", err)
+	}
+	var rec RecordedInput
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded input: %w", err)
+	}
+	return &rec, nil
+}
+
+// ReplayStep loads a step's recorded input from a previous run's workdir
+// and replays it (see RecordedInput.Replay)
+func ReplayStep(dir, stepName string, deps Deps, allowRedacted bool) (StepResult, error) {
+	rec, err := LoadRecordedInput(dir, stepName)
+	if err != nil {
+		return StepResult{}, err
+	}
+	return rec.Replay(deps, allowRedacted)
+}
+
+// Replay invokes the recorded step's handler in isolation. Deps.Env,
+// Deps.HandlerConfig, and Deps.Rand always come from the recording; other
+// unset Deps fields get defaults. An input with redacted values fails with
+// ErrRedactedInput unless allowRedacted is set, in which case the handler
+// receives "***" in their place. Temp files the handler creates are
+// removed when it returns.
+func (rec *RecordedInput) Replay(deps Deps, allowRedacted bool) (StepResult, error) {
+	if rec.Redacted && !allowRedacted {
+		return StepResult{}, fmt.Errorf("cannot replay %s: %w", rec.Input.StepName, ErrRedactedInput)
+	}
+	handler, ok := Get(rec.Handler)
+	if !ok {
+		return StepResult{}, fmt.Errorf("handler not found: %s", rec.Handler)
+	}
+	if deps.Logger == nil {
+		deps.Logger = func(string, ...any) {}
+	}
+	if deps.Stdout == nil {
+		deps.Stdout = os.Stdout
+	}
+	if deps.Context == nil {
+		deps.Context = context.Background()
+	}
+	if deps.Stdin == nil {
+		deps.Stdin = os.Stdin
+	}
+	deps.Rand = rand.New(rand.NewSource(rec.Seed))
+	deps.Env = rec.Env
+	deps.handlerConfig = nil
+	if rec.HandlerConfig != nil {
+		deps.handlerConfig = map[string]any{rec.Handler: rec.HandlerConfig}
+	}
+	temps := &tempTracker{}
+	deps.temps = temps
+	defer temps.cleanup()
+
+	input := rec.Input
+	input.varReads = make(map[string]bool)
+	return handler(input, deps), nil
+}
@@ -0,0 +1,120 @@
+package taskkit
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const replayWorkflow = `
+name: replay
+platform: test
+sensitive: [token]
+env:
+  REGION: eu-west
+handler_config:
+  test-roll:
+    endpoint: https://example.test
+steps:
+  - name: prepare
+  - name: roll
+    params:
+      label: nightly
+`
+
+// rollHandler reports everything a replay must reproduce in its output
+func rollHandler(input StepInput, deps Deps) StepResult {
+	result := NewStepResult()
+	f, err := deps.TempFile("roll-*")
+	if err != nil {
+		result.AddError(err.Error(), "test")
+		return result
+	}
+	f.Close()
+	result.SetOutput("temp", f.Name())
+	result.SetOutput("roll", deps.Rand.Int63())
+	result.SetOutput("label", input.GetParamString("label"))
+	result.SetOutput("token", input.GetParamString("token"))
+	result.SetOutput("region", deps.Env["REGION"])
+	result.SetOutput("endpoint", deps.HandlerConfig("test-roll")["endpoint"])
+	result.SetOutput("seen", input.GetVar("seen"))
+	return result
+}
+
+func TestRecordAndReplayStep(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-prepare": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("seen", "yes")
+			return result
+		},
+		"test-roll": rollHandler,
+	})
+	workdir := t.TempDir()
+	result, _ := runTestWorkflow(t, replayWorkflow, LocalRunnerConfig{Workdir: workdir, RecordInputs: true})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	recorded := stepByName(t, result, "roll").Output
+
+	replayed, err := ReplayStep(workdir, "roll", Deps{Workdir: t.TempDir()}, false)
+	if err != nil {
+		t.Fatalf("ReplayStep: %v", err)
+	}
+	if replayed.HasErrors() {
+		t.Fatalf("replay failed: %v", replayed.Messages)
+	}
+	for _, key := range []string{"roll", "label", "region", "endpoint", "seen"} {
+		if !reflect.DeepEqual(replayed.Output[key], recorded[key]) {
+			t.Errorf("replayed %s = %v, want %v as recorded", key, replayed.Output[key], recorded[key])
+		}
+	}
+	if _, err := os.Stat(replayed.Output["temp"].(string)); !os.IsNotExist(err) {
+		t.Errorf("replay temp file was not removed: %v", err)
+	}
+
+	// Replays are deterministic
+	again, err := ReplayStep(workdir, "roll", Deps{Workdir: t.TempDir()}, false)
+	if err != nil {
+		t.Fatalf("ReplayStep: %v", err)
+	}
+	if again.Output["roll"] != replayed.Output["roll"] {
+		t.Errorf("second replay rolled %v, first %v", again.Output["roll"], replayed.Output["roll"])
+	}
+}
+
+func TestReplayRedactedInput(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-prepare": succeed,
+		"test-roll":    rollHandler,
+	})
+	workdir := t.TempDir()
+	result, _ := runTestWorkflow(t, replayWorkflow, LocalRunnerConfig{
+		Workdir:      workdir,
+		RecordInputs: true,
+		ParamsPath:   writeFile(t, t.TempDir(), "params.json", `{"token": "s3cret"}`),
+	})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	rec, err := LoadRecordedInput(workdir, "roll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Redacted || rec.Input.Params["token"] != redactedValue {
+		t.Fatalf("recorded token = %v (redacted %v), want it redacted", rec.Input.Params["token"], rec.Redacted)
+	}
+
+	if _, err := ReplayStep(workdir, "roll", Deps{Workdir: t.TempDir()}, false); !errors.Is(err, ErrRedactedInput) {
+		t.Fatalf("ReplayStep error = %v, want ErrRedactedInput", err)
+	}
+	replayed, err := ReplayStep(workdir, "roll", Deps{Workdir: t.TempDir()}, true)
+	if err != nil {
+		t.Fatalf("ReplayStep with allowRedacted: %v", err)
+	}
+	if got := replayed.Output["token"]; got != redactedValue {
+		t.Errorf("replayed token = %v, want %s", got, redactedValue)
+	}
+}
@@ -0,0 +1,55 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const requiredEnvWorkflow = `
+name: required-env
+platform: test
+required_env: [TASKKIT_TEST_KUBECONFIG, TASKKIT_TEST_TOKEN, TASKKIT_TEST_REGION]
+steps:
+  - name: deploy
+`
+
+func TestRequiredEnvAllPresent(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": succeed})
+	t.Setenv("TASKKIT_TEST_KUBECONFIG", "/tmp/kubeconfig")
+	t.Setenv("TASKKIT_TEST_TOKEN", "token")
+	t.Setenv("TASKKIT_TEST_REGION", "us-east")
+
+	result, _ := runTestWorkflow(t, requiredEnvWorkflow, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Errorf("result = %s, want Succeeded", result.Result)
+	}
+}
+
+func TestRequiredEnvSomeMissing(t *testing.T) {
+	called := false
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(StepInput, Deps) StepResult {
+			called = true
+			return NewStepResult()
+		},
+	})
+	t.Setenv("TASKKIT_TEST_KUBECONFIG", "/tmp/kubeconfig")
+	t.Setenv("TASKKIT_TEST_TOKEN", "")
+	t.Setenv("TASKKIT_TEST_REGION", "")
+
+	dir := t.TempDir()
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath: writeFile(t, dir, "workflow.yaml", requiredEnvWorkflow),
+		Workdir:      dir,
+	})
+	if err == nil {
+		t.Fatal("NewLocalRunner succeeded, want missing env error")
+	}
+	// Empty counts as missing, and the list keeps declaration order
+	if want := "missing required environment variables: TASKKIT_TEST_TOKEN, TASKKIT_TEST_REGION"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if called {
+		t.Error("a step ran despite missing env")
+	}
+}
@@ -0,0 +1,77 @@
+package taskkit
+
+// ResultBuilder builds a StepResult with chained calls:
+//
+//	return taskkit.BuildResult().
+//		Info("Checks passed", "smoke-test").
+//		SetVar("checked", true).
+//		Result()
+//
+// Each method mirrors the StepResult method of the same purpose.
+type ResultBuilder struct {
+	result StepResult
+}
+
+// BuildResult starts a new, empty result
+func BuildResult() *ResultBuilder {
+	return &ResultBuilder{result: NewStepResult()}
+}
+
+// Message adds a message with the given severity
+func (b *ResultBuilder) Message(severity Severity, text, system string) *ResultBuilder {
+	b.result.AddMessage(severity, text, system)
+	return b
+}
+
+// Info adds an info message
+func (b *ResultBuilder) Info(text, system string) *ResultBuilder {
+	b.result.AddInfo(text, system)
+	return b
+}
+
+// Warning adds a warning message
+func (b *ResultBuilder) Warning(text, system string) *ResultBuilder {
+	b.result.AddWarning(text, system)
+	return b
+}
+
+// Error adds an error message
+func (b *ResultBuilder) Error(text, system string) *ResultBuilder {
+	b.result.AddError(text, system)
+	return b
+}
+
+// Debug adds a debug message
+func (b *ResultBuilder) Debug(text, system string) *ResultBuilder {
+	b.result.AddDebug(text, system)
+	return b
+}
+
+// SetVar sets a workflow variable
+func (b *ResultBuilder) SetVar(key string, value any) *ResultBuilder {
+	b.result.SetVar(key, value)
+	return b
+}
+
+// SetOutput sets an output value
+func (b *ResultBuilder) SetOutput(key string, value any) *ResultBuilder {
+	b.result.SetOutput(key, value)
+	return b
+}
+
+// Skip marks the step as skipped with a reason
+func (b *ResultBuilder) Skip(reason string) *ResultBuilder {
+	b.result.Skip(reason)
+	return b
+}
+
+// ExitWith requests a workflow exit with the given code
+func (b *ResultBuilder) ExitWith(code int, reason string) *ResultBuilder {
+	b.result.ExitWith(code, reason)
+	return b
+}
+
+// Result returns the built result
+func (b *ResultBuilder) Result() StepResult {
+	return b.result
+}
@@ -0,0 +1,41 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultBuilderMatchesImperativeForm(t *testing.T) {
+	want := NewStepResult()
+	want.AddInfo("checks passed", "smoke-test")
+	want.AddWarning("slow response", "smoke-test")
+	want.AddError("disk nearly full", "smoke-test")
+	want.AddDebug("took 3 probes", "smoke-test")
+	want.AddMessage(SeverityInfo, "done", "taskkit")
+	want.SetVar("checked", true)
+	want.SetOutput("latency_ms", 42)
+	want.Skip("nothing to do")
+	want.ExitWith(3, "maintenance window")
+
+	got := BuildResult().
+		Info("checks passed", "smoke-test").
+		Warning("slow response", "smoke-test").
+		Error("disk nearly full", "smoke-test").
+		Debug("took 3 probes", "smoke-test").
+		Message(SeverityInfo, "done", "taskkit").
+		SetVar("checked", true).
+		SetOutput("latency_ms", 42).
+		Skip("nothing to do").
+		ExitWith(3, "maintenance window").
+		Result()
+
+	// Messages are timestamped as they are added, so compare without them
+	for _, r := range []*StepResult{&want, &got} {
+		for i := range r.Messages {
+			r.Messages[i].Timestamp = want.Messages[i].Timestamp
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("built result = %+v\nwant %+v", got, want)
+	}
+}
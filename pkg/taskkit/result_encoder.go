@@ -0,0 +1,101 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Result formats for --result-format
+const (
+	ResultFormatJSON = "json"
+	ResultFormatYAML = "yaml"
+)
+
+// ResultFileBase is the execution result file name without its extension
+const ResultFileBase = "execution-result"
+
+// ResultEncoder serializes the execution result written to the workdir
+type ResultEncoder interface {
+	// Encode serializes v, an ExecutionResult or its generic JSON form
+	Encode(v any) ([]byte, error)
+	// Decode parses data produced by Encode into v
+	Decode(data []byte, v any) error
+	// Extension is the result file extension, including the dot
+	Extension() string
+}
+
+// NewResultEncoder returns the encoder for a result format; empty means JSON
+func NewResultEncoder(format string) (ResultEncoder, error) {
+	switch strings.ToLower(format) {
+	case "", ResultFormatJSON:
+		return JSONResultEncoder{}, nil
+	case ResultFormatYAML, "yml":
+		return YAMLResultEncoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown result format %q (want %s or %s)", format, ResultFormatJSON, ResultFormatYAML)
+}
+
+// resultEncoderFor picks an encoder from a result file's extension
+func resultEncoderFor(path string) ResultEncoder {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAMLResultEncoder{}
+	}
+	return JSONResultEncoder{}
+}
+
+// JSONResultEncoder writes indented JSON (the default)
+type JSONResultEncoder struct{}
+
+func (JSONResultEncoder) Encode(v any) ([]byte, error) {
+	return json.MarshalIndent(v, "", "  ")
+}
+
+func (JSONResultEncoder) Decode(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JSONResultEncoder) Extension() string { return ".json" }
+
+// YAMLResultEncoder writes YAML with the same keys as the JSON form
+type YAMLResultEncoder struct{}
+
+func (YAMLResultEncoder) Encode(v any) ([]byte, error) {
+	// Go through generic JSON so the json struct tags name the keys
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+func (YAMLResultEncoder) Decode(data []byte, v any) error {
+	var generic any
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	jsonData, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+func (YAMLResultEncoder) Extension() string { return ".yaml" }
+
+// toGeneric converts v to maps, slices, and scalars via a JSON round trip
+func toGeneric(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
@@ -0,0 +1,80 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleResult() ExecutionResult {
+	start := time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+	code := 3
+	return ExecutionResult{
+		Result:       "Failed",
+		WorkflowName: "nightly",
+		StartTime:    start,
+		EndTime:      start.Add(90 * time.Second),
+		ExitCode:     &code,
+		Warnings:     []string{"slow step"},
+		FinalVars:    map[string]any{"image": "app:1.2", "count": float64(2)},
+		Steps: []StepExec{{
+			Name:     "deploy",
+			Handler:  "test-deploy",
+			Status:   "Failed",
+			Duration: "1m30s",
+			Messages: []Message{{Severity: SeverityError, Code: "ROLLOUT", Text: "timed out", Timestamp: start}},
+			Output:   map[string]any{"replicas": float64(3), "hosts": []any{"a", "b"}},
+			Attempts: []AttemptRecord{{Attempt: 1, Status: "Failed", Duration: "1m30s", Error: "timed out"}},
+		}},
+	}
+}
+
+func TestResultEncodersRoundTrip(t *testing.T) {
+	for _, format := range []string{ResultFormatJSON, ResultFormatYAML} {
+		t.Run(format, func(t *testing.T) {
+			enc, err := NewResultEncoder(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := sampleResult()
+			data, err := enc.Encode(want)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			var got ExecutionResult
+			if err := enc.Decode(data, &got); err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v\nwant %+v", got, want)
+			}
+
+			// Saved files are named after the format and load back by extension
+			path := filepath.Join(t.TempDir(), ResultFileBase+enc.Extension())
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadExecutionResult(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*loaded, want) {
+				t.Errorf("loaded %s = %+v\nwant %+v", filepath.Base(path), *loaded, want)
+			}
+		})
+	}
+}
+
+func TestNewResultEncoder(t *testing.T) {
+	for format, ext := range map[string]string{"": ".json", "JSON": ".json", "yaml": ".yaml", "yml": ".yaml"} {
+		enc, err := NewResultEncoder(format)
+		if err != nil || enc.Extension() != ext {
+			t.Errorf("NewResultEncoder(%q) = %v, %v, want extension %s", format, enc, err, ext)
+		}
+	}
+	if _, err := NewResultEncoder("msgpack"); err == nil {
+		t.Error("NewResultEncoder accepted an unknown format")
+	}
+}
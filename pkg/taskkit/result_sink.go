@@ -0,0 +1,98 @@
+package taskkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ResultSink POSTs each run's execution result as JSON to an HTTP endpoint
+type ResultSink struct {
+	// URL receives the POST
+	URL string
+
+	// Token, if set, is sent as "Authorization: Bearer <token>"
+	Token string
+
+	// Headers are added to the request
+	Headers map[string]string
+
+	// Retries is the number of extra attempts after a transient failure
+	// (network error, 429, or 5xx). Zero means the default of 3.
+	Retries int
+
+	// Timeout bounds each attempt (default 10s)
+	Timeout time.Duration
+
+	// Client overrides http.DefaultClient
+	Client *http.Client
+}
+
+// sinkRetryBase is the delay before the first retry; it doubles each time
+const sinkRetryBase = 500 * time.Millisecond
+
+// Send posts data, retrying transient failures and waiting between attempts
+// with sleep
+func (s *ResultSink) Send(data []byte, sleep func(time.Duration)) error {
+	retries := s.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	delay := sinkRetryBase
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			sleep(delay)
+			delay *= 2
+		}
+		var transient bool
+		transient, err = s.post(data)
+		if err == nil || !transient {
+			return err
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", retries+1, err)
+}
+
+// post makes one attempt and reports whether a failure is worth retrying
+func (s *ResultSink) post(data []byte) (bool, error) {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("failed to build result request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to post result: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	transient := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return transient, fmt.Errorf("result endpoint returned %s", resp.Status)
+}
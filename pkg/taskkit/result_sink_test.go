@@ -0,0 +1,132 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sinkServer records posted bodies, failing the first failures requests
+// with 503
+type sinkServer struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	auth     []string
+}
+
+func (s *sinkServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies = append(s.bodies, body)
+	s.auth = append(s.auth, req.Header.Get("Authorization"))
+	if len(s.bodies) <= s.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+const sinkWorkflow = `
+name: sink
+platform: test
+sensitive: [token]
+steps:
+  - name: build
+`
+
+func TestResultSinkPostsResult(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.SetOutput("image", "app:1.2")
+			result.SetOutput("token", "s3cret")
+			return result
+		},
+	})
+	sink := &sinkServer{failures: 1}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r, out := newTestRunner(t, sinkWorkflow, LocalRunnerConfig{
+		TaskID:     "task-7",
+		ResultSink: &ResultSink{URL: server.URL, Token: "api-key"},
+	})
+	var slept []time.Duration
+	r.sleep = func(d time.Duration) { slept = append(slept, d) }
+	result := r.Run()
+
+	if len(sink.bodies) != 2 {
+		t.Fatalf("posts = %d, want a retry after the 503", len(sink.bodies))
+	}
+	if len(slept) != 1 {
+		t.Errorf("slept %v, want one backoff before the retry", slept)
+	}
+	if sink.auth[1] != "Bearer api-key" {
+		t.Errorf("Authorization = %q", sink.auth[1])
+	}
+
+	var posted ExecutionResult
+	if err := json.Unmarshal(sink.bodies[1], &posted); err != nil {
+		t.Fatalf("posted body is not a result: %v", err)
+	}
+	if posted.TaskID != result.TaskID || posted.WorkflowName != result.WorkflowName || posted.Result != result.Result {
+		t.Errorf("posted %s/%s/%s, want %s/%s/%s", posted.TaskID, posted.WorkflowName, posted.Result,
+			result.TaskID, result.WorkflowName, result.Result)
+	}
+	if len(posted.Steps) != 1 || posted.Steps[0].Output["image"] != "app:1.2" {
+		t.Errorf("posted steps = %+v", posted.Steps)
+	}
+	if posted.Steps[0].Output["token"] != redactedValue {
+		t.Errorf("posted token = %v, want it redacted", posted.Steps[0].Output["token"])
+	}
+	if !strings.Contains(out.String(), "Result sent to "+server.URL) {
+		t.Errorf("delivery not logged:\n%s", out.String())
+	}
+}
+
+func TestResultSinkFailureDoesNotFailRun(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-build": succeed})
+	sink := &sinkServer{failures: 100}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	r, out := newTestRunner(t, sinkWorkflow, LocalRunnerConfig{
+		ResultSink: &ResultSink{URL: server.URL, Retries: 2},
+	})
+	r.sleep = func(time.Duration) {}
+	result := r.Run()
+
+	if result.Result != "Succeeded" {
+		t.Errorf("result = %s, want Succeeded despite the sink failing", result.Result)
+	}
+	if len(sink.bodies) != 3 {
+		t.Errorf("posts = %d, want 3 (1 + 2 retries)", len(sink.bodies))
+	}
+	if !strings.Contains(out.String(), "Warning: failed to send result") {
+		t.Errorf("failure not logged:\n%s", out.String())
+	}
+}
+
+func TestResultSinkDoesNotRetryClientErrors(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		posts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink := &ResultSink{URL: server.URL}
+	if err := sink.Send([]byte(`{}`), func(time.Duration) {}); err == nil {
+		t.Error("Send succeeded against a 400")
+	}
+	if posts != 1 {
+		t.Errorf("posts = %d, want no retries for a 400", posts)
+	}
+}
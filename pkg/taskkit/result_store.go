@@ -0,0 +1,8 @@
+package taskkit
+
+// ResultStore records each run's execution result for historical queries,
+// e.g. in a database (see the sqlitestore package). The result it receives
+// is redacted like execution-result.json.
+type ResultStore interface {
+	SaveResult(result ExecutionResult) error
+}
@@ -0,0 +1,63 @@
+package taskkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// recordingStore is a ResultStore that keeps what it is given
+type recordingStore struct {
+	results []ExecutionResult
+	err     error
+}
+
+func (s *recordingStore) SaveResult(result ExecutionResult) error {
+	s.results = append(s.results, result)
+	return s.err
+}
+
+const resultStoreWorkflow = `
+name: store
+platform: test
+sensitive: [password]
+steps:
+  - name: login
+    params:
+      password: hunter2
+`
+
+func TestResultStoreReceivesRedactedResult(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-login": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.AddError("login failed for "+input.GetParamString("password"), "test")
+			return result
+		},
+	})
+	store := &recordingStore{}
+	result, _ := runTestWorkflow(t, resultStoreWorkflow, LocalRunnerConfig{ResultStore: store})
+	if len(store.results) != 1 {
+		t.Fatalf("store got %d results, want 1", len(store.results))
+	}
+	got := store.results[0]
+	if got.WorkflowName != "store" || got.Result != result.Result || len(got.Steps) != 1 {
+		t.Errorf("stored result = %s/%s with %d steps, want store/%s with 1", got.WorkflowName, got.Result, len(got.Steps), result.Result)
+	}
+	messages := got.Steps[0].Messages
+	if len(messages) != 1 || messages[0].Text != "login failed for ***" {
+		t.Errorf("stored step messages = %+v, want the password redacted", messages)
+	}
+}
+
+func TestResultStoreFailureDoesNotFailRun(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-login": succeed})
+	store := &recordingStore{err: errors.New("disk full")}
+	result, out := runTestWorkflow(t, resultStoreWorkflow, LocalRunnerConfig{ResultStore: store})
+	if result.Result != "Succeeded" {
+		t.Errorf("result = %s, want Succeeded", result.Result)
+	}
+	if !strings.Contains(out, "Warning: failed to store result: disk full") {
+		t.Errorf("missing store warning:\n%s", out)
+	}
+}
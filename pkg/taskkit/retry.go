@@ -0,0 +1,71 @@
+package taskkit
+
+import "fmt"
+
+// retryPlan works out which steps a retry can reuse from a prior result.
+// Steps that failed, never ran because the prior run stopped early, were
+// skipped for an upstream failure, or were skipped by if_step_status are
+// re-run, along with every step that depends on them through depends,
+// soft_depends, or if_step_status, transitively; the rest are reused.
+// Finalize steps always re-run.
+func retryPlan(w *WorkflowDefinition, prior *ExecutionResult) (map[string]StepExec, error) {
+	steps, err := w.GetExecutionOrder()
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]StepExec, len(prior.Steps))
+	for _, step := range prior.Steps {
+		recorded[step.Name] = step
+	}
+
+	rerun := make(map[string]bool)
+	reuse := make(map[string]StepExec)
+	for _, step := range steps {
+		if step.Template == TemplateFinalize {
+			continue
+		}
+		prev, ok := recorded[step.Name]
+		again := !ok || prev.Status == "Failed" ||
+			prev.Status == "Skipped" && (prev.SkipReason == skipUpstreamFailure || len(step.IfStepStatus) > 0)
+		for _, dep := range retryDeps(step) {
+			if rerun[dep] {
+				again = true
+				break
+			}
+		}
+		if again {
+			rerun[step.Name] = true
+			continue
+		}
+		reuse[step.Name] = prev
+	}
+	return reuse, nil
+}
+
+// retryDeps returns every step whose re-run can change a step's outcome
+func retryDeps(step WorkflowStep) []string {
+	deps := append(append([]string{}, step.Depends...), step.SoftDepends...)
+	for name := range step.IfStepStatus {
+		deps = append(deps, name)
+	}
+	return deps
+}
+
+// reuseStep records a step carried over from the prior run: steps that
+// completed are Cached with their prior output, and skipped steps stay
+// Skipped
+func (r *LocalRunner) reuseStep(step WorkflowStep, prior StepExec) StepExec {
+	if prior.Status == "Skipped" {
+		return r.skipStep(step, fmt.Sprintf("skipped in prior run: %s", prior.SkipReason))
+	}
+	r.stepHeader("\n--- Step: %s (reused from prior run: %s) ---\n", step.Name, prior.Status)
+	r.printRationale()
+	return StepExec{
+		Name:     step.Name,
+		Handler:  r.workflow.GetHandlerName(step),
+		Status:   "Cached",
+		Duration: "0s",
+		Messages: prior.Messages,
+		Output:   prior.Output,
+	}
+}
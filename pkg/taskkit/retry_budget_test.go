@@ -0,0 +1,43 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryBudgetStopsRetries(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-slow": func(StepInput, Deps) StepResult {
+			calls++
+			time.Sleep(600 * time.Millisecond)
+			result := NewStepResult()
+			result.AddError("still unavailable", "test")
+			return result
+		},
+	})
+
+	result, out := runTestWorkflow(t, `
+name: budget
+platform: test
+steps:
+  - name: slow
+    retries: 10
+    retry_budget_seconds: 1
+    retry_backoff:
+      base_seconds: 0.01
+      jitter: none
+`, LocalRunnerConfig{})
+	slow := stepByName(t, result, "slow")
+	if slow.Status != "Failed" {
+		t.Fatalf("slow status = %s, want Failed", slow.Status)
+	}
+	// The second attempt ends past the 1s budget, so no third starts
+	if calls != 2 || len(slow.Attempts) != 2 {
+		t.Errorf("slow ran %d times with %d attempts recorded, want 2 of 11", calls, len(slow.Attempts))
+	}
+	if !strings.Contains(out, "Retry budget of 1s exhausted after 2 attempts") {
+		t.Errorf("budget exhaustion not reported:\n%s", out)
+	}
+}
@@ -0,0 +1,44 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRetryParamsApplyFromSecondAttempt(t *testing.T) {
+	var seen []map[string]any
+	registerHandlers(t, map[string]StepHandler{
+		"test-fetch": func(input StepInput, deps Deps) StepResult {
+			seen = append(seen, input.Params)
+			result := NewStepResult()
+			if input.Attempt < 3 {
+				result.AddError("timed out", "test")
+			}
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: retry-params
+platform: test
+steps:
+  - name: fetch
+    retries: 2
+    params:
+      url: http://nas.local
+      timeout: 5
+    retry_params:
+      timeout: 30
+`, LocalRunnerConfig{})
+	if got := stepByName(t, result, "fetch").Status; got != "Succeeded" {
+		t.Fatalf("fetch status = %s, want Succeeded", got)
+	}
+	want := []map[string]any{
+		{"url": "http://nas.local", "timeout": 5},
+		{"url": "http://nas.local", "timeout": 30},
+		{"url": "http://nas.local", "timeout": 30},
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("params per attempt = %v, want %v", seen, want)
+	}
+}
@@ -0,0 +1,48 @@
+package taskkit
+
+import "testing"
+
+func TestRetryStatsAggregateAttempts(t *testing.T) {
+	calls := 0
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": succeed,
+		"test-flaky": func(StepInput, Deps) StepResult {
+			calls++
+			result := NewStepResult()
+			if calls < 3 {
+				result.AddError("connection reset", "test")
+			}
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: retry-stats
+platform: test
+steps:
+  - name: build
+  - name: flaky
+    retries: 3
+    retry_backoff:
+      base_seconds: 0.01
+      jitter: none
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	// Two retries of flaky, backing off 10ms then 20ms
+	want := RetryStats{TotalRetries: 2, StepsRetried: 1, BackoffTotal: "30ms", BackoffTotalMs: 30}
+	if result.Retries != want {
+		t.Errorf("retries = %+v, want %+v", result.Retries, want)
+	}
+}
+
+func TestNewRetryStatsWithoutRetries(t *testing.T) {
+	stats := NewRetryStats([]StepExec{
+		{Name: "build", Attempts: []AttemptRecord{{Attempt: 1, Status: "Succeeded"}}},
+		{Name: "skipped"},
+	})
+	if want := (RetryStats{BackoffTotal: "0s"}); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
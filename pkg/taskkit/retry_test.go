@@ -0,0 +1,119 @@
+package taskkit
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const retryWorkflow = `
+name: retry
+failure_policy: skip-to-finalize
+platform: test
+steps:
+  - name: setup
+    template: init
+  - name: lint
+  - name: build
+  - name: unit
+    depends: [build]
+  - name: rollback
+    if_step_status: {build: Failed}
+  - name: docs
+    soft_depends: [unit]
+  - name: announce
+    if_step_status: {build: Succeeded}
+  - name: report
+    template: finalize
+`
+
+// retryHarness registers retryWorkflow's handlers once; run executes the
+// workflow and returns the result and the steps whose handlers were called
+type retryHarness struct {
+	t          *testing.T
+	buildFails bool
+	called     []string
+}
+
+func newRetryHarness(t *testing.T) *retryHarness {
+	h := &retryHarness{t: t}
+	handlers := map[string]StepHandler{}
+	for _, name := range []string{"setup", "lint", "build", "unit", "rollback", "docs", "announce", "report"} {
+		name := name
+		handlers["test-"+name] = func(StepInput, Deps) StepResult {
+			h.called = append(h.called, name)
+			result := NewStepResult()
+			if name == "build" && h.buildFails {
+				result.AddError("compile error", "test")
+			}
+			if name == "setup" {
+				result.SetVar("region", "us-east")
+			}
+			return result
+		}
+	}
+	registerHandlers(t, handlers)
+	return h
+}
+
+func (h *retryHarness) run(buildFails bool, config LocalRunnerConfig) (ExecutionResult, []string) {
+	h.t.Helper()
+	h.buildFails, h.called = buildFails, nil
+	result, _ := runTestWorkflow(h.t, retryWorkflow, config)
+	sort.Strings(h.called)
+	return result, h.called
+}
+
+func TestRetryRerunsOnlyFailedSubset(t *testing.T) {
+	h := newRetryHarness(t)
+	workdir := t.TempDir()
+	first, _ := h.run(true, LocalRunnerConfig{Workdir: workdir})
+	if first.Result != "Failed" {
+		t.Fatalf("first run = %s, want Failed", first.Result)
+	}
+	want := map[string]string{
+		"setup": "Succeeded", "lint": "Succeeded", "build": "Failed", "unit": "Skipped",
+		"rollback": "Succeeded", "docs": "Skipped", "announce": "Skipped", "report": "Succeeded",
+	}
+	if got := stepStatuses(first); !reflect.DeepEqual(got, want) {
+		t.Fatalf("first run statuses = %v, want %v", got, want)
+	}
+
+	retry, called := h.run(false, LocalRunnerConfig{
+		RetryFromPath: filepath.Join(workdir, "execution-result.json"),
+	})
+	if retry.Result != "Succeeded" {
+		t.Fatalf("retry = %s, want Succeeded", retry.Result)
+	}
+	// build failed, unit and docs were skipped for it, and finalize steps
+	// always run. rollback and announce are re-evaluated against build's new
+	// status, so only announce runs.
+	wantCalled := []string{"announce", "build", "docs", "report", "unit"}
+	if !reflect.DeepEqual(called, wantCalled) {
+		t.Errorf("retry ran %v, want %v", called, wantCalled)
+	}
+	want = map[string]string{
+		"setup": "Cached", "lint": "Cached", "build": "Succeeded", "unit": "Succeeded",
+		"rollback": "Skipped", "docs": "Succeeded", "announce": "Succeeded", "report": "Succeeded",
+	}
+	if got := stepStatuses(retry); !reflect.DeepEqual(got, want) {
+		t.Errorf("retry statuses = %v, want %v", got, want)
+	}
+	if retry.FinalVars["region"] != "us-east" {
+		t.Errorf("region = %v, want it seeded from the prior run", retry.FinalVars["region"])
+	}
+}
+
+func TestRetryAfterSuccessReusesEverything(t *testing.T) {
+	h := newRetryHarness(t)
+	workdir := t.TempDir()
+	h.run(false, LocalRunnerConfig{Workdir: workdir})
+	_, called := h.run(false, LocalRunnerConfig{
+		RetryFromPath: filepath.Join(workdir, "execution-result.json"),
+	})
+	// rollback was skipped by if_step_status and is re-evaluated
+	if want := []string{"report"}; !reflect.DeepEqual(called, want) {
+		t.Errorf("retry ran %v, want %v", called, want)
+	}
+}
@@ -0,0 +1,50 @@
+package taskkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeoutGrowsPerAttempt(t *testing.T) {
+	budgets := map[int]time.Duration{}
+	registerHandlers(t, map[string]StepHandler{
+		"test-slow": func(input StepInput, deps Deps) StepResult {
+			deadline, ok := deps.Context.Deadline()
+			if !ok {
+				t.Errorf("attempt %d has no deadline", input.Attempt)
+			}
+			budgets[input.Attempt] = time.Until(deadline)
+			result := NewStepResult()
+			if input.Attempt < 3 {
+				result.AddError("still slow", "test")
+			}
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: timeout-growth
+platform: test
+steps:
+  - name: slow
+    retries: 2
+    timeout_seconds: 10
+    timeout_growth: 1
+    max_timeout_seconds: 60
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	if len(budgets) != 3 {
+		t.Fatalf("attempts = %v, want 3", budgets)
+	}
+	// attempt n gets base*n, less the little time spent reaching the handler
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Second, 3: 30 * time.Second} {
+		if got := budgets[attempt]; got > want || got < want-time.Second {
+			t.Errorf("attempt %d deadline in %s, want about %s", attempt, got, want)
+		}
+	}
+	if budgets[3] <= budgets[1] {
+		t.Errorf("attempt 3 deadline %s not later than attempt 1 %s", budgets[3], budgets[1])
+	}
+}
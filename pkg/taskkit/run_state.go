@@ -0,0 +1,101 @@
+package taskkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunStateFile is the live progress file written when WriteState is set
+const RunStateFile = "run-state.json"
+
+// RunState is the live progress of a run, rewritten as each step starts and
+// finishes so another process can poll it
+type RunState struct {
+	Workflow    string      `json:"workflow"`
+	TaskID      string      `json:"task_id,omitempty"`
+	PID         int         `json:"pid"`
+	Status      string      `json:"status"` // Running, then the final result
+	StartedAt   time.Time   `json:"started_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	CurrentStep string      `json:"current_step,omitempty"`
+	Completed   int         `json:"completed"`
+	Total       int         `json:"total"`
+	Steps       []StepState `json:"steps"`
+}
+
+// StepState is the recorded status of a finished step
+type StepState struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+}
+
+// LoadRunState reads the run state file from a workdir
+func LoadRunState(workdir string) (*RunState, error) {
+	data, err := os.ReadFile(filepath.Join(workdir, RunStateFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run state: %w", err)
+	}
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse run state: %w", err)
+	}
+	return &state, nil
+}
+
+// stateBegin records that a step has started
+func (r *LocalRunner) stateBegin(stepName string) {
+	if r.state == nil {
+		return
+	}
+	r.state.CurrentStep = stepName
+	r.writeState()
+}
+
+// stateEnd records a finished step
+func (r *LocalRunner) stateEnd(exec StepExec) {
+	if r.state == nil {
+		return
+	}
+	r.state.CurrentStep = ""
+	r.state.Completed++
+	r.state.Steps = append(r.state.Steps, StepState{
+		Name:     exec.Name,
+		Status:   exec.Status,
+		Duration: exec.Duration,
+	})
+	r.writeState()
+}
+
+// stateFinish records the final result
+func (r *LocalRunner) stateFinish(result string) {
+	if r.state == nil {
+		return
+	}
+	r.state.CurrentStep = ""
+	r.state.Status = result
+	r.writeState()
+}
+
+// writeState replaces the state file atomically so readers never see a
+// partial write
+func (r *LocalRunner) writeState() {
+	r.state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		r.printf("Warning: failed to marshal run state: %v\n", err)
+		return
+	}
+	path := filepath.Join(r.config.Workdir, RunStateFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		r.printf("Warning: failed to write run state: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		r.printf("Warning: failed to write run state: %v\n", err)
+	}
+}
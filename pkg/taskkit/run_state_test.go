@@ -0,0 +1,54 @@
+package taskkit
+
+import "testing"
+
+func TestRunStateTracksProgress(t *testing.T) {
+	workdir := t.TempDir()
+	var midRun *RunState
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": succeed,
+		"test-deploy": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			state, err := LoadRunState(workdir)
+			if err != nil {
+				result.AddError(err.Error(), "test")
+				return result
+			}
+			midRun = state
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: state
+platform: test
+steps:
+  - name: build
+  - name: deploy
+`, LocalRunnerConfig{Workdir: workdir, WriteState: true})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+
+	// While deploy runs, the state shows it in progress after build
+	if midRun.Status != "Running" || midRun.CurrentStep != "deploy" || midRun.Completed != 1 || midRun.Total != 2 {
+		t.Errorf("mid-run state = %+v, want Running deploy with 1/2 completed", midRun)
+	}
+	if len(midRun.Steps) != 1 || midRun.Steps[0] != (StepState{Name: "build", Status: "Succeeded", Duration: midRun.Steps[0].Duration}) {
+		t.Errorf("mid-run steps = %+v, want build Succeeded", midRun.Steps)
+	}
+
+	final, err := LoadRunState(workdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Status != "Succeeded" || final.CurrentStep != "" || final.Completed != 2 {
+		t.Errorf("final state = %+v, want Succeeded with 2 completed", final)
+	}
+}
+
+func TestLoadRunStateMissing(t *testing.T) {
+	if _, err := LoadRunState(t.TempDir()); err == nil {
+		t.Error("LoadRunState succeeded without a state file")
+	}
+}
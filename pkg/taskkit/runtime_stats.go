@@ -0,0 +1,65 @@
+package taskkit
+
+import "runtime"
+
+// RuntimeSnapshot is a point-in-time sample of Go runtime statistics
+type RuntimeSnapshot struct {
+	Goroutines  int    `json:"goroutines"`
+	HeapAlloc   uint64 `json:"heap_alloc_bytes"`
+	HeapObjects uint64 `json:"heap_objects"`
+	TotalAlloc  uint64 `json:"total_alloc_bytes"`
+	Sys         uint64 `json:"sys_bytes"`
+	Mallocs     uint64 `json:"mallocs"`
+	NumGC       uint32 `json:"num_gc"`
+}
+
+// RuntimeDelta is the change between two snapshots. Goroutine and heap
+// figures can shrink; the cumulative counters never do.
+type RuntimeDelta struct {
+	Goroutines  int    `json:"goroutines"`
+	HeapAlloc   int64  `json:"heap_alloc_bytes"`
+	HeapObjects int64  `json:"heap_objects"`
+	TotalAlloc  uint64 `json:"total_alloc_bytes"`
+	Sys         int64  `json:"sys_bytes"`
+	Mallocs     uint64 `json:"mallocs"`
+	NumGC       uint32 `json:"num_gc"`
+}
+
+// RuntimeStats holds runtime snapshots taken before and after a run
+type RuntimeStats struct {
+	Before RuntimeSnapshot `json:"before"`
+	After  RuntimeSnapshot `json:"after"`
+	Delta  RuntimeDelta    `json:"delta"`
+}
+
+// CaptureRuntime samples the current runtime statistics
+func CaptureRuntime() RuntimeSnapshot {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return RuntimeSnapshot{
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   m.HeapAlloc,
+		HeapObjects: m.HeapObjects,
+		TotalAlloc:  m.TotalAlloc,
+		Sys:         m.Sys,
+		Mallocs:     m.Mallocs,
+		NumGC:       m.NumGC,
+	}
+}
+
+// NewRuntimeStats computes the delta between two snapshots
+func NewRuntimeStats(before, after RuntimeSnapshot) *RuntimeStats {
+	return &RuntimeStats{
+		Before: before,
+		After:  after,
+		Delta: RuntimeDelta{
+			Goroutines:  after.Goroutines - before.Goroutines,
+			HeapAlloc:   int64(after.HeapAlloc) - int64(before.HeapAlloc),
+			HeapObjects: int64(after.HeapObjects) - int64(before.HeapObjects),
+			TotalAlloc:  after.TotalAlloc - before.TotalAlloc,
+			Sys:         int64(after.Sys) - int64(before.Sys),
+			Mallocs:     after.Mallocs - before.Mallocs,
+			NumGC:       after.NumGC - before.NumGC,
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package taskkit
+
+import "testing"
+
+func TestProfileRuntime(t *testing.T) {
+	var sink [][]byte
+	registerHandlers(t, map[string]StepHandler{
+		"test-allocate": func(StepInput, Deps) StepResult {
+			for i := 0; i < 100; i++ {
+				sink = append(sink, make([]byte, 1024))
+			}
+			return NewStepResult()
+		},
+	})
+	const workflow = `
+name: profile
+platform: test
+steps:
+  - name: allocate
+`
+
+	result, _ := runTestWorkflow(t, workflow, LocalRunnerConfig{ProfileRuntime: true})
+	stats := result.Runtime
+	if stats == nil {
+		t.Fatal("Runtime not recorded with ProfileRuntime set")
+	}
+	for name, snap := range map[string]RuntimeSnapshot{"before": stats.Before, "after": stats.After} {
+		if snap.Goroutines <= 0 || snap.HeapAlloc == 0 || snap.Sys == 0 || snap.Mallocs == 0 {
+			t.Errorf("%s snapshot not populated: %+v", name, snap)
+		}
+	}
+	// Cumulative counters only grow over a run
+	if stats.Delta.TotalAlloc < 100*1024 || stats.Delta.Mallocs == 0 {
+		t.Errorf("delta = %+v, want the handler's allocations counted", stats.Delta)
+	}
+	if stats.After.TotalAlloc < stats.Before.TotalAlloc || stats.After.NumGC < stats.Before.NumGC {
+		t.Errorf("cumulative counters shrank: before %+v, after %+v", stats.Before, stats.After)
+	}
+
+	if result, _ := runTestWorkflow(t, workflow, LocalRunnerConfig{}); result.Runtime != nil {
+		t.Errorf("Runtime = %+v without ProfileRuntime, want nil", result.Runtime)
+	}
+}
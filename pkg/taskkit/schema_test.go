@@ -0,0 +1,47 @@
+package taskkit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func schemaWorkflow(version string) string {
+	return fmt.Sprintf(`
+name: schema
+platform: test
+%s
+steps:
+  - name: build
+`, version)
+}
+
+func TestSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		wantErr string
+	}{
+		{"supported", fmt.Sprintf("schema_version: %d", CurrentSchemaVersion), ""},
+		{"missing", "", ""},
+		{"unsupported", fmt.Sprintf("schema_version: %d", CurrentSchemaVersion+1), "unsupported schema_version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wf, err := LoadWorkflow(writeFile(t, t.TempDir(), "workflow.yaml", schemaWorkflow(tt.version)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadWorkflow error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadWorkflow: %v", err)
+			}
+			// A missing version defaults to the current one
+			if wf.SchemaVersion != CurrentSchemaVersion {
+				t.Errorf("SchemaVersion = %d, want %d", wf.SchemaVersion, CurrentSchemaVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package taskkit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const seedWorkflow = `
+name: seed
+platform: test
+steps:
+  - name: first
+  - name: second
+    depends: [first]
+`
+
+// seededIDs runs seedWorkflow with seed and returns the ID each step drew
+// from Deps.Rand, and the seed recorded in the result
+func seededIDs(t *testing.T, seed int64) ([]string, int64) {
+	t.Helper()
+	var ids []string
+	handler := func(input StepInput, deps Deps) StepResult {
+		ids = append(ids, fmt.Sprintf("%s-%08x", input.StepName, deps.Rand.Uint32()))
+		return NewStepResult()
+	}
+	snap := SnapshotRegistry()
+	defer RestoreRegistry(snap)
+	Register("test-first", handler)
+	Register("test-second", handler)
+	result, _ := runTestWorkflow(t, seedWorkflow, LocalRunnerConfig{Seed: seed})
+	return ids, result.Seed
+}
+
+func TestSeedReproducesRandomness(t *testing.T) {
+	first, seed := seededIDs(t, 1234)
+	second, _ := seededIDs(t, 1234)
+	if seed != 1234 {
+		t.Errorf("result seed = %d, want 1234", seed)
+	}
+	if len(first) != 2 || !reflect.DeepEqual(first, second) {
+		t.Errorf("IDs with the same seed = %v and %v, want identical", first, second)
+	}
+	if other, _ := seededIDs(t, 99); reflect.DeepEqual(first, other) {
+		t.Errorf("IDs with different seeds are identical: %v", first)
+	}
+}
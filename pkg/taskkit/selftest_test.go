@@ -0,0 +1,36 @@
+package taskkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRunSelfTests(t *testing.T) {
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	RegisterWithTest("selftest-pass", succeed, func() error { return nil })
+	RegisterWithTest("selftest-fail", succeed, func() error { return errors.New("canned input rejected") })
+	RegisterWithTest("selftest-panic", succeed, func() error { panic("boom") })
+
+	results := make(map[string]SelfTestResult)
+	for _, result := range RunSelfTests() {
+		results[result.Handler] = result
+	}
+	if r := results["selftest-pass"]; !r.Passed || r.Error != "" {
+		t.Errorf("selftest-pass = %+v, want passed", r)
+	}
+	if r := results["selftest-fail"]; r.Passed || r.Error != "canned input rejected" {
+		t.Errorf("selftest-fail = %+v, want failed with its error", r)
+	}
+	// A panicking self-test is reported as a failure, not a crash
+	if r := results["selftest-panic"]; r.Passed || !strings.Contains(r.Error, "panic: boom") {
+		t.Errorf("selftest-panic = %+v, want failed with the panic", r)
+	}
+
+	for _, info := range Handlers() {
+		if info.Name == "selftest-pass" && !info.HasSelfTest {
+			t.Error("selftest-pass listed without its self-test")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package taskkit
+
+import "testing"
+
+func TestSkipReasonKeptOutOfError(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			result.Skip("image already deployed")
+			return result
+		},
+		"test-legacy": succeed,
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: skips
+platform: test
+steps:
+  - name: deploy
+  - name: legacy
+    disabled: true
+`, LocalRunnerConfig{})
+	for name, reason := range map[string]string{
+		"deploy": "image already deployed", // skipped by its handler
+		"legacy": "disabled",               // skipped by the runner
+	} {
+		step := stepByName(t, result, name)
+		if step.Status != "Skipped" || step.SkipReason != reason {
+			t.Errorf("%s = %s (%q), want Skipped with reason %q", name, step.Status, step.SkipReason, reason)
+		}
+		if step.Error != "" {
+			t.Errorf("%s error = %q, want empty for a skip", name, step.Error)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlowStepWarning(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-slow": func(StepInput, Deps) StepResult {
+			time.Sleep(60 * time.Millisecond)
+			return NewStepResult()
+		},
+	})
+
+	// The step may take 20ms * 1.5 = 30ms before it is flagged
+	result, out := runTestWorkflow(t, `
+name: slow-step
+platform: test
+steps:
+  - name: slow
+    expected_seconds: 0.02
+`, LocalRunnerConfig{SlowStepFactor: 1.5})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded (a slow step does not fail)", result.Result)
+	}
+
+	slow := stepByName(t, result, "slow")
+	if !slow.SlowerThanExpected {
+		t.Errorf("SlowerThanExpected = false, want true")
+	}
+	if len(slow.Messages) != 1 || slow.Messages[0].Severity != "WARNING" || !strings.Contains(slow.Messages[0].Text, "more than 1.5x its expected 20ms") {
+		t.Errorf("messages = %+v, want a slow step warning", slow.Messages)
+	}
+	if !strings.Contains(out, "[WARNING] Step took") {
+		t.Errorf("warning not printed:\n%s", out)
+	}
+}
+
+func TestFastStepNotFlagged(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-fast": succeed})
+
+	result, _ := runTestWorkflow(t, `
+name: fast-step
+platform: test
+steps:
+  - name: fast
+    expected_seconds: 10
+`, LocalRunnerConfig{})
+	fast := stepByName(t, result, "fast")
+	if fast.Status != "Succeeded" || fast.SlowerThanExpected || len(fast.Messages) != 0 {
+		t.Errorf("fast = %+v, want Succeeded with no slow step flag or warning", fast)
+	}
+}
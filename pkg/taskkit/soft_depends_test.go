@@ -0,0 +1,65 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSoftDependsOrdersAfterPresentSteps(t *testing.T) {
+	var ran []string
+	record := func(name string) StepHandler {
+		return func(StepInput, Deps) StepResult {
+			ran = append(ran, name)
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-notify": record("notify"),
+		"test-build":  record("build"),
+		"test-lint":   record("lint"),
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: soft
+platform: test
+steps:
+  - name: notify
+    soft_depends: [build, lint, cleanup]
+  - name: build
+  - name: lint
+    disabled: true
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s (%s), want Succeeded", result.Result, result.ErrorMessage)
+	}
+	// A disabled or absent soft dependency neither blocks nor skips notify
+	if want := []string{"build", "notify"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("ran = %v, want %v", ran, want)
+	}
+	if got := stepByName(t, result, "notify").Status; got != "Succeeded" {
+		t.Errorf("notify status = %s, want Succeeded", got)
+	}
+}
+
+func TestSoftDependsDoNotRequireSuccess(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build":  failWith("compile error"),
+		"test-notify": succeed,
+		"test-report": succeed,
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: soft-failure
+platform: test
+steps:
+  - name: build
+  - name: notify
+    soft_depends: [build]
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{})
+	statuses := stepStatuses(result)
+	if statuses["notify"] != "Succeeded" {
+		t.Errorf("notify status = %s, want Succeeded after a failed soft dependency", statuses["notify"])
+	}
+}
@@ -0,0 +1,145 @@
+// Package sqlitestore provides a SQLite-backed taskkit.ResultStore so run
+// results can be queried historically.
+//
+// Each run is a row in "runs"; with Options.Steps each step is also a row
+// in "steps", keyed by the run's id. The schema is created on first use.
+package sqlitestore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	// Registers the "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+// schema creates the tables if they don't exist yet
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id     TEXT NOT NULL,
+	workflow    TEXT NOT NULL,
+	result      TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	started_at  TIMESTAMP NOT NULL,
+	ended_at    TIMESTAMP NOT NULL,
+	error       TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS runs_workflow ON runs (workflow, started_at);
+CREATE TABLE IF NOT EXISTS steps (
+	run_id       INTEGER NOT NULL REFERENCES runs (id),
+	position     INTEGER NOT NULL,
+	name         TEXT NOT NULL,
+	handler      TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	duration_ms  INTEGER NOT NULL,
+	attempts     INTEGER NOT NULL,
+	failure_kind TEXT NOT NULL DEFAULT '',
+	error        TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (run_id, position)
+);
+`
+
+// Options configures a Store
+type Options struct {
+	// Path is the database file, or ":memory:" for a private in-memory
+	// database
+	Path string
+	// Steps also records a row per step
+	Steps bool
+}
+
+// Store is a taskkit.ResultStore backed by SQLite
+type Store struct {
+	db   *sql.DB
+	opts Options
+}
+
+var _ taskkit.ResultStore = (*Store)(nil)
+
+// New opens the database at opts.Path
+func New(opts Options) (*Store, error) {
+	if opts.Path == "" {
+		return nil, fmt.Errorf("sqlite result store requires a database path")
+	}
+	db, err := sql.Open("sqlite", opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result database: %w", err)
+	}
+	// An in-memory database lives as long as its connection
+	db.SetMaxOpenConns(1)
+	return NewWithDB(db, opts), nil
+}
+
+// NewWithDB creates a Store around an open database
+func NewWithDB(db *sql.DB, opts Options) *Store {
+	return &Store{db: db, opts: opts}
+}
+
+// DB returns the underlying database, e.g. for queries
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// SaveResult inserts a row for the run, and its steps with Options.Steps,
+// in one transaction, creating the schema if needed
+func (s *Store) SaveResult(result taskkit.ExecutionResult) error {
+	if _, err := s.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create result schema: %w", err)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin result transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		`INSERT INTO runs (task_id, workflow, result, duration_ms, started_at, ended_at, error) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		result.TaskID, result.WorkflowName, result.Result, durationMillis(result.Duration),
+		result.StartTime.UTC(), result.EndTime.UTC(), result.ErrorMessage,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert run: %w", err)
+	}
+	if s.opts.Steps {
+		runID, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to read run id: %w", err)
+		}
+		for i, step := range result.Steps {
+			attempts := len(step.Attempts)
+			if attempts == 0 && step.Status != "Skipped" && step.Status != "Cached" {
+				attempts = 1
+			}
+			if _, err := tx.Exec(
+				`INSERT INTO steps (run_id, position, name, handler, status, duration_ms, attempts, failure_kind, error) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				runID, i, step.Name, step.Handler, step.Status, durationMillis(step.Duration),
+				attempts, string(step.FailureKind), step.Error,
+			); err != nil {
+				return fmt.Errorf("failed to insert step %s: %w", step.Name, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit result: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// durationMillis parses a recorded duration such as "1.5s", or 0 if it
+// doesn't parse
+func durationMillis(s string) int64 {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d.Milliseconds()
+}
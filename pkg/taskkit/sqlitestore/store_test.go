@@ -0,0 +1,170 @@
+package sqlitestore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func newTestStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+	if opts.Path == "" {
+		opts.Path = ":memory:"
+	}
+	store, err := New(opts)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// registerHandlers registers the handlers runWorkflow needs: deploy fails
+// its first attempt and then succeeds, and verify is skipped by its when
+func registerHandlers(t *testing.T) {
+	t.Helper()
+	snap := taskkit.SnapshotRegistry()
+	t.Cleanup(func() { taskkit.RestoreRegistry(snap) })
+	succeed := func(taskkit.StepInput, taskkit.Deps) taskkit.StepResult {
+		return taskkit.NewStepResult()
+	}
+	taskkit.Register("sqlite-build", succeed)
+	taskkit.Register("sqlite-verify", succeed)
+	taskkit.Register("sqlite-deploy", func(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResult {
+		result := taskkit.NewStepResult()
+		if input.Attempt == 1 {
+			result.AddError("registry unavailable", "test")
+		}
+		return result
+	})
+}
+
+// runWorkflow runs a release workflow, recording the result in store
+func runWorkflow(t *testing.T, store *Store, taskID string) taskkit.ExecutionResult {
+	t.Helper()
+	dir := t.TempDir()
+	workflow := filepath.Join(dir, "workflow.yaml")
+	if err := os.WriteFile(workflow, []byte(`
+name: release
+platform: sqlite
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+    retries: 1
+  - name: verify
+    when: vars.never == true
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner, err := taskkit.NewLocalRunner(taskkit.LocalRunnerConfig{
+		WorkflowPath: workflow,
+		Workdir:      dir,
+		TaskID:       taskID,
+		Stdout:       &strings.Builder{},
+		ResultStore:  store,
+	})
+	if err != nil {
+		t.Fatalf("NewLocalRunner: %v", err)
+	}
+	return runner.Run()
+}
+
+func TestStoreRecordsRun(t *testing.T) {
+	registerHandlers(t)
+	store := newTestStore(t, Options{Steps: true})
+	result := runWorkflow(t, store, "task-1")
+
+	var (
+		taskID, workflow, status string
+		durationMS               int64
+		runID                    int64
+	)
+	row := store.DB().QueryRow(`SELECT id, task_id, workflow, result, duration_ms FROM runs`)
+	if err := row.Scan(&runID, &taskID, &workflow, &status, &durationMS); err != nil {
+		t.Fatalf("query runs: %v", err)
+	}
+	if taskID != "task-1" || workflow != "release" || status != result.Result || durationMS < 0 {
+		t.Errorf("run row = %s %s %s %dms, want task-1 release %s", taskID, workflow, status, durationMS, result.Result)
+	}
+
+	rows, err := store.DB().Query(`SELECT name, handler, status, attempts FROM steps WHERE run_id = ? ORDER BY position`, runID)
+	if err != nil {
+		t.Fatalf("query steps: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var name, handler, status string
+		var attempts int
+		if err := rows.Scan(&name, &handler, &status, &attempts); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s %s %s %d", name, handler, status, attempts))
+	}
+	want := []string{
+		"build sqlite-build Succeeded 1",
+		"deploy sqlite-deploy Succeeded 2",
+		"verify sqlite-verify Skipped 0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("step rows = %q, want %q", got, want)
+	}
+}
+
+func TestStoreWithoutSteps(t *testing.T) {
+	registerHandlers(t)
+	store := newTestStore(t, Options{})
+	runWorkflow(t, store, "task-1")
+	runWorkflow(t, store, "task-2")
+
+	var runs, steps int
+	if err := store.DB().QueryRow(`SELECT COUNT(*) FROM runs`).Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DB().QueryRow(`SELECT COUNT(*) FROM steps`).Scan(&steps); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 || steps != 0 {
+		t.Errorf("rows = %d runs, %d steps; want 2 runs and no steps", runs, steps)
+	}
+}
+
+func TestStoreFileSurvivesReopen(t *testing.T) {
+	registerHandlers(t)
+	path := filepath.Join(t.TempDir(), "results.db")
+	for _, id := range []string{"task-1", "task-2"} {
+		store := newTestStore(t, Options{Path: path})
+		runWorkflow(t, store, id)
+		store.Close()
+	}
+
+	store := newTestStore(t, Options{Path: path})
+	var ids []string
+	rows, err := store.DB().Query(`SELECT task_id FROM runs ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if want := []string{"task-1", "task-2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("task ids = %v, want %v", ids, want)
+	}
+}
+
+func TestNewRequiresPath(t *testing.T) {
+	if _, err := New(Options{}); err == nil {
+		t.Error("New without a path succeeded, want error")
+	}
+}
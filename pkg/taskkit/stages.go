@@ -0,0 +1,40 @@
+package taskkit
+
+// StageResult aggregates the steps of one stage
+type StageResult struct {
+	Name   string   `json:"name"`
+	Status string   `json:"status"` // Failed, Succeeded, or Skipped
+	Steps  []string `json:"steps"`
+}
+
+// NewStageResults groups steps by stage in order of each stage's first
+// step. A stage failed if any of its steps failed, succeeded if any other
+// step ran or was cached, and is skipped otherwise. Steps without a stage
+// are left out.
+func NewStageResults(steps []StepExec) []StageResult {
+	var stages []StageResult
+	index := make(map[string]int)
+	for _, step := range steps {
+		if step.Stage == "" {
+			continue
+		}
+		i, ok := index[step.Stage]
+		if !ok {
+			i = len(stages)
+			index[step.Stage] = i
+			stages = append(stages, StageResult{Name: step.Stage, Status: "Skipped"})
+		}
+		stage := &stages[i]
+		stage.Steps = append(stage.Steps, step.Name)
+		switch step.Status {
+		case "Failed":
+			stage.Status = "Failed"
+		case "Skipped":
+		default:
+			if stage.Status != "Failed" {
+				stage.Status = "Succeeded"
+			}
+		}
+	}
+	return stages
+}
@@ -0,0 +1,74 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStageResults(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-compile": succeed,
+		"test-unit":    failWith("3 tests failed"),
+		"test-package": succeed,
+		"test-publish": succeed,
+		"test-notify":  succeed,
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: stages
+platform: test
+steps:
+  - name: compile
+    stage: build
+  - name: unit
+    stage: verify
+    depends: [compile]
+  - name: package
+    stage: build
+    depends: [compile]
+  - name: publish
+    stage: release
+    depends: [package]
+    if_step_status:
+      unit: Succeeded
+  - name: notify
+    template: finalize
+`, LocalRunnerConfig{})
+	if result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+
+	var order []string
+	for _, step := range result.Steps {
+		order = append(order, step.Name)
+	}
+	if want := []string{"compile", "unit", "package", "publish", "notify"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("steps ran in order %v, want build and verify interleaved as %v", order, want)
+	}
+
+	// Stages are ordered by their first step; unstaged steps are left out
+	want := []StageResult{
+		{Name: "build", Status: "Succeeded", Steps: []string{"compile", "package"}},
+		{Name: "verify", Status: "Failed", Steps: []string{"unit"}},
+		{Name: "release", Status: "Skipped", Steps: []string{"publish"}},
+	}
+	if !reflect.DeepEqual(result.Stages, want) {
+		t.Errorf("stages = %+v, want %+v", result.Stages, want)
+	}
+}
+
+func TestStageFailsWhenAnyStepFails(t *testing.T) {
+	stages := NewStageResults([]StepExec{
+		{Name: "compile", Stage: "build", Status: "Succeeded"},
+		{Name: "unit", Stage: "verify", Status: "Succeeded"},
+		{Name: "package", Stage: "build", Status: "Failed"},
+		{Name: "image", Stage: "build", Status: "Cached"},
+	})
+	want := []StageResult{
+		{Name: "build", Status: "Failed", Steps: []string{"compile", "package", "image"}},
+		{Name: "verify", Status: "Succeeded", Steps: []string{"unit"}},
+	}
+	if !reflect.DeepEqual(stages, want) {
+		t.Errorf("stages = %+v, want %+v", stages, want)
+	}
+}
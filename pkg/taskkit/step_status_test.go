@@ -0,0 +1,55 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const stepStatusWorkflow = `
+name: step-status
+platform: test
+steps:
+  - name: build
+  - name: cleanup
+    if_step_status: {build: Failed}
+  - name: report
+    template: finalize
+`
+
+func TestIfStepStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   StepHandler
+		cleanup string
+	}{
+		{"runs on failure", failWith("compile error"), "Succeeded"},
+		{"skipped on success", succeed, "Skipped"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerHandlers(t, map[string]StepHandler{
+				"test-build":   tt.build,
+				"test-cleanup": succeed,
+				"test-report":  succeed,
+			})
+			result, _ := runTestWorkflow(t, stepStatusWorkflow, LocalRunnerConfig{})
+			cleanup := stepByName(t, result, "cleanup")
+			if cleanup.Status != tt.cleanup {
+				t.Fatalf("cleanup = %s, want %s", cleanup.Status, tt.cleanup)
+			}
+			if tt.cleanup == "Skipped" && !strings.Contains(cleanup.SkipReason, `step "build" status is Succeeded, want Failed`) {
+				t.Errorf("skip reason = %q", cleanup.SkipReason)
+			}
+		})
+	}
+}
+
+func TestCheckStepStatus(t *testing.T) {
+	step := WorkflowStep{IfStepStatus: map[string]string{"build": "failed"}}
+	if ok, _ := step.CheckStepStatus(map[string]string{"build": "Failed"}); !ok {
+		t.Error("status match is not case-insensitive")
+	}
+	if ok, reason := step.CheckStepStatus(map[string]string{}); ok || !strings.Contains(reason, "did not run") {
+		t.Errorf("CheckStepStatus without build = %v, %q; want a did not run reason", ok, reason)
+	}
+}
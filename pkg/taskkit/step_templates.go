@@ -0,0 +1,66 @@
+package taskkit
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandStepTemplates instantiates the steps of a workflow document that
+// use a template and returns the expanded document as YAML.
+//
+// A step that uses a template starts from the template's fields and
+// overrides them with its own, as extends does for steps. Its params are
+// merged lowest first: template params, with, then the step's own params.
+func expandStepTemplates(data []byte) ([]byte, error) {
+	doc := make(map[string]any)
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
+	}
+	templates, _ := doc["templates"].(map[string]any)
+	steps, _ := doc["steps"].([]any)
+	for i, s := range steps {
+		step, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+		use, _ := step["use"].(string)
+		if use == "" {
+			continue
+		}
+		tmpl, ok := templates[use].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("step %v uses unknown template %q", step["name"], use)
+		}
+		if _, nested := tmpl["use"]; nested {
+			return nil, fmt.Errorf("template %q cannot use another template", use)
+		}
+
+		tmplParams, _ := tmpl["params"].(map[string]any)
+		with, _ := step["with"].(map[string]any)
+		stepParams, _ := step["params"].(map[string]any)
+		params := mergeMaps(mergeMaps(tmplParams, with), stepParams)
+
+		expanded := mergeMaps(tmpl, step)
+		if len(params) > 0 {
+			expanded["params"] = params
+		}
+		steps[i] = expanded
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal expanded workflow: %w", err)
+	}
+	return out, nil
+}
+
+// usesTemplates reports whether any step uses a step template
+func (w *WorkflowDefinition) usesTemplates() bool {
+	for _, step := range w.Steps {
+		if step.Use != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package taskkit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const stepTemplatesWorkflow = `
+name: step-templates
+platform: test
+templates:
+  deploy:
+    handler: test-deploy
+    retries: 2
+    params:
+      replicas: 1
+      region: us-east
+      image: app:latest
+steps:
+  - name: deploy-staging
+    use: deploy
+    with:
+      region: eu-west
+  - name: deploy-prod
+    use: deploy
+    retries: 4
+    with:
+      region: us-west
+      replicas: 3
+    params:
+      replicas: 5
+`
+
+func TestStepTemplatesInstantiatedTwice(t *testing.T) {
+	wf := loadTestWorkflow(t, stepTemplatesWorkflow)
+	steps := make(map[string]WorkflowStep, len(wf.Steps))
+	for _, step := range wf.Steps {
+		steps[step.Name] = step
+	}
+
+	// Template params, then with, then the step's own params
+	tests := []struct {
+		step    string
+		retries int
+		params  map[string]any
+	}{
+		{"deploy-staging", 2, map[string]any{"replicas": 1, "region": "eu-west", "image": "app:latest"}},
+		{"deploy-prod", 4, map[string]any{"replicas": 5, "region": "us-west", "image": "app:latest"}},
+	}
+	for _, tt := range tests {
+		step, ok := steps[tt.step]
+		if !ok {
+			t.Fatalf("steps = %v, want %s", wf.Steps, tt.step)
+		}
+		if step.Handler != "test-deploy" || step.Retries != tt.retries {
+			t.Errorf("%s = handler %q, retries %d, want test-deploy and %d", tt.step, step.Handler, step.Retries, tt.retries)
+		}
+		if !reflect.DeepEqual(step.Params, tt.params) {
+			t.Errorf("%s params = %v, want %v", tt.step, step.Params, tt.params)
+		}
+	}
+}
+
+func TestStepTemplateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     string
+	}{
+		{"unknown use", `
+name: bad
+platform: test
+steps:
+  - name: deploy
+    use: missing
+`, `uses unknown template "missing"`},
+		{"name collision", `
+name: bad
+platform: test
+templates:
+  deploy:
+    params:
+      region: us-east
+steps:
+  - name: deploy
+    use: deploy
+    with:
+      region: eu-west
+  - name: deploy
+    use: deploy
+`, "duplicate step names: deploy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWorkflow(writeFile(t, t.TempDir(), "workflow.yaml", tt.workflow))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadWorkflow error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
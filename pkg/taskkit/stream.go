@@ -0,0 +1,77 @@
+package taskkit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// stepWriter streams handler output to the console one line at a time,
+// prefixing each line with the step name and optionally keeping a copy
+type stepWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	prefix  string
+	pending bytes.Buffer
+	capture *bytes.Buffer
+	filter  func(string) string
+}
+
+func newStepWriter(out io.Writer, stepName string, capture bool, filter func(string) string) *stepWriter {
+	w := &stepWriter{
+		out:    out,
+		prefix: fmt.Sprintf("  [%s] ", stepName),
+		filter: filter,
+	}
+	if capture {
+		w.capture = &bytes.Buffer{}
+	}
+	return w
+}
+
+// Write implements io.Writer
+func (w *stepWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.capture != nil {
+		w.capture.Write(p)
+	}
+	w.pending.Write(p)
+	for {
+		line, err := w.pending.ReadBytes('\n')
+		if err != nil {
+			// Incomplete line: keep it until more data or Flush
+			w.pending.Reset()
+			w.pending.Write(line)
+			break
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.prefix, w.filter(string(line))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line
+func (w *stepWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.pending.Len() > 0 {
+		fmt.Fprintf(w.out, "%s%s\n", w.prefix, w.filter(w.pending.String()))
+		w.pending.Reset()
+	}
+}
+
+// Captured returns everything written so far, or "" if capture is disabled
+func (w *stepWriter) Captured() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.capture == nil {
+		return ""
+	}
+	return w.filter(w.capture.String())
+}
@@ -0,0 +1,46 @@
+package taskkit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const streamWorkflow = `
+name: stream
+platform: test
+steps:
+  - name: build
+`
+
+func streamHandlers(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-build": func(input StepInput, deps Deps) StepResult {
+			fmt.Fprintln(deps.Stdout, "compiling")
+			fmt.Fprint(deps.Stdout, "linking")
+			fmt.Fprint(deps.Stdout, " done\npartial")
+			return NewStepResult()
+		},
+	})
+}
+
+func TestStreamedOutputIsPrefixed(t *testing.T) {
+	streamHandlers(t)
+	result, out := runTestWorkflow(t, streamWorkflow, LocalRunnerConfig{})
+	for _, line := range []string{"  [build] compiling\n", "  [build] linking done\n", "  [build] partial\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("console output missing %q:\n%s", line, out)
+		}
+	}
+	if _, ok := stepByName(t, result, "build").Output["stdout"]; ok {
+		t.Error("stdout captured without CaptureStdout")
+	}
+}
+
+func TestStreamedOutputCaptured(t *testing.T) {
+	streamHandlers(t)
+	result, _ := runTestWorkflow(t, streamWorkflow, LocalRunnerConfig{CaptureStdout: true})
+	if got := stepByName(t, result, "build").Output["stdout"]; got != "compiling\nlinking done\npartial" {
+		t.Errorf("captured stdout = %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package taskkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const corruptVars = "image: [unterminated\n"
+
+func TestCorruptVarsFile(t *testing.T) {
+	const workflow = `
+name: corrupt-vars
+platform: test
+steps:
+  - name: read
+`
+	t.Run("strict", func(t *testing.T) {
+		registerHandlers(t, map[string]StepHandler{"test-read": succeed})
+		workdir := t.TempDir()
+		writeFile(t, workdir, "vars.yaml", corruptVars)
+
+		_, err := NewLocalRunner(LocalRunnerConfig{
+			WorkflowPath: writeFile(t, t.TempDir(), "workflow.yaml", workflow),
+			Workdir:      workdir,
+			StrictVars:   true,
+		})
+		if !errors.Is(err, ErrMalformedVars) {
+			t.Errorf("NewLocalRunner error = %v, want ErrMalformedVars", err)
+		}
+	})
+
+	t.Run("lenient", func(t *testing.T) {
+		var seen map[string]any
+		registerHandlers(t, map[string]StepHandler{
+			"test-read": func(input StepInput, deps Deps) StepResult {
+				seen = input.Vars
+				return NewStepResult()
+			},
+		})
+		workdir := t.TempDir()
+		writeFile(t, workdir, "vars.yaml", corruptVars)
+
+		result, out := runTestWorkflow(t, workflow, LocalRunnerConfig{Workdir: workdir})
+		if result.Result != "Succeeded" {
+			t.Fatalf("result = %s, want Succeeded", result.Result)
+		}
+		if len(seen) != 0 {
+			t.Errorf("vars = %v, want none from the corrupt file", seen)
+		}
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "ignoring vars") {
+			t.Errorf("warnings = %q, want one about the ignored vars file", result.Warnings)
+		}
+		if !strings.Contains(out, "Warning: ignoring vars") {
+			t.Errorf("warning not printed:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package taskkit
+
+import "testing"
+
+func TestStrictWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		want   string
+	}{
+		{"warning passes by default", false, "Succeeded"},
+		{"warning fails under strict mode", true, "Failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			registerHandlers(t, map[string]StepHandler{
+				"test-check": func(StepInput, Deps) StepResult {
+					calls++
+					result := NewStepResult()
+					result.AddWarning("disk 85% full", "test")
+					return result
+				},
+			})
+			result, _ := runTestWorkflow(t, `
+name: strict
+platform: test
+steps:
+  - name: check
+    retries: 1
+`, LocalRunnerConfig{StrictWarnings: tt.strict})
+			check := stepByName(t, result, "check")
+			if check.Status != tt.want {
+				t.Errorf("check = %s, want %s", check.Status, tt.want)
+			}
+			// A strict failure is retried like any other
+			if wantCalls := map[bool]int{false: 1, true: 2}[tt.strict]; calls != wantCalls {
+				t.Errorf("handler called %d times, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestStepResultHasWarnings(t *testing.T) {
+	result := NewStepResult()
+	result.AddInfo("ok", "test")
+	if result.HasWarnings() || result.HasErrors() {
+		t.Error("info message counted as a warning or error")
+	}
+	result.AddWarning("careful", "test")
+	if !result.HasWarnings() || result.HasErrors() {
+		t.Error("HasWarnings/HasErrors wrong after a warning")
+	}
+}
@@ -0,0 +1,126 @@
+package taskkit
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RegistrySnapshot is a saved copy of the handler registry, self-tests,
+// declared handler params, and registering packages
+type RegistrySnapshot struct {
+	handlers map[string]StepHandler
+	tests    map[string]SelfTest
+	params   map[string][]string
+	packages map[string]string
+}
+
+// SnapshotRegistry saves the handler registry so tests can put it back
+// with RestoreRegistry after stubbing or registering handlers
+func SnapshotRegistry() RegistrySnapshot {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	snap := RegistrySnapshot{
+		handlers: make(map[string]StepHandler, len(registry)),
+		tests:    make(map[string]SelfTest, len(selfTests)),
+		params:   make(map[string][]string, len(handlerParams)),
+		packages: make(map[string]string, len(handlerPackages)),
+	}
+	for name, h := range registry {
+		snap.handlers[name] = h
+	}
+	for name, t := range selfTests {
+		snap.tests[name] = t
+	}
+	for name, keys := range handlerParams {
+		snap.params[name] = keys
+	}
+	for name, pkg := range handlerPackages {
+		snap.packages[name] = pkg
+	}
+	return snap
+}
+
+// RestoreRegistry replaces the handler registry with a snapshot
+func RestoreRegistry(snap RegistrySnapshot) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	registry = make(map[string]StepHandler, len(snap.handlers))
+	for name, h := range snap.handlers {
+		registry[name] = h
+	}
+	selfTests = make(map[string]SelfTest, len(snap.tests))
+	for name, t := range snap.tests {
+		selfTests[name] = t
+	}
+	handlerParams = make(map[string][]string, len(snap.params))
+	for name, keys := range snap.params {
+		handlerParams[name] = keys
+	}
+	handlerPackages = make(map[string]string, len(snap.packages))
+	for name, pkg := range snap.packages {
+		handlerPackages[name] = pkg
+	}
+}
+
+// StubHandlers replaces every registered handler whose name matches the
+// glob pattern (path.Match syntax, e.g. "smoke-test-*") with a fake that
+// returns a copy of result, for testing workflow wiring without handler
+// logic. A pattern without wildcards stubs that name even if it is not
+// registered. The stubbed handlers' self-tests and declared params are
+// dropped; a stubbed name keeps the package that registered it, and a new
+// one is attributed to the caller. It returns the stubbed names, sorted;
+// take a SnapshotRegistry first to undo it.
+func StubHandlers(pattern string, result StepResult) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid handler pattern %q: %w", pattern, err)
+	}
+	pkg := callerPackage()
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	var names []string
+	for name := range registry {
+		if ok, _ := path.Match(pattern, name); ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && !strings.ContainsAny(pattern, `*?[\`) {
+		names = append(names, pattern)
+	}
+	sort.Strings(names)
+
+	stub := func(StepInput, Deps) StepResult {
+		return copyStepResult(result)
+	}
+	for _, name := range names {
+		registry[name] = stub
+		delete(selfTests, name)
+		delete(handlerParams, name)
+		if _, ok := handlerPackages[name]; !ok {
+			handlerPackages[name] = pkg
+		}
+	}
+	return names, nil
+}
+
+// copyStepResult copies result's messages and maps so each stub call
+// returns an independent result
+func copyStepResult(result StepResult) StepResult {
+	out := NewStepResult()
+	out.Messages = append(out.Messages, result.Messages...)
+	for k, v := range result.ContextUpdates {
+		out.ContextUpdates[k] = v
+	}
+	for k, v := range result.Output {
+		out.Output[k] = v
+	}
+	for k, v := range result.FlowControl {
+		out.FlowControl[k] = v
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStubHandlers(t *testing.T) {
+	realCalls := 0
+	real := func(StepInput, Deps) StepResult {
+		realCalls++
+		result := NewStepResult()
+		result.SetOutput("source", "real")
+		return result
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"stub-build":  real,
+		"stub-deploy": real,
+		"other-build": real,
+	})
+	snap := SnapshotRegistry()
+
+	canned := NewStepResult()
+	canned.SetOutput("source", "stub")
+	canned.AddInfo("stubbed", "test")
+	names, err := StubHandlers("stub-*", canned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"stub-build", "stub-deploy"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("stubbed = %v, want %v", names, want)
+	}
+
+	result, _ := runTestWorkflow(t, `
+name: stubbed
+platform: stub
+steps:
+  - name: build
+  - name: deploy
+    depends: [build]
+`, LocalRunnerConfig{})
+	if result.Result != "Succeeded" || realCalls != 0 {
+		t.Fatalf("result = %s after %d real calls, want Succeeded with none", result.Result, realCalls)
+	}
+	for _, step := range []string{"build", "deploy"} {
+		exec := stepByName(t, result, step)
+		if exec.Output["source"] != "stub" || len(exec.Messages) != 1 || exec.Messages[0].Text != "stubbed" {
+			t.Errorf("%s = %v %+v, want the canned output and message", step, exec.Output, exec.Messages)
+		}
+	}
+
+	// Handlers outside the pattern are untouched
+	other, _ := Get("other-build")
+	if other(StepInput{}, Deps{}).Output["source"] != "real" {
+		t.Errorf("other-build was stubbed")
+	}
+
+	RestoreRegistry(snap)
+	for _, name := range names {
+		handler, ok := Get(name)
+		if !ok {
+			t.Fatalf("%s missing after restore", name)
+		}
+		if got := handler(StepInput{}, Deps{}).Output["source"]; got != "real" {
+			t.Errorf("%s after restore returned %v, want the real handler", name, got)
+		}
+	}
+}
+
+func TestStubHandlersCopiesResult(t *testing.T) {
+	registerHandlers(t, nil)
+	canned := NewStepResult()
+	canned.SetOutput("count", 1)
+	if _, err := StubHandlers("stub-copy", canned); err != nil {
+		t.Fatal(err)
+	}
+	handler, ok := Get("stub-copy")
+	if !ok {
+		t.Fatal("stub-copy not registered")
+	}
+	first := handler(StepInput{}, Deps{})
+	first.SetOutput("count", 2)
+	if second := handler(StepInput{}, Deps{}); second.Output["count"] != 1 {
+		t.Errorf("second call output = %v, want the canned result unchanged", second.Output)
+	}
+}
+
+func TestStubHandlersInvalidPattern(t *testing.T) {
+	if _, err := StubHandlers("stub-[", NewStepResult()); err == nil {
+		t.Error("StubHandlers accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,51 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandlerSuggestion(t *testing.T) {
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	RestoreRegistry(RegistrySnapshot{})
+	Register("test-deploy", succeed)
+	Register("prod-migrate-db", succeed)
+	wf := loadTestWorkflow(t, `
+name: suggest
+platform: test
+steps:
+  - name: deploy
+`)
+
+	tests := []struct {
+		step WorkflowStep
+		want string
+	}{
+		{WorkflowStep{Name: "depoly"}, "test-deploy"},
+		// A handler under another platform prefix
+		{WorkflowStep{Name: "migrate-db"}, "prod-migrate-db"},
+		{WorkflowStep{Name: "backup"}, ""},
+		{WorkflowStep{Name: "x", Handler: "unrelated-handler"}, ""},
+	}
+	for _, tt := range tests {
+		if got := wf.HandlerSuggestion(tt.step); got != tt.want {
+			t.Errorf("HandlerSuggestion(%+v) = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestRunSuggestsHandlerForMissingStep(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-deploy": succeed})
+
+	result, _ := runTestWorkflow(t, `
+name: near-miss
+platform: test
+steps:
+  - name: depoly
+`, LocalRunnerConfig{AllowMissing: true})
+	step := stepByName(t, result, "depoly")
+	if !strings.Contains(step.Error, "handler not found: test-depoly (did you mean test-deploy?)") {
+		t.Errorf("error = %q, want a suggestion for the near-miss name", step.Error)
+	}
+}
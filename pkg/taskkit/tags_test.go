@@ -0,0 +1,78 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const tagsWorkflow = `
+name: tags
+platform: test
+steps:
+  - name: ping
+    tags: [network]
+  - name: wipe
+    tags: [destructive]
+  - name: reseed
+    depends: [wipe]
+    tags: [network]
+  - name: untagged
+`
+
+func TestRunTagFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LocalRunnerConfig
+		ran     []string
+		skipped map[string]string // step -> skip reason substring
+	}{
+		{
+			name:   "include",
+			config: LocalRunnerConfig{Tags: []string{"network"}},
+			ran:    []string{"ping"},
+			skipped: map[string]string{
+				"wipe":     "filtered by tags",
+				"reseed":   `depends on step "wipe"`,
+				"untagged": "filtered by tags",
+			},
+		},
+		{
+			name:   "exclude",
+			config: LocalRunnerConfig{SkipTags: []string{"destructive"}},
+			ran:    []string{"ping", "untagged"},
+			skipped: map[string]string{
+				"wipe":   "filtered by tags",
+				"reseed": `depends on step "wipe"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := map[string]bool{}
+			handlers := map[string]StepHandler{}
+			for _, name := range []string{"ping", "wipe", "reseed", "untagged"} {
+				handlers["test-"+name] = func(StepInput, Deps) StepResult {
+					called[name] = true
+					return NewStepResult()
+				}
+			}
+			registerHandlers(t, handlers)
+
+			result, _ := runTestWorkflow(t, tagsWorkflow, tt.config)
+			for _, name := range tt.ran {
+				if got := stepByName(t, result, name).Status; got != "Succeeded" {
+					t.Errorf("%s status = %s, want Succeeded", name, got)
+				}
+			}
+			for name, reason := range tt.skipped {
+				step := stepByName(t, result, name)
+				if step.Status != "Skipped" || !strings.Contains(step.SkipReason, reason) {
+					t.Errorf("%s = %s (%q), want Skipped with %q", name, step.Status, step.SkipReason, reason)
+				}
+				if called[name] {
+					t.Errorf("filtered step %s ran", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package taskkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// tempTracker records temp paths created through Deps so the runner can
+// remove them when the run ends
+type tempTracker struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (t *tempTracker) add(path string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paths = append(t.paths, path)
+}
+
+// cleanup removes tracked paths, newest first, and returns the failures
+func (t *tempTracker) cleanup() []error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var errs []error
+	for i := len(t.paths) - 1; i >= 0; i-- {
+		if err := os.RemoveAll(t.paths[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.paths = nil
+	return errs
+}
+
+// tempRoot returns <workdir>/tmp, creating it if needed
+func (d Deps) tempRoot() (string, error) {
+	root := filepath.Join(d.Workdir, "tmp")
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	return root, nil
+}
+
+// TempFile creates a scratch file under <workdir>/tmp (see os.CreateTemp
+// for pattern). The caller closes it; the runner removes it at the end of
+// the run when temp cleanup is enabled.
+func (d Deps) TempFile(pattern string) (*os.File, error) {
+	root, err := d.tempRoot()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(root, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	d.temps.add(f.Name())
+	return f, nil
+}
+
+// TempDir creates a scratch directory under <workdir>/tmp (see
+// os.MkdirTemp for pattern), removed like TempFile
+func (d Deps) TempDir(pattern string) (string, error) {
+	root, err := d.tempRoot()
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(root, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	d.temps.add(dir)
+	return dir, nil
+}
@@ -0,0 +1,61 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTempPaths(t *testing.T) {
+	for _, cleanup := range []bool{true, false} {
+		name := map[bool]string{true: "cleanup", false: "keep"}[cleanup]
+		t.Run(name, func(t *testing.T) {
+			var paths []string
+			registerHandlers(t, map[string]StepHandler{
+				"test-scratch": func(input StepInput, deps Deps) StepResult {
+					result := NewStepResult()
+					f, err := deps.TempFile("render-*.yaml")
+					if err != nil {
+						result.AddError(err.Error(), "test")
+						return result
+					}
+					f.Close()
+					dir, err := deps.TempDir("clone-*")
+					if err != nil {
+						result.AddError(err.Error(), "test")
+						return result
+					}
+					paths = append(paths, f.Name(), dir)
+					return result
+				},
+			})
+			workdir := t.TempDir()
+
+			result, _ := runTestWorkflow(t, `
+name: temps
+platform: test
+steps:
+  - name: scratch
+`, LocalRunnerConfig{Workdir: workdir, CleanupTemp: cleanup})
+			if result.Result != "Succeeded" {
+				t.Fatalf("result = %s, want Succeeded", result.Result)
+			}
+			if len(paths) != 2 {
+				t.Fatalf("paths = %v, want a file and a directory", paths)
+			}
+			for _, path := range paths {
+				if !strings.HasPrefix(path, filepath.Join(workdir, "tmp")+string(filepath.Separator)) {
+					t.Errorf("%s not under the workdir's tmp directory", path)
+				}
+				_, err := os.Stat(path)
+				if cleanup && !os.IsNotExist(err) {
+					t.Errorf("%s left behind with cleanup enabled (stat: %v)", path, err)
+				}
+				if !cleanup && err != nil {
+					t.Errorf("%s removed with cleanup disabled: %v", path, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package taskkit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lineThrottle collapses identical consecutive lines written within window
+// of each other. The first line is written immediately; repeats are counted
+// and reported as one "<line> (repeated Nx)" line, where N is the number of
+// suppressed repeats, once a different line arrives, a repeat comes after
+// the window has lapsed, or Flush is called.
+type lineThrottle struct {
+	mu      sync.Mutex
+	out     io.Writer
+	window  time.Duration
+	now     func() time.Time
+	pending bytes.Buffer
+	last    string
+	lastAt  time.Time
+	repeats int
+}
+
+func newLineThrottle(out io.Writer, window time.Duration) *lineThrottle {
+	return &lineThrottle{out: out, window: window, now: time.Now}
+}
+
+// Write implements io.Writer
+func (t *lineThrottle) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending.Write(p)
+	for {
+		line, err := t.pending.ReadBytes('\n')
+		if err != nil {
+			// Incomplete line: keep it until the rest arrives
+			t.pending.Reset()
+			t.pending.Write(line)
+			break
+		}
+		if err := t.writeLine(string(line)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (t *lineThrottle) writeLine(line string) error {
+	now := t.now()
+	// Blank lines separate output blocks and are never collapsed
+	if line == t.last && strings.TrimSpace(line) != "" && now.Sub(t.lastAt) <= t.window {
+		t.repeats++
+		t.lastAt = now
+		return nil
+	}
+	if err := t.flushRepeats(); err != nil {
+		return err
+	}
+	t.last = line
+	t.lastAt = now
+	_, err := io.WriteString(t.out, line)
+	return err
+}
+
+func (t *lineThrottle) flushRepeats() error {
+	if t.repeats == 0 {
+		return nil
+	}
+	n := t.repeats
+	t.repeats = 0
+	_, err := fmt.Fprintf(t.out, "%s (repeated %dx)\n", strings.TrimRight(t.last, "\n"), n)
+	return err
+}
+
+// Flush reports pending repeats and writes any trailing partial line
+func (t *lineThrottle) Flush() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.flushRepeats()
+	if t.pending.Len() > 0 {
+		io.WriteString(t.out, t.pending.String())
+		t.pending.Reset()
+	}
+	t.last = ""
+}
+
+// collapseMessages merges identical consecutive messages (same severity and
+// text) whose timestamps fall within window, appending "(repeated Nx)" to
+// the kept message. Messages without timestamps are treated as adjacent.
+func collapseMessages(msgs []Message, window time.Duration) []Message {
+	if len(msgs) < 2 {
+		return msgs
+	}
+	out := make([]Message, 0, len(msgs))
+	var base Message
+	var lastAt time.Time
+	repeats := 0
+	emit := func() {
+		if repeats > 0 {
+			base.Text = fmt.Sprintf("%s (repeated %dx)", base.Text, repeats)
+		}
+		out = append(out, base)
+	}
+	for i, m := range msgs {
+		if i > 0 && m.Severity == base.Severity && m.Code == base.Code && m.Text == base.Text && withinWindow(lastAt, m.Timestamp, window) {
+			repeats++
+			lastAt = m.Timestamp
+			continue
+		}
+		if i > 0 {
+			emit()
+		}
+		base, lastAt, repeats = m, m.Timestamp, 0
+	}
+	emit()
+	return out
+}
+
+func withinWindow(prev, next time.Time, window time.Duration) bool {
+	if prev.IsZero() || next.IsZero() {
+		return true
+	}
+	return next.Sub(prev) <= window
+}
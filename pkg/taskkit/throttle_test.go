@@ -0,0 +1,79 @@
+package taskkit
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLineThrottleCollapsesRepeats(t *testing.T) {
+	var out bytes.Buffer
+	clock := time.Unix(0, 0)
+	throttle := newLineThrottle(&out, time.Second)
+	throttle.now = func() time.Time { return clock }
+
+	for i := 0; i < 4; i++ {
+		throttle.Write([]byte("retrying\n"))
+		clock = clock.Add(100 * time.Millisecond)
+	}
+	throttle.Write([]byte("done\n"))
+	// A repeat after the window has lapsed is written again
+	throttle.Write([]byte("waiting\n"))
+	clock = clock.Add(2 * time.Second)
+	throttle.Write([]byte("waiting\n"))
+	throttle.Write([]byte("partial"))
+	throttle.Flush()
+
+	want := "retrying\nretrying (repeated 3x)\ndone\nwaiting\nwaiting\npartial"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestCollapseMessages(t *testing.T) {
+	at := time.Unix(100, 0)
+	msg := func(text string, offset time.Duration) Message {
+		return Message{Severity: SeverityWarning, Text: text, Timestamp: at.Add(offset)}
+	}
+	msgs := []Message{
+		msg("connection refused", 0),
+		msg("connection refused", time.Second),
+		msg("connection refused", 2*time.Second),
+		msg("connected", 3*time.Second),
+		msg("connected", time.Minute),
+	}
+
+	var got []string
+	for _, m := range collapseMessages(msgs, 5*time.Second) {
+		got = append(got, m.Text)
+	}
+	want := []string{"connection refused (repeated 2x)", "connected", "connected"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collapsed = %q, want %q", got, want)
+	}
+}
+
+func TestRunThrottlesStepMessages(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-poll": func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			for i := 0; i < 5; i++ {
+				result.AddInfo("still waiting", "poller")
+			}
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: throttle
+platform: test
+steps:
+  - name: poll
+`, LocalRunnerConfig{LogThrottle: time.Minute})
+	messages := stepByName(t, result, "poll").Messages
+	if len(messages) != 1 || !strings.HasSuffix(messages[0].Text, "still waiting (repeated 4x)") {
+		t.Errorf("messages = %+v, want one collapsed message", messages)
+	}
+}
@@ -0,0 +1,53 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+const traceWorkflow = `
+name: trace
+platform: test
+sensitive: [password]
+steps:
+  - name: configure
+    params:
+      region: eu-west
+      password: hunter2
+`
+
+func TestTraceDumpsInputAndResult(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-configure": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			result.SetVar("configured_region", input.GetParamString("region"))
+			result.Skip("nothing to change")
+			return result
+		},
+	})
+
+	_, out := runTestWorkflow(t, traceWorkflow, LocalRunnerConfig{Trace: true})
+	for _, want := range []string{
+		"[TRACE] input (attempt 1)",
+		`"region": "eu-west"`,
+		"[TRACE] result (attempt 1)",
+		`"configured_region": "eu-west"`,
+		`"skip_reason": "nothing to change"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("trace output leaks a sensitive param:\n%s", out)
+	}
+}
+
+func TestTraceOffByDefault(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{"test-configure": succeed})
+
+	_, out := runTestWorkflow(t, traceWorkflow, LocalRunnerConfig{Verbose: true})
+	if strings.Contains(out, "[TRACE]") {
+		t.Errorf("trace output without Trace:\n%s", out)
+	}
+}
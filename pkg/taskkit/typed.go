@@ -0,0 +1,115 @@
+package taskkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TypedHandler is a step handler that takes its params decoded into P and
+// returns its output as O
+type TypedHandler[P, O any] func(ctx context.Context, params P, deps Deps) (O, error)
+
+// RegisterTyped adds a typed handler to the global registry (see Typed).
+// When P is a struct its json field names are recorded as the params the
+// handler reads (see HandlerParams).
+// Panics if a handler with the same name is already registered.
+func RegisterTyped[P, O any](name string, fn TypedHandler[P, O]) {
+	Register(name, Typed(name, fn))
+
+	keys, ok := paramKeys(reflect.TypeOf((*P)(nil)).Elem())
+	if !ok {
+		return
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	handlerParams[name] = keys
+}
+
+// HandlerParams returns the params a handler is known to read, and false
+// when the handler did not declare them (e.g. a plain StepHandler), in
+// which case it may read any param
+func HandlerParams(name string) ([]string, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	keys, ok := handlerParams[name]
+	return keys, ok
+}
+
+// paramKeys returns the json names of a params struct's fields, including
+// those of embedded structs; other types have no fixed set of keys
+func paramKeys(t reflect.Type) ([]string, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			if embedded, ok := paramKeys(field.Type); ok {
+				keys = append(keys, embedded...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		keys = append(keys, tag)
+	}
+	return keys, true
+}
+
+// Typed adapts fn to a StepHandler. Params are decoded into P through JSON,
+// so struct fields use json tags; params that don't match P fail the step.
+// O must encode to a JSON object, which becomes StepResult.Output, and a
+// returned error becomes an error message.
+func Typed[P, O any](name string, fn TypedHandler[P, O]) StepHandler {
+	return func(input StepInput, deps Deps) StepResult {
+		result := NewStepResult()
+
+		var params P
+		data, err := json.Marshal(input.Params)
+		if err == nil {
+			err = json.Unmarshal(data, &params)
+		}
+		if err != nil {
+			result.AddError(fmt.Sprintf("Params do not match %T: %v", params, err), name)
+			return result
+		}
+
+		ctx := deps.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		out, err := fn(ctx, params, deps)
+		if err != nil {
+			result.AddError(err.Error(), name)
+			return result
+		}
+
+		data, err = json.Marshal(out)
+		if err == nil {
+			err = json.Unmarshal(data, &result.Output)
+		}
+		if err != nil {
+			result.AddError(fmt.Sprintf("Output %T does not encode to an object: %v", out, err), name)
+		}
+		if result.Output == nil {
+			result.Output = make(map[string]any)
+		}
+		return result
+	}
+}
@@ -0,0 +1,120 @@
+package taskkit
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type rolloutParams struct {
+	Image    string   `json:"image"`
+	Replicas int      `json:"replicas"`
+	Regions  []string `json:"regions"`
+}
+
+type rolloutOutput struct {
+	Deployed string `json:"deployed"`
+	Pods     int    `json:"pods"`
+}
+
+const typedWorkflow = `
+name: typed
+platform: test
+steps:
+  - name: deploy
+    params:
+      image: app:1.2
+      replicas: 3
+      regions: [us, eu]
+`
+
+func TestTypedHandlerDecodesParamsAndEncodesOutput(t *testing.T) {
+	var seen rolloutParams
+	registerHandlers(t, map[string]StepHandler{
+		"test-deploy": Typed("test-deploy", func(ctx context.Context, params rolloutParams, deps Deps) (rolloutOutput, error) {
+			if ctx == nil {
+				t.Error("typed handler got a nil context")
+			}
+			seen = params
+			return rolloutOutput{Deployed: params.Image, Pods: params.Replicas * len(params.Regions)}, nil
+		}),
+	})
+
+	result, _ := runTestWorkflow(t, typedWorkflow, LocalRunnerConfig{})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded", result.Result)
+	}
+	want := rolloutParams{Image: "app:1.2", Replicas: 3, Regions: []string{"us", "eu"}}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("params = %+v, want %+v", seen, want)
+	}
+	output := stepByName(t, result, "deploy").Output
+	if output["deployed"] != "app:1.2" || output["pods"] != float64(6) {
+		t.Errorf("output = %v, want deployed app:1.2 and 6 pods", output)
+	}
+}
+
+func TestTypedHandlerFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler StepHandler
+		message string
+	}{
+		{
+			"params mismatch",
+			Typed("test-deploy", func(_ context.Context, params struct {
+				Image int `json:"image"`
+			}, _ Deps) (rolloutOutput, error) {
+				t.Error("handler ran despite mismatched params")
+				return rolloutOutput{}, nil
+			}),
+			"Params do not match",
+		},
+		{
+			"returned error",
+			Typed("test-deploy", func(context.Context, rolloutParams, Deps) (rolloutOutput, error) {
+				return rolloutOutput{}, errors.New("registry unreachable")
+			}),
+			"registry unreachable",
+		},
+		{
+			"non-object output",
+			Typed("test-deploy", func(context.Context, rolloutParams, Deps) ([]string, error) {
+				return []string{"pod-1"}, nil
+			}),
+			"does not encode to an object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registerHandlers(t, map[string]StepHandler{"test-deploy": tt.handler})
+			result, _ := runTestWorkflow(t, typedWorkflow, LocalRunnerConfig{})
+			if result.Result != "Failed" {
+				t.Fatalf("result = %s, want Failed", result.Result)
+			}
+			step := stepByName(t, result, "deploy")
+			var found bool
+			for _, msg := range step.Messages {
+				found = found || (msg.Severity == SeverityError && strings.Contains(msg.Text, tt.message))
+			}
+			if !found {
+				t.Errorf("messages = %+v, want an error containing %q", step.Messages, tt.message)
+			}
+		})
+	}
+}
+
+func TestRegisterTyped(t *testing.T) {
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	RegisterTyped("test-deploy", func(_ context.Context, params rolloutParams, _ Deps) (rolloutOutput, error) {
+		return rolloutOutput{Deployed: params.Image}, nil
+	})
+
+	result, _ := runTestWorkflow(t, typedWorkflow, LocalRunnerConfig{})
+	if got := stepByName(t, result, "deploy").Output["deployed"]; got != "app:1.2" {
+		t.Errorf("deployed = %v, want app:1.2", got)
+	}
+}
@@ -0,0 +1,55 @@
+package taskkit
+
+import (
+	"strings"
+	"testing"
+)
+
+// loadError writes workflow to a temp dir and returns the LoadWorkflow error
+func loadError(t *testing.T, workflow string) error {
+	t.Helper()
+	_, err := LoadWorkflow(writeFile(t, t.TempDir(), "workflow.yaml", workflow))
+	return err
+}
+
+func TestValidateDuplicateStepNames(t *testing.T) {
+	err := loadError(t, `
+name: dupes
+platform: test
+steps:
+  - name: build
+  - name: deploy
+  - name: build
+  - name: deploy
+  - name: build
+`)
+	if err == nil || !strings.Contains(err.Error(), "duplicate step names: build, deploy") {
+		t.Errorf("error = %v, want duplicate step names listed once each", err)
+	}
+}
+
+func TestValidateStepNames(t *testing.T) {
+	tests := []struct {
+		name string
+		step string
+		want string
+	}{
+		{"empty", `""`, "step 2 has an empty name"},
+		{"blank", `"  "`, "step 2 has an empty name"},
+		{"reserved prefix", "__audit", `step "__audit" uses the reserved prefix "__"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loadError(t, `
+name: names
+platform: test
+steps:
+  - name: build
+  - name: `+tt.step+`
+`)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package taskkit
+
+import "testing"
+
+// fakeClient stands in for a dependency an embedding program injects
+type fakeClient struct{ calls int }
+
+func (c *fakeClient) Get(string) string {
+	c.calls++
+	return "ok"
+}
+
+func TestValuesReachHandlers(t *testing.T) {
+	client := &fakeClient{}
+	var status string
+	registerHandlers(t, map[string]StepHandler{
+		"test-probe": func(input StepInput, deps Deps) StepResult {
+			result := NewStepResult()
+			v, ok := deps.Value("http")
+			if !ok {
+				result.AddError("no http client injected", "test")
+				return result
+			}
+			status = v.(*fakeClient).Get("http://nas.local/health")
+			if _, ok := deps.Value("db"); ok {
+				result.AddError("unexpected db value", "test")
+			}
+			return result
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: values
+platform: test
+steps:
+  - name: probe
+`, LocalRunnerConfig{Values: map[string]any{"http": client}})
+	if result.Result != "Succeeded" {
+		t.Fatalf("result = %s, want Succeeded: %+v", result.Result, stepByName(t, result, "probe").Messages)
+	}
+	if status != "ok" || client.calls != 1 {
+		t.Errorf("status = %q after %d calls, want the injected client used once", status, client.calls)
+	}
+}
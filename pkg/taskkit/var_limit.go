@@ -0,0 +1,52 @@
+package taskkit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// limitVars enforces MaxVars on the vars about to be saved, pruning first
+// when PruneVars is set. A remaining excess is added to the result's
+// warnings, or returned as an error under StrictVars.
+func (r *LocalRunner) limitVars(result *ExecutionResult) error {
+	limit := r.config.MaxVars
+	if r.vars.Len() <= limit {
+		return nil
+	}
+
+	if r.config.PruneVars {
+		pruned := r.prunableVars()
+		for _, k := range pruned {
+			r.vars.Delete(k)
+		}
+		if len(pruned) > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("pruned %d vars over max vars (%d): %s", len(pruned), limit, strings.Join(pruned, ", ")))
+		}
+		if r.vars.Len() <= limit {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("%d vars exceed max vars (%d)", r.vars.Len(), limit)
+	if r.config.StrictVars {
+		return fmt.Errorf("%s; vars not saved", msg)
+	}
+	result.Warnings = append(result.Warnings, msg)
+	return nil
+}
+
+// prunableVars returns the reserved and stale var keys, sorted
+func (r *LocalRunner) prunableVars() []string {
+	var keys []string
+	for k := range r.vars.Snapshot() {
+		_, declared := r.workflow.Vars[k]
+		_, set := r.config.SetVars[k]
+		stale := !declared && !set && !r.usedVars[k]
+		if strings.HasPrefix(k, ReservedPrefix) || stale {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package taskkit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const varLimitWorkflow = `
+name: var-limit
+platform: test
+vars:
+  region: us-east
+steps:
+  - name: build
+`
+
+// savedVars reads the vars.yaml saved in workdir
+func savedVars(t *testing.T, workdir string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(workdir, "vars.yaml"))
+	if err != nil {
+		t.Fatalf("vars.yaml: %v", err)
+	}
+	vars := make(map[string]any)
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		t.Fatalf("vars.yaml: %v", err)
+	}
+	return vars
+}
+
+func TestMaxVars(t *testing.T) {
+	build := func(StepInput, Deps) StepResult {
+		result := NewStepResult()
+		result.SetVar("image", "app:v1")
+		return result
+	}
+
+	t.Run("warns and saves", func(t *testing.T) {
+		registerHandlers(t, map[string]StepHandler{"test-build": build})
+		workdir := t.TempDir()
+		writeFile(t, workdir, "vars.yaml", "old_a: 1\nold_b: 2\n")
+
+		result, _ := runTestWorkflow(t, varLimitWorkflow, LocalRunnerConfig{Workdir: workdir, MaxVars: 2})
+		if result.Result != "Succeeded" {
+			t.Fatalf("result = %s, want Succeeded", result.Result)
+		}
+		if len(result.Warnings) != 1 || result.Warnings[0] != "4 vars exceed max vars (2)" {
+			t.Errorf("warnings = %q, want one about 4 vars over the limit", result.Warnings)
+		}
+		if vars := savedVars(t, workdir); len(vars) != 4 || vars["image"] != "app:v1" {
+			t.Errorf("saved vars = %v, want all 4 saved", vars)
+		}
+	})
+
+	t.Run("strict fails without saving", func(t *testing.T) {
+		registerHandlers(t, map[string]StepHandler{"test-build": build})
+		workdir := t.TempDir()
+
+		result, _ := runTestWorkflow(t, varLimitWorkflow, LocalRunnerConfig{Workdir: workdir, MaxVars: 1, StrictVars: true})
+		if result.Result != "Failed" || !strings.Contains(result.ErrorMessage, "2 vars exceed max vars (1); vars not saved") {
+			t.Errorf("result = %s (%s), want Failed over the limit", result.Result, result.ErrorMessage)
+		}
+		if _, err := os.Stat(filepath.Join(workdir, "vars.yaml")); !os.IsNotExist(err) {
+			t.Errorf("vars.yaml was saved (%v), want none", err)
+		}
+	})
+
+	t.Run("prunes reserved and stale vars", func(t *testing.T) {
+		registerHandlers(t, map[string]StepHandler{"test-build": build})
+		workdir := t.TempDir()
+		writeFile(t, workdir, "vars.yaml", "old_a: 1\n__internal: x\n")
+
+		result, _ := runTestWorkflow(t, varLimitWorkflow, LocalRunnerConfig{Workdir: workdir, MaxVars: 2, PruneVars: true})
+		if result.Result != "Succeeded" {
+			t.Fatalf("result = %s, want Succeeded", result.Result)
+		}
+		want := "pruned 2 vars over max vars (2): __internal, old_a"
+		if len(result.Warnings) != 1 || result.Warnings[0] != want {
+			t.Errorf("warnings = %q, want %q", result.Warnings, want)
+		}
+		// The declared var and the one set this run are kept
+		vars := savedVars(t, workdir)
+		if len(vars) != 2 || vars["region"] != "us-east" || vars["image"] != "app:v1" {
+			t.Errorf("saved vars = %v, want only region and image", vars)
+		}
+	})
+}
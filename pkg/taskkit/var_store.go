@@ -0,0 +1,60 @@
+package taskkit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VarStore persists workflow vars between runs
+type VarStore interface {
+	// Load returns the persisted vars, or an empty map if none exist yet
+	Load() (map[string]any, error)
+	// Save replaces the persisted vars
+	Save(vars map[string]any) error
+}
+
+// FileVarStore persists vars as YAML in a local file (the default vars.yaml)
+type FileVarStore struct {
+	Path string
+}
+
+// NewFileVarStore creates a file-backed VarStore
+func NewFileVarStore(path string) *FileVarStore {
+	return &FileVarStore{Path: path}
+}
+
+// ErrMalformedVars is wrapped by Load errors for a vars file that exists but
+// cannot be parsed
+var ErrMalformedVars = errors.New("malformed vars file")
+
+// Load reads vars from the file. A missing file yields empty vars; a
+// malformed file yields empty vars and an error wrapping ErrMalformedVars.
+func (s *FileVarStore) Load() (map[string]any, error) {
+	vars := make(map[string]any)
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return vars, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return make(map[string]any), fmt.Errorf("%w %s: %v", ErrMalformedVars, s.Path, err)
+	}
+	return vars, nil
+}
+
+// Save writes vars to the file as YAML
+func (s *FileVarStore) Save(vars map[string]any) error {
+	data, err := yaml.Marshal(vars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal vars: %w", err)
+	}
+	if err := os.WriteFile(s.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write vars: %w", err)
+	}
+	return nil
+}
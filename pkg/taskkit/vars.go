@@ -0,0 +1,75 @@
+package taskkit
+
+import "sync"
+
+// SyncVars is a concurrency-safe set of workflow variables. Steps receive
+// an immutable snapshot as StepInput.Vars while context updates are written
+// back through Set, so concurrently executing steps never share a map.
+type SyncVars struct {
+	mu   sync.RWMutex
+	vars map[string]any
+}
+
+// NewSyncVars creates a SyncVars seeded with a copy of initial
+func NewSyncVars(initial map[string]any) *SyncVars {
+	v := &SyncVars{vars: make(map[string]any, len(initial))}
+	for k, val := range initial {
+		v.vars[k] = val
+	}
+	return v
+}
+
+// Get returns the value for key and whether it was set
+func (v *SyncVars) Get(key string) (any, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	val, ok := v.vars[key]
+	return val, ok
+}
+
+// Set stores a value for key
+func (v *SyncVars) Set(key string, value any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.vars[key] = value
+}
+
+// Update replaces the value for key with fn's result, atomically. ok is
+// false when key is not set.
+func (v *SyncVars) Update(key string, fn func(current any, ok bool) any) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	current, ok := v.vars[key]
+	v.vars[key] = fn(current, ok)
+}
+
+// Delete removes key
+func (v *SyncVars) Delete(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	delete(v.vars, key)
+}
+
+// Len returns the number of vars
+func (v *SyncVars) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return len(v.vars)
+}
+
+// Snapshot returns a copy of all vars that is safe to read without locking
+func (v *SyncVars) Snapshot() map[string]any {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	snap := make(map[string]any, len(v.vars))
+	for k, val := range v.vars {
+		snap[k] = val
+	}
+	return snap
+}
@@ -0,0 +1,50 @@
+package taskkit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVarsSnapshotPerStep(t *testing.T) {
+	setVars := func(vars map[string]any) StepHandler {
+		return func(StepInput, Deps) StepResult {
+			result := NewStepResult()
+			for k, v := range vars {
+				result.SetVar(k, v)
+			}
+			return result
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-build":  setVars(map[string]any{"image": "app:1.2"}),
+		"test-check":  setVars(nil),
+		"test-deploy": setVars(map[string]any{"image": "app:1.3", "replicas": 3}),
+	})
+	const workflow = `
+name: snapshots
+platform: test
+steps:
+  - name: build
+  - name: check
+  - name: deploy
+`
+
+	result, _ := runTestWorkflow(t, workflow, LocalRunnerConfig{SnapshotVars: true, SetVars: map[string]any{"env": "prod"}})
+	want := map[string]map[string]any{
+		"build":  {"env": "prod", "image": "app:1.2"},
+		"check":  {"env": "prod", "image": "app:1.2"},
+		"deploy": {"env": "prod", "image": "app:1.3", "replicas": 3},
+	}
+	for name, vars := range want {
+		if got := stepByName(t, result, name).VarsSnapshot; !reflect.DeepEqual(got, vars) {
+			t.Errorf("%s snapshot = %v, want %v", name, got, vars)
+		}
+	}
+
+	result, _ = runTestWorkflow(t, workflow, LocalRunnerConfig{})
+	for _, step := range result.Steps {
+		if step.VarsSnapshot != nil {
+			t.Errorf("%s snapshot = %v without SnapshotVars, want none", step.Name, step.VarsSnapshot)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package taskkit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestSyncVarsParallelSteps simulates parallel steps that each read a
+// snapshot and write distinct keys; run with -race to catch data races
+func TestSyncVarsParallelSteps(t *testing.T) {
+	vars := NewSyncVars(map[string]any{"shared": "initial"})
+
+	const steps, updates = 32, 50
+	var wg sync.WaitGroup
+	for s := 0; s < steps; s++ {
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for u := 0; u < updates; u++ {
+				input := StepInput{Vars: vars.Snapshot()}
+				if input.GetVar("shared") != "initial" {
+					t.Errorf("step %d: shared = %v", s, input.GetVar("shared"))
+				}
+				// Handlers may scribble on their snapshot without affecting others
+				input.Vars["scratch"] = s
+				vars.Set(fmt.Sprintf("step%d.count", s), u+1)
+				vars.Update("total", func(current any, ok bool) any {
+					n, _ := current.(int)
+					return n + 1
+				})
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	final := vars.Snapshot()
+	for s := 0; s < steps; s++ {
+		if got := final[fmt.Sprintf("step%d.count", s)]; got != updates {
+			t.Errorf("step%d.count = %v, want %d", s, got, updates)
+		}
+	}
+	if got := final["total"]; got != steps*updates {
+		t.Errorf("total = %v, want %d", got, steps*updates)
+	}
+	if _, ok := vars.Get("scratch"); ok {
+		t.Error("a snapshot write leaked into the shared vars")
+	}
+	if got, want := vars.Len(), steps+2; got != want {
+		t.Errorf("Len = %d, want %d", got, want)
+	}
+}
+
+func TestSyncVarsSnapshotIsolation(t *testing.T) {
+	initial := map[string]any{"a": 1}
+	vars := NewSyncVars(initial)
+	initial["a"] = 2
+
+	snap := vars.Snapshot()
+	vars.Set("a", 3)
+	vars.Delete("missing")
+
+	if snap["a"] != 1 {
+		t.Errorf("snapshot a = %v, want 1", snap["a"])
+	}
+	if got, _ := vars.Get("a"); got != 3 {
+		t.Errorf("a = %v, want 3", got)
+	}
+}
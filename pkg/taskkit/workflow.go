@@ -1,8 +1,13 @@
 package taskkit
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,24 +21,216 @@ const (
 	TemplateFinalize StepTemplate = "finalize"
 )
 
+// CurrentSchemaVersion is the workflow schema version written by this release
+const CurrentSchemaVersion = 1
+
+// schemaMigrations upgrade a workflow from the keyed version to the next
+// one. Versions between the oldest migration and CurrentSchemaVersion are
+// supported; anything else is rejected.
+var schemaMigrations = map[int]func(*WorkflowDefinition) error{}
+
+// ReservedPrefix marks names reserved for taskkit's internal vars and steps
+const ReservedPrefix = "__"
+
 // WorkflowStep defines a single step in a workflow
 type WorkflowStep struct {
-	Name     string         `yaml:"name"`
-	Depends  []string       `yaml:"depends,omitempty"`
-	Template StepTemplate   `yaml:"template,omitempty"`
-	Params   map[string]any `yaml:"params,omitempty"`
-	Retries  int            `yaml:"retries,omitempty"`
+	Name     string       `yaml:"name"`
+	Depends  []string     `yaml:"depends,omitempty"`
+	Template StepTemplate `yaml:"template,omitempty"`
+
+	// Params are passed to the handler. A string value may include an
+	// earlier step's output as {{ steps.<name>.output.<key> }}; that is the
+	// only form rendered, so any other action over steps (a pipeline,
+	// function, or conditional) fails validation.
+	Params  map[string]any `yaml:"params,omitempty"`
+	Retries int            `yaml:"retries,omitempty"`
+
+	// Description says what the step does, for readers and `workflow lint`
+	Description string `yaml:"description,omitempty"`
+
+	// Handler names the registered handler explicitly, bypassing the
+	// prefix-stepname convention (useful for shared handlers)
+	Handler string `yaml:"handler,omitempty"`
+
+	// RetryBackoff overrides the workflow's retry backoff for this step
+	RetryBackoff *BackoffConfig `yaml:"retry_backoff,omitempty"`
+
+	// IfStepStatus runs the step only if each named step finished with the
+	// given status (e.g. {build: Failed}); otherwise the step is skipped
+	IfStepStatus map[string]string `yaml:"if_step_status,omitempty"`
+
+	// Env is passed to the handler via Deps.Env, overriding workflow env
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// SoftDepends orders this step after the named steps when they are
+	// present, without requiring them to exist
+	SoftDepends []string `yaml:"soft_depends,omitempty"`
+
+	// Memoize caches a successful result in the workdir keyed by a hash of
+	// the handler, merged params, and the vars named in MemoizeVars, and
+	// reuses it on a match. A cache_key param takes precedence: the result is
+	// then cached under that key whether or not memoize is set.
+	Memoize bool `yaml:"memoize,omitempty"`
+
+	// MemoizeVars names the vars a memoized step depends on. Other vars,
+	// such as a run's start time, do not affect the cache key.
+	MemoizeVars []string `yaml:"memoize_vars,omitempty"`
+
+	// When is a condition over vars and params (see ParseCondition); the
+	// step is skipped when it evaluates to false
+	When string `yaml:"when,omitempty"`
+
+	// Tags label the step for --tags/--skip-tags filtering
+	Tags []string `yaml:"tags,omitempty"`
+
+	// Priority orders steps that are ready at the same time: higher runs
+	// first (e.g. long-running steps), equal priorities keep declaration
+	// order
+	Priority int `yaml:"priority,omitempty"`
+
+	// Disabled skips the step, and any step that depends on it, without
+	// removing it from the workflow
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// RetryParams override Params on retry attempts (Attempt > 1), e.g. to
+	// use a longer timeout; --param overrides still take precedence
+	RetryParams map[string]any `yaml:"retry_params,omitempty"`
+
+	// TimeoutSeconds fails an attempt that runs longer than this, and
+	// defaults to the workflow's timeout_seconds. The handler's
+	// Deps.Context is cancelled at the deadline.
+	TimeoutSeconds int `yaml:"timeout_seconds,omitempty"`
+
+	// Pre and Post are shell commands run in the workdir before and after
+	// the handler (e.g. "echo starting"); Post runs even if the handler
+	// failed. Hook failures are warnings unless FailOnHookError is set.
+	Pre             string `yaml:"pre,omitempty"`
+	Post            string `yaml:"post,omitempty"`
+	FailOnHookError bool   `yaml:"fail_on_hook_error,omitempty"`
+
+	// RetryBudgetSeconds caps the total time spent on a step across all
+	// attempts and backoff delays; no retry starts once it is used up
+	RetryBudgetSeconds int `yaml:"retry_budget_seconds,omitempty"`
+
+	// PromoteOutputs copies the step's outputs into vars as
+	// "<step>.<key>" (e.g. GetVar("build.image")) for later steps
+	PromoteOutputs bool `yaml:"promote_outputs,omitempty"`
+
+	// TimeoutGrowth extends the timeout on each retry: attempt n gets
+	// timeout_seconds * (1 + growth*(n-1)), so 1 gives base*attempt
+	TimeoutGrowth float64 `yaml:"timeout_growth,omitempty"`
+
+	// MaxTimeoutSeconds caps the grown timeout (0 means no cap)
+	MaxTimeoutSeconds int `yaml:"max_timeout_seconds,omitempty"`
+
+	// ExpectedSeconds is how long the step usually takes. A step running
+	// longer than this times LocalRunnerConfig.SlowStepFactor gets a
+	// warning and StepExec.SlowerThanExpected, but does not fail.
+	ExpectedSeconds float64 `yaml:"expected_seconds,omitempty"`
+
+	// Stage groups the step with others for reporting (e.g. build,
+	// verify, deploy); dependencies still decide the order
+	Stage string `yaml:"stage,omitempty"`
+
+	// Use instantiates the named entry of the workflow's templates, with
+	// With merged over the template's params (see WorkflowDefinition.Templates)
+	Use  string         `yaml:"use,omitempty"`
+	With map[string]any `yaml:"with,omitempty"`
 }
 
 // WorkflowDefinition is the parsed workflow YAML
 type WorkflowDefinition struct {
+	SchemaVersion  int            `yaml:"schema_version,omitempty"`
 	Name           string         `yaml:"name"`
 	Description    string         `yaml:"description,omitempty"`
 	Platform       string         `yaml:"platform"`
 	HandlerPrefix  string         `yaml:"handler_prefix,omitempty"`
 	Steps          []WorkflowStep `yaml:"steps"`
 	DefaultRetries int            `yaml:"default_retries,omitempty"`
-	TimeoutSeconds int            `yaml:"timeout_seconds,omitempty"`
+	RetryBackoff   *BackoffConfig `yaml:"retry_backoff,omitempty"`
+	// TimeoutSeconds is the per-attempt timeout for steps that set no
+	// timeout_seconds of their own, and for the on_step_give_up handler
+	TimeoutSeconds int      `yaml:"timeout_seconds,omitempty"`
+	RequiredEnv    []string `yaml:"required_env,omitempty"`
+
+	// Env is the base environment passed to every step's handler
+	Env map[string]string `yaml:"env,omitempty"`
+
+	// DefaultParams apply to every step. Params are merged lowest first:
+	// env fallback (TASKKIT_PARAM_*), default_params, the params file,
+	// step params, then --param overrides.
+	DefaultParams map[string]any `yaml:"default_params,omitempty"`
+
+	// Vars are initial workflow variables available to all steps
+	Vars map[string]any `yaml:"vars,omitempty"`
+
+	// VarReducers names a reducer (append, sum, or one added with
+	// RegisterReducer) per var key; context updates to those keys are
+	// combined with the current value instead of replacing it. Values
+	// persisted in vars.yaml by earlier runs are the starting point.
+	VarReducers map[string]string `yaml:"var_reducers,omitempty"`
+
+	// Sensitive lists param/output/var keys whose values are redacted in
+	// execution-result.json, recorded inputs, and console output
+	Sensitive []string `yaml:"sensitive,omitempty"`
+
+	// FailurePolicy controls what happens to remaining steps after a
+	// failure when a finalize step exists: "continue" (default) runs them,
+	// "skip-to-finalize" records them as Skipped and goes to finalize
+	FailurePolicy string `yaml:"failure_policy,omitempty"`
+
+	// Outputs exposes step outputs as workflow outputs, mapping an output
+	// name to a "step.outputKey" reference (nested keys may be dotted)
+	Outputs map[string]string `yaml:"outputs,omitempty"`
+
+	// Destructive workflows only run when confirmed with --confirm or
+	// TASKKIT_CONFIRM=1
+	Destructive bool `yaml:"destructive,omitempty"`
+
+	// Extends names a base workflow file (relative to this one) whose
+	// steps and settings this workflow inherits and overrides by name
+	Extends string `yaml:"extends,omitempty"`
+
+	// OnStepGiveUp names a handler to run whenever a step fails on every
+	// attempt. It runs as GiveUpStepName with the failed step's name,
+	// handler, messages, error, failure_kind, and attempts as params.
+	OnStepGiveUp string `yaml:"on_step_give_up,omitempty"`
+
+	// TokenDelimiters replace the "${" and "}" around interpolation tokens
+	// such as ${ENV:NAME}; see TokenDelimiters for escaping
+	TokenDelimiters TokenDelimiters `yaml:"token_delimiters,omitempty"`
+
+	// HandlerConfig holds per-handler configuration beyond params (e.g. an
+	// endpoint map), keyed by handler name and read through
+	// Deps.HandlerConfig. An entry may instead name a YAML file holding
+	// the map, relative to the workflow file.
+	HandlerConfig map[string]any `yaml:"handler_config,omitempty"`
+
+	// Templates are reusable step blueprints, instantiated by steps with
+	// use and with. Steps are expanded when the workflow is loaded; a
+	// step's own fields override the template's.
+	Templates map[string]WorkflowStep `yaml:"templates,omitempty"`
+
+	// Hash is the hex SHA-256 of the raw workflow file, set by LoadWorkflow
+	Hash string `yaml:"-"`
+
+	// tokenSyntax is built from TokenDelimiters by Validate
+	tokenSyntax *tokenSyntax
+}
+
+// Failure policies
+const (
+	FailurePolicyContinue       = "continue"
+	FailurePolicySkipToFinalize = "skip-to-finalize"
+)
+
+// ValidateFailurePolicy reports an error for an unknown failure policy
+func ValidateFailurePolicy(policy string) error {
+	switch policy {
+	case "", FailurePolicyContinue, FailurePolicySkipToFinalize:
+		return nil
+	}
+	return fmt.Errorf("unknown failure policy %q (want %s or %s)", policy, FailurePolicyContinue, FailurePolicySkipToFinalize)
 }
 
 // LoadWorkflow reads and parses a workflow YAML file
@@ -47,20 +244,313 @@ func LoadWorkflow(path string) (*WorkflowDefinition, error) {
 	if err := yaml.Unmarshal(data, &wf); err != nil {
 		return nil, fmt.Errorf("failed to parse workflow YAML: %w", err)
 	}
+	parsed := data
 
-	// Validate
-	if wf.Name == "" {
-		return nil, fmt.Errorf("workflow name is required")
+	// Specialize a base workflow; the hash then covers every file in the chain
+	if wf.Extends != "" {
+		merged, sources, err := resolveExtends(path)
+		if err != nil {
+			return nil, err
+		}
+		extends := wf.Extends
+		wf = WorkflowDefinition{}
+		if err := yaml.Unmarshal(merged, &wf); err != nil {
+			return nil, fmt.Errorf("failed to parse extended workflow: %w", err)
+		}
+		wf.Extends = extends
+		data = sources
+		parsed = merged
 	}
-	if len(wf.Steps) == 0 {
-		return nil, fmt.Errorf("workflow must have at least one step")
+
+	// Instantiate step templates before validation and ordering
+	if wf.usesTemplates() {
+		expanded, err := expandStepTemplates(parsed)
+		if err != nil {
+			return nil, err
+		}
+		extends := wf.Extends
+		wf = WorkflowDefinition{}
+		if err := yaml.Unmarshal(expanded, &wf); err != nil {
+			return nil, fmt.Errorf("failed to parse expanded workflow: %w", err)
+		}
+		wf.Extends = extends
 	}
 
+	if err := wf.migrate(); err != nil {
+		return nil, err
+	}
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	// The hash also covers handler config files
+	sidecars, err := wf.loadHandlerConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(append(data, sidecars...))
+	wf.Hash = hex.EncodeToString(sum[:])
+
 	return &wf, nil
 }
 
+// migrate upgrades the workflow to CurrentSchemaVersion. A missing version
+// is treated as the current one for backward compatibility.
+func (w *WorkflowDefinition) migrate() error {
+	if w.SchemaVersion == 0 {
+		w.SchemaVersion = CurrentSchemaVersion
+	}
+	if w.SchemaVersion > CurrentSchemaVersion {
+		return fmt.Errorf("unsupported schema_version %d: this taskkit supports up to %d", w.SchemaVersion, CurrentSchemaVersion)
+	}
+	for w.SchemaVersion < CurrentSchemaVersion {
+		upgrade, ok := schemaMigrations[w.SchemaVersion]
+		if !ok {
+			return fmt.Errorf("unsupported schema_version %d: no migration to %d", w.SchemaVersion, CurrentSchemaVersion)
+		}
+		if err := upgrade(w); err != nil {
+			return fmt.Errorf("failed to migrate schema_version %d: %w", w.SchemaVersion, err)
+		}
+		w.SchemaVersion++
+	}
+	return nil
+}
+
+// Validate checks the workflow definition for structural errors
+func (w *WorkflowDefinition) Validate() error {
+	if w.Name == "" {
+		return fmt.Errorf("workflow name is required")
+	}
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow must have at least one step")
+	}
+	w.tokenSyntax = newTokenSyntax(w.TokenDelimiters)
+
+	for i, step := range w.Steps {
+		if strings.TrimSpace(step.Name) == "" {
+			return fmt.Errorf("step %d has an empty name", i+1)
+		}
+		if strings.HasPrefix(step.Name, ReservedPrefix) {
+			return fmt.Errorf("step %q uses the reserved prefix %q", step.Name, ReservedPrefix)
+		}
+	}
+
+	// Step names key the execution order, so duplicates would silently
+	// collapse into one step
+	seen := make(map[string]int)
+	var duplicates []string
+	for _, step := range w.Steps {
+		seen[step.Name]++
+		if seen[step.Name] == 2 {
+			duplicates = append(duplicates, step.Name)
+		}
+	}
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate step names: %s", strings.Join(duplicates, ", "))
+	}
+
+	for _, key := range sortedKeys(w.VarReducers) {
+		if _, ok := GetReducer(w.VarReducers[key]); !ok {
+			return fmt.Errorf("var %q has unknown reducer %q (available: %s)", key, w.VarReducers[key], strings.Join(ListReducers(), ", "))
+		}
+	}
+
+	if err := ValidateFailurePolicy(w.FailurePolicy); err != nil {
+		return err
+	}
+	if w.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	if w.RetryBackoff != nil {
+		if err := w.RetryBackoff.Validate(); err != nil {
+			return fmt.Errorf("invalid retry_backoff: %w", err)
+		}
+	}
+	for name, ref := range w.Outputs {
+		stepName, _, ok := strings.Cut(ref, ".")
+		if !ok || stepName == "" {
+			return fmt.Errorf("output %q: reference %q must be step.outputKey", name, ref)
+		}
+		if !w.hasStep(stepName) {
+			return fmt.Errorf("output %q references unknown step %q", name, stepName)
+		}
+	}
+	if err := checkStepTemplates(w.DefaultParams); err != nil {
+		return fmt.Errorf("default_params: %w", err)
+	}
+	for _, step := range w.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+		if step.RetryBackoff != nil {
+			if err := step.RetryBackoff.Validate(); err != nil {
+				return fmt.Errorf("step %q has invalid retry_backoff: %w", step.Name, err)
+			}
+		}
+		if step.When != "" {
+			if _, err := ParseCondition(step.When); err != nil {
+				return fmt.Errorf("step %q: %w", step.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks a step's fields after YAML anchors, aliases, and merge
+// keys have been expanded, so a step that inherited a bad value from a
+// shared anchor is reported under its own name
+func (s WorkflowStep) validate() error {
+	switch s.Template {
+	case "", TemplateInit, TemplateAction, TemplateFinalize:
+	default:
+		return fmt.Errorf("unknown template %q (want init, action, or finalize)", s.Template)
+	}
+	if s.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if s.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeout_seconds must not be negative")
+	}
+	if s.TimeoutGrowth < 0 {
+		return fmt.Errorf("timeout_growth must not be negative")
+	}
+	if s.MaxTimeoutSeconds < 0 {
+		return fmt.Errorf("max_timeout_seconds must not be negative")
+	}
+	if s.RetryBudgetSeconds < 0 {
+		return fmt.Errorf("retry_budget_seconds must not be negative")
+	}
+	if s.ExpectedSeconds < 0 {
+		return fmt.Errorf("expected_seconds must not be negative")
+	}
+	for _, dep := range s.Depends {
+		if strings.TrimSpace(dep) == "" {
+			return fmt.Errorf("depends contains an empty step name")
+		}
+		if dep == s.Name {
+			return fmt.Errorf("step depends on itself")
+		}
+	}
+	for key := range s.Params {
+		if key == "" {
+			return fmt.Errorf("params contains an empty key")
+		}
+		if key == "<<" {
+			return fmt.Errorf("params contains an unresolved merge key")
+		}
+	}
+	for _, params := range []map[string]any{s.Params, s.RetryParams} {
+		if err := checkStepTemplates(params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MissingEnv returns the required environment variables that are unset or empty
+func (w *WorkflowDefinition) MissingEnv() []string {
+	var missing []string
+	for _, name := range w.RequiredEnv {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// tokens returns the interpolation token syntax for the workflow's
+// delimiters
+func (w *WorkflowDefinition) tokens() *tokenSyntax {
+	if w.tokenSyntax != nil {
+		return w.tokenSyntax
+	}
+	if w.TokenDelimiters == (TokenDelimiters{}) {
+		return defaultTokens
+	}
+	return newTokenSyntax(w.TokenDelimiters)
+}
+
+// GetEnv returns the step environment: workflow env overlaid with step env,
+// with ${ENV:NAME} tokens resolved from the process environment and
+// ${FILE:path} tokens from files. Each value read from a file is passed to
+// secret (if non-nil), as with params, so the caller can redact it.
+func (w *WorkflowDefinition) GetEnv(step WorkflowStep, secret func(string)) (map[string]string, error) {
+	env := make(map[string]string, len(w.Env)+len(step.Env))
+	for k, v := range w.Env {
+		env[k] = v
+	}
+	for k, v := range step.Env {
+		env[k] = v
+	}
+	for k, v := range env {
+		resolved, err := w.tokens().expand(v, secret)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		env[k] = resolved
+	}
+	return env, nil
+}
+
+// layerParams merges params in precedence order, lowest first: env
+// fallback, default_params, the params file, the step's params, then
+// overrides. Runs and plans both merge through it so they agree.
+func (w *WorkflowDefinition) layerParams(env, file, step, overrides map[string]any) map[string]any {
+	merged := make(map[string]any)
+	for _, layer := range []map[string]any{env, w.DefaultParams, file, step, overrides} {
+		for k, v := range layer {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// hasStep reports whether the workflow declares a step with the given name
+func (w *WorkflowDefinition) hasStep(name string) bool {
+	for _, step := range w.Steps {
+		if step.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveOutputs maps the workflow's declared outputs to values recorded in
+// the executed steps. It returns the outputs it could resolve and an error
+// listing any that are missing.
+func (w *WorkflowDefinition) ResolveOutputs(steps []StepExec) (map[string]any, error) {
+	if len(w.Outputs) == 0 {
+		return nil, nil
+	}
+
+	stepOutputs := make(map[string]any, len(steps))
+	for _, step := range steps {
+		stepOutputs[step.Name] = step.Output
+	}
+
+	outputs := make(map[string]any, len(w.Outputs))
+	var missing []string
+	for _, name := range sortedKeys(w.Outputs) {
+		ref := w.Outputs[name]
+		stepName, key, _ := strings.Cut(ref, ".")
+		v, ok := lookupPath(stepOutputs[stepName], splitPath(key))
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s (%s)", name, ref))
+			continue
+		}
+		outputs[name] = v
+	}
+	if len(missing) > 0 {
+		return outputs, fmt.Errorf("missing workflow outputs: %s", strings.Join(missing, ", "))
+	}
+	return outputs, nil
+}
+
 // GetHandlerName returns the full handler name for a step
 func (w *WorkflowDefinition) GetHandlerName(step WorkflowStep) string {
+	// An explicit handler always wins
+	if step.Handler != "" {
+		return step.Handler
+	}
 	// If handler_prefix is set, use prefix-stepname
 	if w.HandlerPrefix != "" {
 		return fmt.Sprintf("%s-%s", w.HandlerPrefix, step.Name)
@@ -69,8 +559,80 @@ func (w *WorkflowDefinition) GetHandlerName(step WorkflowStep) string {
 	return fmt.Sprintf("%s-%s", w.Platform, step.Name)
 }
 
+// ResolveHandlers checks the handler of every enabled step, and the
+// on_step_give_up handler, against the registry and returns the handler
+// names that were found and those that are missing, each once in step
+// order. Disabled steps never run, so their handlers are not checked.
+func (w *WorkflowDefinition) ResolveHandlers() ([]string, []string) {
+	var found, missing []string
+	seen := make(map[string]bool)
+	check := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		if _, ok := Get(name); ok {
+			found = append(found, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	for _, step := range w.Steps {
+		if !step.Disabled {
+			check(w.GetHandlerName(step))
+		}
+	}
+	if w.OnStepGiveUp != "" {
+		check(w.OnStepGiveUp)
+	}
+	return found, missing
+}
+
+// HandlerSuggestion suggests a registered handler for a step whose handler
+// is missing: a near-miss spelling, or failing that, the step's name
+// registered under a different prefix. It returns "" if nothing is close.
+func (w *WorkflowDefinition) HandlerSuggestion(step WorkflowStep) string {
+	name := w.GetHandlerName(step)
+	if suggestion := SuggestHandler(name); suggestion != "" {
+		return suggestion
+	}
+	if step.Handler != "" {
+		return ""
+	}
+	for _, candidate := range ListHandlers() {
+		if strings.HasSuffix(candidate, "-"+step.Name) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// describeMissing annotates handler names returned as missing by
+// ResolveHandlers with a "did you mean" hint where one is available
+func (w *WorkflowDefinition) describeMissing(missing []string) []string {
+	described := make([]string, 0, len(missing))
+	for _, name := range missing {
+		desc := name
+		if name == w.OnStepGiveUp {
+			desc += " (on_step_give_up)"
+		}
+		for _, step := range w.Steps {
+			if step.Disabled || w.GetHandlerName(step) != name {
+				continue
+			}
+			if suggestion := w.HandlerSuggestion(step); suggestion != "" {
+				desc = fmt.Sprintf("%s (did you mean %s?)", name, suggestion)
+			}
+			break
+		}
+		described = append(described, desc)
+	}
+	return described
+}
+
 // GetExecutionOrder returns steps in topologically sorted order
-// Uses Kahn's algorithm for dependency resolution
+// Uses Kahn's algorithm for dependency resolution; init steps are always
+// scheduled before every other step and finalize steps after
 func (w *WorkflowDefinition) GetExecutionOrder() ([]WorkflowStep, error) {
 	// Build adjacency list and in-degree map
 	stepMap := make(map[string]WorkflowStep)
@@ -79,35 +641,65 @@ func (w *WorkflowDefinition) GetExecutionOrder() ([]WorkflowStep, error) {
 
 	for _, step := range w.Steps {
 		stepMap[step.Name] = step
-		inDegree[step.Name] = len(step.Depends)
-
-		for _, dep := range step.Depends {
-			dependents[dep] = append(dependents[dep], step.Name)
-		}
 	}
 
 	// Validate all dependencies exist
 	for _, step := range w.Steps {
-		for _, dep := range step.Depends {
+		for _, dep := range step.orderingDeps() {
 			if _, exists := stepMap[dep]; !exists {
 				return nil, fmt.Errorf("step %q depends on unknown step %q", step.Name, dep)
 			}
 		}
 	}
 
-	// Kahn's algorithm
+	// Init steps always run first and finalize steps last, so no step may
+	// wait on a step from a later phase
+	for _, step := range w.Steps {
+		for _, dep := range step.orderingDeps() {
+			other := stepMap[dep]
+			if other.phase() <= step.phase() {
+				continue
+			}
+			if other.Template == TemplateFinalize {
+				return nil, fmt.Errorf("step %q depends on finalize step %q, which always runs last", step.Name, dep)
+			}
+			return nil, fmt.Errorf("init step %q depends on non-init step %q; init steps always run first", step.Name, dep)
+		}
+	}
+
+	waits := w.schedulingDeps(stepMap)
+	for _, step := range w.Steps {
+		deps := waits[step.Name]
+		inDegree[step.Name] = len(deps)
+
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], step.Name)
+		}
+	}
+
+	// Kahn's algorithm. Among ready steps the highest Priority goes first,
+	// then declaration order, so the order is deterministic.
+	position := make(map[string]int, len(w.Steps))
 	var queue []string
-	for name, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, name)
+	for i, step := range w.Steps {
+		position[step.Name] = i
+		if inDegree[step.Name] == 0 {
+			queue = append(queue, step.Name)
 		}
 	}
 
 	var order []WorkflowStep
 	for len(queue) > 0 {
-		// Pop from queue
-		name := queue[0]
-		queue = queue[1:]
+		// Pop the best ready step
+		best := 0
+		for i := 1; i < len(queue); i++ {
+			a, b := stepMap[queue[i]], stepMap[queue[best]]
+			if a.Priority > b.Priority || (a.Priority == b.Priority && position[a.Name] < position[b.Name]) {
+				best = i
+			}
+		}
+		name := queue[best]
+		queue = append(queue[:best], queue[best+1:]...)
 
 		order = append(order, stepMap[name])
 
@@ -122,12 +714,204 @@ func (w *WorkflowDefinition) GetExecutionOrder() ([]WorkflowStep, error) {
 
 	// Check for cycles
 	if len(order) != len(w.Steps) {
+		// Every other step waits on the init steps, so a cycle among them
+		// stalls the whole workflow; name them rather than a generic cycle
+		var stuckInit []string
+		for _, step := range w.Steps {
+			if step.Template == TemplateInit && inDegree[step.Name] > 0 {
+				stuckInit = append(stuckInit, step.Name)
+			}
+		}
+		if len(stuckInit) > 0 {
+			return nil, fmt.Errorf("init steps have conflicting ordering: %s", strings.Join(stuckInit, ", "))
+		}
 		return nil, fmt.Errorf("workflow contains a dependency cycle")
 	}
 
 	return order, nil
 }
 
+// schedulingDeps returns, per step, every step it must wait for: its
+// ordering deps, soft deps that are present, and all steps of earlier
+// phases. Dependencies must already be known to exist.
+func (w *WorkflowDefinition) schedulingDeps(stepMap map[string]WorkflowStep) map[string][]string {
+	waits := make(map[string][]string, len(w.Steps))
+	for _, step := range w.Steps {
+		deps := step.orderingDeps()
+		// Soft dependencies only order against steps that are present, and
+		// are ignored where they would reach into a later phase
+		for _, dep := range step.SoftDepends {
+			other, exists := stepMap[dep]
+			if !exists || containsString(deps, dep) || other.phase() > step.phase() {
+				continue
+			}
+			deps = append(deps, dep)
+		}
+		// Each step implicitly waits for every step of an earlier phase
+		for _, other := range w.Steps {
+			if other.phase() < step.phase() && !containsString(deps, other.Name) {
+				deps = append(deps, other.Name)
+			}
+		}
+		waits[step.Name] = deps
+	}
+	return waits
+}
+
+// phase orders steps by template: init steps, then the rest, then finalize
+func (s WorkflowStep) phase() int {
+	switch s.Template {
+	case TemplateInit:
+		return 0
+	case TemplateFinalize:
+		return 2
+	}
+	return 1
+}
+
+// orderingDeps returns the steps that must run before this one: explicit
+// dependencies plus any step referenced by IfStepStatus
+func (s WorkflowStep) orderingDeps() []string {
+	deps := append([]string(nil), s.Depends...)
+	for _, name := range sortedKeys(s.IfStepStatus) {
+		if !containsString(deps, name) {
+			deps = append(deps, name)
+		}
+	}
+	return deps
+}
+
+// CheckStepStatus evaluates IfStepStatus against recorded step statuses.
+// It returns false and a reason if any condition is not met.
+func (s WorkflowStep) CheckStepStatus(statuses map[string]string) (bool, string) {
+	for _, name := range sortedKeys(s.IfStepStatus) {
+		want := s.IfStepStatus[name]
+		got, ok := statuses[name]
+		if !ok {
+			return false, fmt.Sprintf("step %q did not run, want status %s", name, want)
+		}
+		if !strings.EqualFold(got, want) {
+			return false, fmt.Sprintf("step %q status is %s, want %s", name, got, want)
+		}
+	}
+	return true, ""
+}
+
+// CheckWhen evaluates the step's When condition. It returns false and a
+// reason if the condition is not met; a step without one always runs.
+func (s WorkflowStep) CheckWhen(vars, params map[string]any) (bool, string) {
+	if s.When == "" {
+		return true, ""
+	}
+	cond, err := ParseCondition(s.When)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !cond.Eval(vars, params) {
+		return false, fmt.Sprintf("condition %q is false", s.When)
+	}
+	return true, ""
+}
+
+// MatchesTags reports whether the step passes the tag filters: it must
+// carry one of include (when include is non-empty) and none of exclude
+func (s WorkflowStep) MatchesTags(include, exclude []string) bool {
+	for _, tag := range s.Tags {
+		if containsString(exclude, tag) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, tag := range s.Tags {
+		if containsString(include, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// StepExclusion explains why a step will not run
+type StepExclusion struct {
+	Step   string `json:"step"`
+	Reason string `json:"reason"`
+
+	// Unreachable is set for steps that are enabled and match the tag
+	// filters but depend, possibly transitively, on an excluded step
+	Unreachable bool `json:"unreachable,omitempty"`
+}
+
+// ExcludedSteps returns the steps that will not run because they are
+// disabled, filtered out by tags, or depend on such a step, in execution
+// order. A dependency is a depends entry, or an if_step_status entry whose
+// wanted status is anything but Skipped (the status excluded steps are
+// recorded with). Soft dependencies never exclude a step, since they only
+// order it after steps that run. Finalize steps are never excluded.
+func (w *WorkflowDefinition) ExcludedSteps(include, exclude []string) ([]StepExclusion, error) {
+	steps, err := w.GetExecutionOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool)
+	var result []StepExclusion
+	for _, step := range steps {
+		if step.Template == TemplateFinalize {
+			continue
+		}
+		ex := StepExclusion{Step: step.Name}
+		switch {
+		case step.Disabled:
+			ex.Reason = "disabled"
+		case !step.MatchesTags(include, exclude):
+			ex.Reason = "filtered by tags"
+		default:
+			for _, dep := range step.Depends {
+				if excluded[dep] {
+					ex.Reason = fmt.Sprintf("depends on step %q, which will not run", dep)
+					ex.Unreachable = true
+					break
+				}
+			}
+			if ex.Reason != "" {
+				break
+			}
+			for _, name := range sortedKeys(step.IfStepStatus) {
+				want := step.IfStepStatus[name]
+				if excluded[name] && !strings.EqualFold(want, "Skipped") {
+					ex.Reason = fmt.Sprintf("runs only if step %q is %s, but it will not run", name, want)
+					ex.Unreachable = true
+					break
+				}
+			}
+		}
+		if ex.Reason != "" {
+			excluded[step.Name] = true
+			result = append(result, ex)
+		}
+	}
+	return result, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRetries returns the number of retries for a step
 func (w *WorkflowDefinition) GetRetries(step WorkflowStep) int {
 	if step.Retries > 0 {
@@ -138,3 +922,29 @@ func (w *WorkflowDefinition) GetRetries(step WorkflowStep) int {
 	}
 	return 0
 }
+
+// GetTimeout returns the timeout for an attempt of a step: the step's
+// timeout_seconds, or the workflow's when the step sets none, grown by
+// timeout_growth and capped by max_timeout_seconds; zero means no timeout
+func (w *WorkflowDefinition) GetTimeout(step WorkflowStep, attempt int) time.Duration {
+	base := time.Duration(step.TimeoutSeconds) * time.Second
+	if base <= 0 {
+		base = time.Duration(w.TimeoutSeconds) * time.Second
+	}
+	if base <= 0 || attempt <= 1 || step.TimeoutGrowth <= 0 {
+		return base
+	}
+	timeout := time.Duration(float64(base) * (1 + step.TimeoutGrowth*float64(attempt-1)))
+	if limit := time.Duration(step.MaxTimeoutSeconds) * time.Second; limit > 0 && timeout > limit {
+		timeout = limit
+	}
+	return timeout
+}
+
+// GetBackoff returns the retry backoff for a step, or nil for no delay
+func (w *WorkflowDefinition) GetBackoff(step WorkflowStep) *BackoffConfig {
+	if step.RetryBackoff != nil {
+		return step.RetryBackoff
+	}
+	return w.RetryBackoff
+}
@@ -0,0 +1,270 @@
+package taskkit
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const resolveHandlersWorkflow = `
+name: resolve
+platform: test
+on_step_give_up: test-give-up
+steps:
+  - name: init
+    template: init
+  - name: build
+  - name: deploy
+    handler: test-build
+  - name: old
+    disabled: true
+  - name: report
+    template: finalize
+`
+
+func TestResolveHandlersAllPresent(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-init":    succeed,
+		"test-build":   succeed,
+		"test-report":  succeed,
+		"test-give-up": succeed,
+	})
+	wf := loadTestWorkflow(t, resolveHandlersWorkflow)
+
+	found, missing := wf.ResolveHandlers()
+	want := []string{"test-init", "test-build", "test-report", "test-give-up"}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("found = %v, want %v", found, want)
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing = %v, want none (disabled steps are not checked)", missing)
+	}
+}
+
+func TestResolveHandlersSomeMissing(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-init":   succeed,
+		"test-report": succeed,
+	})
+	wf := loadTestWorkflow(t, resolveHandlersWorkflow)
+
+	found, missing := wf.ResolveHandlers()
+	if want := []string{"test-init", "test-report"}; !reflect.DeepEqual(found, want) {
+		t.Errorf("found = %v, want %v", found, want)
+	}
+	if want := []string{"test-build", "test-give-up"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+}
+
+func TestRunFailsFastOnMissingHandlers(t *testing.T) {
+	ran := map[string]bool{}
+	record := func(name string) StepHandler {
+		return func(StepInput, Deps) StepResult {
+			ran[name] = true
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, map[string]StepHandler{
+		"test-init":    record("init"),
+		"test-buidl":   record("buidl"),
+		"test-report":  record("report"),
+		"test-give-up": succeed,
+	})
+
+	result, _ := runTestWorkflow(t, resolveHandlersWorkflow, LocalRunnerConfig{})
+	if result.Result != "Error" {
+		t.Fatalf("result = %s, want Error", result.Result)
+	}
+	if !strings.Contains(result.ErrorMessage, "test-build (did you mean test-buidl?)") {
+		t.Errorf("error = %q, want the missing handler with a suggestion", result.ErrorMessage)
+	}
+	if ran["init"] || ran["buidl"] {
+		t.Errorf("steps ran despite missing handlers: %v", ran)
+	}
+	// Finalize steps still run so failure notifications fire
+	if !ran["report"] {
+		t.Error("finalize step did not run")
+	}
+	if got := stepByName(t, result, "report").Status; got != "Succeeded" {
+		t.Errorf("report status = %s, want Succeeded", got)
+	}
+}
+
+func TestRunAllowMissing(t *testing.T) {
+	registerHandlers(t, map[string]StepHandler{
+		"test-init":    succeed,
+		"test-report":  succeed,
+		"test-give-up": succeed,
+	})
+
+	result, _ := runTestWorkflow(t, resolveHandlersWorkflow, LocalRunnerConfig{AllowMissing: true})
+	if result.Result != "Failed" {
+		t.Fatalf("result = %s, want Failed", result.Result)
+	}
+	build := stepByName(t, result, "build")
+	if build.Status != "Failed" || build.FailureKind != FailureHandlerNotFound {
+		t.Errorf("build = %s/%s, want Failed/%s", build.Status, build.FailureKind, FailureHandlerNotFound)
+	}
+	if got := stepByName(t, result, "init").Status; got != "Succeeded" {
+		t.Errorf("init status = %s, want Succeeded", got)
+	}
+}
+
+const exclusionWorkflow = `
+name: exclusions
+platform: test
+steps:
+  - name: legacy
+    disabled: true
+  - name: migrate
+    depends: [legacy]
+  - name: verify
+    depends: [migrate]
+  - name: cleanup
+    if_step_status:
+      legacy: Failed
+  - name: note-skip
+    if_step_status:
+      legacy: Skipped
+  - name: ordered
+    soft_depends: [legacy]
+  - name: dns
+    tags: [network]
+  - name: report
+    template: finalize
+    depends: [legacy]
+`
+
+func TestExcludedSteps(t *testing.T) {
+	wf := loadTestWorkflow(t, exclusionWorkflow)
+
+	list, err := wf.ExcludedSteps(nil, []string{"network"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]StepExclusion, len(list))
+	for _, ex := range list {
+		got[ex.Step] = ex
+	}
+
+	want := map[string]bool{ // step -> unreachable
+		"legacy":  false,
+		"migrate": true,
+		"verify":  true,
+		"cleanup": true,
+		"dns":     false,
+	}
+	for name, unreachable := range want {
+		ex, ok := got[name]
+		if !ok {
+			t.Errorf("step %s not excluded", name)
+			continue
+		}
+		if ex.Unreachable != unreachable || ex.Reason == "" {
+			t.Errorf("step %s = %+v, want unreachable %v with a reason", name, ex, unreachable)
+		}
+	}
+	for _, name := range []string{"note-skip", "ordered", "report"} {
+		if ex, ok := got[name]; ok {
+			t.Errorf("step %s excluded: %s", name, ex.Reason)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("excluded %d steps, want %d: %v", len(got), len(want), list)
+	}
+}
+
+func TestRunReportsUnreachableSteps(t *testing.T) {
+	ran := map[string]bool{}
+	handlers := map[string]StepHandler{}
+	for _, name := range []string{"legacy", "migrate", "verify", "cleanup", "note-skip", "ordered", "dns", "report"} {
+		name := name
+		handlers["test-"+name] = func(StepInput, Deps) StepResult {
+			ran[name] = true
+			return NewStepResult()
+		}
+	}
+	registerHandlers(t, handlers)
+
+	result, _ := runTestWorkflow(t, exclusionWorkflow, LocalRunnerConfig{})
+	for _, name := range []string{"legacy", "migrate", "verify", "cleanup"} {
+		if ran[name] {
+			t.Errorf("excluded step %s ran", name)
+		}
+		if got := stepByName(t, result, name).Status; got != "Skipped" {
+			t.Errorf("step %s = %s, want Skipped", name, got)
+		}
+	}
+	for _, name := range []string{"note-skip", "ordered", "dns", "report"} {
+		if !ran[name] {
+			t.Errorf("step %s did not run", name)
+		}
+	}
+
+	var unreachable []string
+	for _, w := range result.Warnings {
+		if strings.Contains(w, "is unreachable") {
+			unreachable = append(unreachable, w)
+		}
+	}
+	if len(unreachable) != 3 {
+		t.Errorf("unreachable warnings = %q, want migrate, verify, and cleanup", unreachable)
+	}
+}
+
+func TestRunFinalizesOnOrderingError(t *testing.T) {
+	var reported string
+	ran := map[string]bool{}
+	registerHandlers(t, map[string]StepHandler{
+		"test-a": func(StepInput, Deps) StepResult { ran["a"] = true; return NewStepResult() },
+		"test-b": func(StepInput, Deps) StepResult { ran["b"] = true; return NewStepResult() },
+		"test-report": func(input StepInput, deps Deps) StepResult {
+			reported = input.WorkflowResult
+			return NewStepResult()
+		},
+	})
+
+	result, _ := runTestWorkflow(t, `
+name: cyclic
+platform: test
+steps:
+  - name: a
+    depends: [b]
+  - name: b
+    depends: [a]
+  - name: report
+    template: finalize
+`, LocalRunnerConfig{})
+	if result.Result != "Error" || !strings.Contains(result.ErrorMessage, "Failed to determine execution order") {
+		t.Fatalf("result = %s (%s), want an execution order Error", result.Result, result.ErrorMessage)
+	}
+	if ran["a"] || ran["b"] {
+		t.Errorf("cyclic steps ran: %v", ran)
+	}
+	// The finalize step still runs, and sees the run as errored
+	if reported != "Error" {
+		t.Errorf("finalize saw workflow result %q, want Error", reported)
+	}
+	if got := stepByName(t, result, "report").Status; got != "Succeeded" {
+		t.Errorf("report status = %s, want Succeeded", got)
+	}
+}
+
+func TestNewLocalRunnerEmptyRegistry(t *testing.T) {
+	snap := SnapshotRegistry()
+	t.Cleanup(func() { RestoreRegistry(snap) })
+	RestoreRegistry(RegistrySnapshot{})
+
+	_, err := NewLocalRunner(LocalRunnerConfig{
+		WorkflowPath: writeFile(t, t.TempDir(), "workflow.yaml", resolveHandlersWorkflow),
+		Workdir:      t.TempDir(),
+	})
+	if !errors.Is(err, ErrNoHandlers) {
+		t.Fatalf("NewLocalRunner error = %v, want ErrNoHandlers", err)
+	}
+	if !strings.Contains(err.Error(), "did you import your task packages?") {
+		t.Errorf("error = %q, want the import hint", err)
+	}
+}
@@ -0,0 +1,21 @@
+// Package exec provides a step handler that runs a command as a subprocess.
+//
+// Handlers:
+//   - exec: Runs a command, optionally as another user and with a umask
+//
+// Reference it from a step with an explicit handler name:
+//
+//	steps:
+//	  - name: prune-backups
+//	    handler: exec
+//	    params:
+//	      command: ["restic", "forget", "--keep-daily", "7"]
+//	      run_as: "1000:1000"
+//	      umask: "027"
+//
+// A string command runs through sh -c; a list runs directly. run_as takes a
+// uid or uid:gid, as a string or an integer uid, and requires taskkit to run
+// as root; umask is an octal string or an integer mode. Both are only
+// supported on Unix. Output is streamed to the console and recorded as
+// the stdout and exit_code outputs; a non-zero exit fails the step.
+package exec
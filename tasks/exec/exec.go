@@ -0,0 +1,198 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func init() {
+	taskkit.Register("exec", HandleExec)
+}
+
+// HandleExec runs the command param as a subprocess in the workdir with the
+// step env added. The process is killed if the run's context is cancelled.
+func HandleExec(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResult {
+	result := taskkit.NewStepResult()
+
+	deps.Logger("Running exec")
+
+	// Validate params
+	argv, err := commandParam(input.GetParam("command"))
+	if err != nil {
+		result.AddError(err.Error(), "exec")
+		return result
+	}
+	cred, err := parseRunAs(input.GetParam("run_as"))
+	if err != nil {
+		result.AddError(fmt.Sprintf("Invalid run_as param: %v", err), "exec")
+		return result
+	}
+	umask, err := parseUmask(input.GetParam("umask"))
+	if err != nil {
+		result.AddError(fmt.Sprintf("Invalid umask param: %v", err), "exec")
+		return result
+	}
+
+	ctx := deps.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd, err := buildCommand(ctx, argv, cred, umask)
+	if err != nil {
+		result.AddError(err.Error(), "exec")
+		return result
+	}
+	cmd.Dir = deps.Workdir
+	cmd.Env = os.Environ()
+	for k, v := range deps.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	var stdout bytes.Buffer
+	live := deps.Stdout
+	if live == nil {
+		live = io.Discard
+	}
+	cmd.Stdout = io.MultiWriter(&stdout, live)
+	cmd.Stderr = live
+
+	err = cmd.Run()
+	result.SetOutput("stdout", stdout.String())
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.SetOutput("exit_code", 0)
+		result.AddInfo(fmt.Sprintf("Command succeeded: %s", strings.Join(argv, " ")), "exec")
+	case ctx.Err() != nil:
+		result.AddError(fmt.Sprintf("Command cancelled: %v", ctx.Err()), "exec")
+	case errors.As(err, &exitErr):
+		result.SetOutput("exit_code", exitErr.ExitCode())
+		result.AddError(fmt.Sprintf("Command exited with code %d", exitErr.ExitCode()), "exec")
+	case cred != nil && errors.Is(err, os.ErrPermission):
+		result.AddError(fmt.Sprintf("Cannot run as %v: changing user requires taskkit to run as root (%v)", input.GetParam("run_as"), err), "exec")
+	default:
+		result.AddError(fmt.Sprintf("Failed to start command: %v", err), "exec")
+	}
+	return result
+}
+
+// commandParam accepts a shell string or a list of arguments
+func commandParam(v any) ([]string, error) {
+	switch c := v.(type) {
+	case string:
+		if strings.TrimSpace(c) == "" {
+			return nil, fmt.Errorf("Missing required param: command")
+		}
+		return []string{"sh", "-c", c}, nil
+	case []any:
+		if len(c) == 0 {
+			return nil, fmt.Errorf("Missing required param: command")
+		}
+		argv := make([]string, len(c))
+		for i, arg := range c {
+			argv[i] = fmt.Sprint(arg)
+		}
+		return argv, nil
+	case nil:
+		return nil, fmt.Errorf("Missing required param: command")
+	}
+	return nil, fmt.Errorf("Invalid command param: want a string or list, got %T", v)
+}
+
+// credential is the uid/gid parsed from run_as
+type credential struct {
+	uid, gid uint32
+}
+
+// parseRunAs parses "uid", "uid:gid" or an integer uid; a bare uid keeps gid
+// equal to it
+func parseRunAs(v any) (*credential, error) {
+	var s string
+	switch r := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		s = r
+	default:
+		n, ok := intParam(v)
+		if !ok || n < 0 || n > math.MaxUint32 {
+			return nil, fmt.Errorf("want a uid or \"uid:gid\", got %v", v)
+		}
+		return &credential{uid: uint32(n), gid: uint32(n)}, nil
+	}
+	if s == "" {
+		return nil, nil
+	}
+	uidPart, gidPart, hasGid := strings.Cut(s, ":")
+	uid, err := strconv.ParseUint(uidPart, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("uid %q is not a number", uidPart)
+	}
+	gid := uid
+	if hasGid {
+		if gid, err = strconv.ParseUint(gidPart, 10, 32); err != nil {
+			return nil, fmt.Errorf("gid %q is not a number", gidPart)
+		}
+	}
+	return &credential{uid: uint32(uid), gid: uint32(gid)}, nil
+}
+
+// parseUmask parses an octal string such as "022" or an integer mode; -1
+// means unset. An integer is taken as the mode itself, so an unquoted YAML
+// 027 (decoded as octal) and a JSON 23 are the same mask.
+func parseUmask(v any) (int, error) {
+	var mask uint64
+	switch m := v.(type) {
+	case nil:
+		return -1, nil
+	case string:
+		if m == "" {
+			return -1, nil
+		}
+		var err error
+		if mask, err = strconv.ParseUint(m, 8, 32); err != nil {
+			return 0, fmt.Errorf("%q is not an octal mode", m)
+		}
+	default:
+		n, ok := intParam(v)
+		if !ok || n < 0 {
+			return 0, fmt.Errorf("want an octal mode, got %v", v)
+		}
+		mask = uint64(n)
+	}
+	if mask > 0777 {
+		return 0, fmt.Errorf("%#o is not a valid mode", mask)
+	}
+	return int(mask), nil
+}
+
+// intParam reads a whole number as decoded from YAML (int) or JSON (float64)
+func intParam(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package exec
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func run(t *testing.T, params map[string]any) taskkit.StepResult {
+	t.Helper()
+	return HandleExec(taskkit.StepInput{StepName: "exec", Params: params}, taskkit.Deps{
+		Logger:  func(string, ...any) {},
+		Stdout:  io.Discard,
+		Context: context.Background(),
+		// A user dropped to with run_as must be able to enter the workdir
+		Workdir: os.TempDir(),
+	})
+}
+
+func TestExecCommand(t *testing.T) {
+	result := run(t, map[string]any{"command": []any{"echo", "hello", "world"}})
+	if result.HasErrors() || result.Output["stdout"] != "hello world\n" || result.Output["exit_code"] != 0 {
+		t.Errorf("echo = %v %+v, want hello world with exit code 0", result.Output, result.Messages)
+	}
+
+	result = run(t, map[string]any{"command": "exit 3"})
+	if !result.HasErrors() || result.Output["exit_code"] != 3 {
+		t.Errorf("exit 3 = %v, want a failure with exit code 3", result.Output)
+	}
+}
+
+func TestExecUmask(t *testing.T) {
+	if !supported {
+		t.Skip("umask is not supported on this platform")
+	}
+	result := run(t, map[string]any{"command": "umask", "umask": "027"})
+	if got := strings.TrimSpace(result.Output["stdout"].(string)); got != "0027" {
+		t.Errorf("umask = %q, want 0027", got)
+	}
+}
+
+func TestExecNumericUmask(t *testing.T) {
+	if !supported {
+		t.Skip("umask is not supported on this platform")
+	}
+	// YAML decodes an unquoted 027 as an octal int, JSON 23 as a float64
+	for _, umask := range []any{027, float64(23)} {
+		result := run(t, map[string]any{"command": "umask", "umask": umask})
+		if got := strings.TrimSpace(result.Output["stdout"].(string)); got != "0027" {
+			t.Errorf("umask %v = %q, want 0027", umask, got)
+		}
+	}
+}
+
+func TestExecRunAs(t *testing.T) {
+	if !supported || os.Geteuid() != 0 {
+		t.Skip("changing credentials needs root")
+	}
+	result := run(t, map[string]any{"command": "id -u; id -g", "run_as": "65534:65534"})
+	if result.HasErrors() {
+		t.Fatalf("run_as failed: %+v", result.Messages)
+	}
+	if got := strings.Fields(result.Output["stdout"].(string)); len(got) != 2 || got[0] != "65534" || got[1] != "65534" {
+		t.Errorf("credentials = %q, want uid and gid 65534", got)
+	}
+}
+
+func TestExecNumericRunAs(t *testing.T) {
+	if !supported || os.Geteuid() != 0 {
+		t.Skip("changing credentials needs root")
+	}
+	// YAML decodes an unquoted uid as an int, JSON as a float64
+	for _, runAs := range []any{65534, float64(65534)} {
+		result := run(t, map[string]any{"command": "id -u; id -g", "run_as": runAs})
+		if result.HasErrors() {
+			t.Fatalf("run_as %v failed: %+v", runAs, result.Messages)
+		}
+		if got := strings.Fields(result.Output["stdout"].(string)); len(got) != 2 || got[0] != "65534" || got[1] != "65534" {
+			t.Errorf("run_as %v credentials = %q, want uid and gid 65534", runAs, got)
+		}
+	}
+}
+
+func TestExecRunAsWithoutRoot(t *testing.T) {
+	if !supported || os.Geteuid() == 0 {
+		t.Skip("needs an unprivileged user")
+	}
+	result := run(t, map[string]any{"command": "true", "run_as": "65534"})
+	if !result.HasErrors() || !strings.Contains(result.Messages[len(result.Messages)-1].Text, "requires taskkit to run as root") {
+		t.Errorf("messages = %+v, want a clear privilege error", result.Messages)
+	}
+}
+
+func TestExecInvalidParams(t *testing.T) {
+	tests := []struct {
+		params map[string]any
+		want   string
+	}{
+		{map[string]any{}, "Missing required param: command"},
+		{map[string]any{"command": []any{}}, "Missing required param: command"},
+		{map[string]any{"command": 42}, "Invalid command param"},
+		{map[string]any{"command": "true", "run_as": "nobody"}, "Invalid run_as param"},
+		{map[string]any{"command": "true", "run_as": "1000:staff"}, "Invalid run_as param"},
+		{map[string]any{"command": "true", "umask": "999"}, "Invalid umask param"},
+		{map[string]any{"command": "true", "run_as": -1}, "Invalid run_as param"},
+		{map[string]any{"command": "true", "run_as": 1000.5}, "Invalid run_as param"},
+		{map[string]any{"command": "true", "run_as": true}, "Invalid run_as param"},
+		{map[string]any{"command": "true", "umask": 01000}, "Invalid umask param"},
+		{map[string]any{"command": "true", "umask": []any{"022"}}, "Invalid umask param"},
+	}
+	for _, tt := range tests {
+		result := run(t, tt.params)
+		if !result.HasErrors() || !strings.Contains(result.Messages[0].Text, tt.want) {
+			t.Errorf("exec(%v) messages = %+v, want %q", tt.params, result.Messages, tt.want)
+		}
+	}
+}
+
+func TestParseRunAs(t *testing.T) {
+	cred, err := parseRunAs("1000")
+	if err != nil || *cred != (credential{uid: 1000, gid: 1000}) {
+		t.Errorf("parseRunAs(1000) = %+v, %v, want uid and gid 1000", cred, err)
+	}
+	cred, err = parseRunAs("1000:100")
+	if err != nil || *cred != (credential{uid: 1000, gid: 100}) {
+		t.Errorf("parseRunAs(1000:100) = %+v, %v, want uid 1000 gid 100", cred, err)
+	}
+	for _, n := range []any{1000, float64(1000)} {
+		cred, err = parseRunAs(n)
+		if err != nil || *cred != (credential{uid: 1000, gid: 1000}) {
+			t.Errorf("parseRunAs(%T 1000) = %+v, %v, want uid and gid 1000", n, cred, err)
+		}
+	}
+	if cred, err := parseRunAs(nil); cred != nil || err != nil {
+		t.Errorf("parseRunAs(nil) = %+v, %v, want unset", cred, err)
+	}
+	if cred, err := parseRunAs(""); cred != nil || err != nil {
+		t.Errorf("parseRunAs(\"\") = %+v, %v, want unset", cred, err)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// supported reports whether run_as and umask work on this platform
+const supported = false
+
+// buildCommand creates the subprocess; run_as and umask need Unix
+func buildCommand(ctx context.Context, argv []string, cred *credential, umask int) (*exec.Cmd, error) {
+	if cred != nil || umask >= 0 {
+		return nil, fmt.Errorf("run_as and umask are only supported on Unix")
+	}
+	return exec.CommandContext(ctx, argv[0], argv[1:]...), nil
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// supported reports whether run_as and umask work on this platform
+const supported = true
+
+// buildCommand creates the subprocess, applying run_as through the process
+// credentials and umask through a sh wrapper (Go has no per-process umask)
+func buildCommand(ctx context.Context, argv []string, cred *credential, umask int) (*exec.Cmd, error) {
+	if umask >= 0 {
+		wrapper := fmt.Sprintf(`umask %04o && exec "$@"`, umask)
+		argv = append([]string{"sh", "-c", wrapper, "sh"}, argv...)
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	if cred != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			Credential: &syscall.Credential{Uid: cred.uid, Gid: cred.gid},
+		}
+	}
+	return cmd, nil
+}
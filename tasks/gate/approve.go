@@ -0,0 +1,150 @@
+package gate
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+// ApprovalTokenEnv holds the token that the approval_token param must match
+const ApprovalTokenEnv = "TASKKIT_APPROVAL_TOKEN"
+
+func init() {
+	taskkit.RegisterWithTest("manual-approve", HandleApprove, testApprove)
+}
+
+// testApprove checks the non-interactive approved and unapproved paths.
+func testApprove() error {
+	deps := taskkit.Deps{
+		Logger:  func(string, ...any) {},
+		Stdout:  io.Discard,
+		Stdin:   strings.NewReader(""),
+		Context: context.Background(),
+	}
+	input := taskkit.StepInput{StepName: "gate", Params: map[string]any{}}
+
+	if result := HandleApprove(input, deps); !result.HasErrors() {
+		return fmt.Errorf("unapproved gate succeeded without a terminal")
+	}
+
+	deps.Approved = true
+	if result := HandleApprove(input, deps); result.HasErrors() {
+		return fmt.Errorf("pre-approved gate failed: %v", result.Messages)
+	}
+
+	deps.Approved = false
+	deps.Env = map[string]string{ApprovalTokenEnv: "s3cret"}
+	input.Params["approval_token"] = "wrong"
+	if result := HandleApprove(input, deps); !result.HasErrors() {
+		return fmt.Errorf("gate accepted a mismatched approval token")
+	}
+	input.Params["approval_token"] = "s3cret"
+	if result := HandleApprove(input, deps); result.HasErrors() {
+		return fmt.Errorf("gate rejected a matching approval token: %v", result.Messages)
+	}
+	return nil
+}
+
+// HandleApprove asks for confirmation before the workflow continues. A
+// declined or unavailable approval fails the step, halting the workflow.
+func HandleApprove(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResult {
+	result := taskkit.NewStepResult()
+
+	deps.Logger("Running manual-approve")
+
+	message := input.GetParamString("message")
+	if message == "" {
+		message = fmt.Sprintf("Continue past step %q?", input.StepName)
+	}
+
+	// Non-interactive approval
+	if deps.Approved {
+		result.AddInfo("Approved via --approve", "gate")
+		result.Output["approved_by"] = "flag"
+		return result
+	}
+	if tokenMatches(input.GetParamString("approval_token"), expectedToken(deps)) {
+		result.AddInfo("Approved via approval token", "gate")
+		result.Output["approved_by"] = "token"
+		return result
+	}
+
+	if !isInteractive(deps.Stdin) {
+		result.AddError("Approval required: rerun with --approve or a valid approval_token param", "gate")
+		return result
+	}
+
+	ctx := deps.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	answer, err := prompt(ctx, deps.Stdout, deps.Stdin, message)
+	if err != nil {
+		result.AddError(fmt.Sprintf("Approval not received: %v", err), "gate")
+		return result
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		result.AddInfo("Approved interactively", "gate")
+		result.Output["approved_by"] = "prompt"
+	default:
+		result.AddError("Approval declined", "gate")
+	}
+	return result
+}
+
+// expectedToken reads the approval token from the step env, then the process env
+func expectedToken(deps taskkit.Deps) string {
+	if token, ok := deps.Env[ApprovalTokenEnv]; ok {
+		return token
+	}
+	return os.Getenv(ApprovalTokenEnv)
+}
+
+func tokenMatches(got, want string) bool {
+	if got == "" || want == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func isInteractive(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && taskkit.IsTerminal(f)
+}
+
+// prompt writes the question and waits for one line of input, returning
+// early if ctx is cancelled
+func prompt(ctx context.Context, out io.Writer, in io.Reader, message string) (string, error) {
+	if out == nil {
+		out = os.Stdout
+	}
+	// The step writer is line-buffered, so end the prompt with a newline
+	fmt.Fprintf(out, "%s [y/N]\n", message)
+
+	type reply struct {
+		line string
+		err  error
+	}
+	replies := make(chan reply, 1)
+	go func() {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		replies <- reply{line, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-replies:
+		return r.line, r.err
+	}
+}
@@ -0,0 +1,80 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func approveDeps() taskkit.Deps {
+	return taskkit.Deps{
+		Logger:  func(string, ...any) {},
+		Stdout:  io.Discard,
+		Stdin:   strings.NewReader("y\n"),
+		Context: context.Background(),
+		Env:     map[string]string{ApprovalTokenEnv: "s3cret"},
+	}
+}
+
+func TestApproveNonInteractive(t *testing.T) {
+	tests := []struct {
+		name       string
+		approved   bool
+		token      string
+		approvedBy string
+	}{
+		{"flag", true, "", "flag"},
+		{"token", false, "s3cret", "token"},
+		{"wrong token", false, "wrong", ""},
+		{"unapproved", false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := approveDeps()
+			deps.Approved = tt.approved
+			params := map[string]any{}
+			if tt.token != "" {
+				params["approval_token"] = tt.token
+			}
+
+			// Stdin is not a terminal, so the piped "y" is never read
+			result := HandleApprove(taskkit.StepInput{StepName: "gate", Params: params}, deps)
+			if tt.approvedBy == "" {
+				if !result.HasErrors() {
+					t.Fatalf("gate succeeded, want approval required")
+				}
+				if !strings.Contains(result.Messages[len(result.Messages)-1].Text, "--approve") {
+					t.Errorf("messages = %+v, want a hint to use --approve", result.Messages)
+				}
+				return
+			}
+			if result.HasErrors() {
+				t.Fatalf("gate failed: %+v", result.Messages)
+			}
+			if result.Output["approved_by"] != tt.approvedBy {
+				t.Errorf("approved_by = %v, want %s", result.Output["approved_by"], tt.approvedBy)
+			}
+		})
+	}
+}
+
+func TestPromptHonoursCancellation(t *testing.T) {
+	in, _ := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := prompt(ctx, io.Discard, in, "Continue?")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("prompt error = %v, want context.Canceled", err)
+	}
+}
+
+func TestApproveSelfTest(t *testing.T) {
+	if err := testApprove(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,18 @@
+// Package gate provides step handlers that pause a workflow for a human
+// decision before destructive operations.
+//
+// Handlers:
+//   - manual-approve: Prompts for confirmation on an interactive terminal
+//
+// Reference it from a step with an explicit handler name:
+//
+//	steps:
+//	  - name: confirm-wipe
+//	    handler: manual-approve
+//	    params:
+//	      message: Wipe the NAS scratch volume?
+//
+// Without a terminal the step fails unless the run was started with
+// --approve, or the approval_token param matches the TASKKIT_APPROVAL_TOKEN
+// environment variable (e.g. --param approval_token=... from CI).
+package gate
@@ -0,0 +1,93 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+// DefaultTimeout bounds a net-check dial when no timeout param is given
+const DefaultTimeout = 5 * time.Second
+
+func init() {
+	taskkit.Register("net-check", HandleCheck)
+}
+
+// HandleCheck attempts a TCP connection to host:port and records whether it
+// succeeded and how long the dial took. A failed dial fails the step.
+func HandleCheck(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResult {
+	result := taskkit.NewStepResult()
+
+	deps.Logger("Running net-check")
+
+	// Validate params
+	host := input.GetParamString("host")
+	if host == "" {
+		result.AddError("Missing required param: host", "net")
+		return result
+	}
+	port, ok := input.GetParamPathInt("port")
+	if !ok || port <= 0 || port > 65535 {
+		result.AddError(fmt.Sprintf("Invalid or missing port param: %v", input.GetParam("port")), "net")
+		return result
+	}
+	timeout, err := timeoutParam(input)
+	if err != nil {
+		result.AddError(fmt.Sprintf("Invalid timeout param: %v", err), "net")
+		return result
+	}
+
+	ctx := deps.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	result.SetOutput("address", address)
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	latency := time.Since(start)
+	result.SetOutput("latency_ms", latency.Milliseconds())
+	if err != nil {
+		result.SetOutput("reachable", false)
+		result.SetOutput("error", err.Error())
+		result.AddError(fmt.Sprintf("%s is unreachable: %v", address, err), "net")
+		return result
+	}
+	conn.Close()
+
+	result.SetOutput("reachable", true)
+	result.AddInfo(fmt.Sprintf("%s is reachable (%s)", address, latency.Round(time.Millisecond)), "net")
+	return result
+}
+
+// timeoutParam reads the timeout param in seconds, defaulting to DefaultTimeout
+func timeoutParam(input taskkit.StepInput) (time.Duration, error) {
+	v, ok := input.GetParamPath("timeout")
+	if !ok || v == nil {
+		return DefaultTimeout, nil
+	}
+	var seconds float64
+	switch n := v.(type) {
+	case int:
+		seconds = float64(n)
+	case int64:
+		seconds = float64(n)
+	case float64:
+		seconds = n
+	default:
+		return 0, fmt.Errorf("want seconds, got %v", v)
+	}
+	if seconds <= 0 {
+		return 0, fmt.Errorf("must be positive, got %v", v)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,98 @@
+package net
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func check(ctx context.Context, params map[string]any) taskkit.StepResult {
+	return HandleCheck(taskkit.StepInput{StepName: "check", Params: params},
+		taskkit.Deps{Logger: func(string, ...any) {}, Context: ctx})
+}
+
+// listen starts a local listener that accepts and closes connections
+func listen(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort returns a port with nothing listening on it
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestCheckReachable(t *testing.T) {
+	port := listen(t)
+	result := check(context.Background(), map[string]any{"host": "127.0.0.1", "port": port, "timeout": 2})
+	if result.HasErrors() {
+		t.Fatalf("check failed: %+v", result.Messages)
+	}
+	if result.Output["reachable"] != true {
+		t.Errorf("reachable = %v, want true", result.Output["reachable"])
+	}
+	if latency, ok := result.Output["latency_ms"].(int64); !ok || latency < 0 {
+		t.Errorf("latency_ms = %v, want a non-negative duration", result.Output["latency_ms"])
+	}
+}
+
+func TestCheckClosedPort(t *testing.T) {
+	result := check(context.Background(), map[string]any{"host": "127.0.0.1", "port": closedPort(t)})
+	if !result.HasErrors() {
+		t.Fatal("check of a closed port succeeded")
+	}
+	if result.Output["reachable"] != false || result.Output["error"] == "" {
+		t.Errorf("output = %v, want unreachable with an error", result.Output)
+	}
+}
+
+func TestCheckCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := check(ctx, map[string]any{"host": "127.0.0.1", "port": listen(t)})
+	if !result.HasErrors() || result.Output["reachable"] != false {
+		t.Errorf("cancelled check = %v, want unreachable", result.Output)
+	}
+}
+
+func TestCheckInvalidParams(t *testing.T) {
+	tests := []struct {
+		params map[string]any
+		want   string
+	}{
+		{map[string]any{"port": 22}, "Missing required param: host"},
+		{map[string]any{"host": "nas", "port": 70000}, "Invalid or missing port param"},
+		{map[string]any{"host": "nas"}, "Invalid or missing port param"},
+		{map[string]any{"host": "nas", "port": 22, "timeout": -1}, "Invalid timeout param"},
+	}
+	for _, tt := range tests {
+		result := check(context.Background(), tt.params)
+		if !result.HasErrors() || !strings.Contains(result.Messages[0].Text, tt.want) {
+			t.Errorf("check(%v) messages = %+v, want %q", tt.params, result.Messages, tt.want)
+		}
+	}
+}
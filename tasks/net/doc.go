@@ -0,0 +1,19 @@
+// Package net provides step handlers that probe network services.
+//
+// Handlers:
+//   - net-check: Dials a TCP address and records reachability and latency
+//
+// Reference it from a step with an explicit handler name:
+//
+//	steps:
+//	  - name: check-nas
+//	    handler: net-check
+//	    params:
+//	      host: nas.lan
+//	      port: 445
+//	      timeout: 3
+//
+// The timeout is in seconds (default 5). The step fails when the connection
+// cannot be established; its output still records reachable: false and the
+// dial error so finalize steps can report it.
+package net
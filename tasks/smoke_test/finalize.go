@@ -33,10 +33,10 @@ func HandleFinalize(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResu
 	deps.Logger("Running smoke-test-finalize")
 
 	// Gather vars
-	testName := getStringVar(input.Vars, "test_name", "unknown")
-	startTime := getStringVar(input.Vars, "start_time", "")
-	checksPassed := getBoolVar(input.Vars, "checks_passed", false)
-	goVersion := getStringVar(input.Vars, "go_version", "")
+	testName := getStringVar(&input, "test_name", "unknown")
+	startTime := getStringVar(&input, "start_time", "")
+	checksPassed := getBoolVar(&input, "checks_passed", false)
+	goVersion := getStringVar(&input, "go_version", "")
 
 	// Build report
 	report := SmokeTestReport{
@@ -49,13 +49,13 @@ func HandleFinalize(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResu
 		Status:       "passed",
 		Details: map[string]any{
 			"workflow":    input.WorkflowName,
-			"initialized": getBoolVar(input.Vars, "initialized", false),
+			"initialized": getBoolVar(&input, "initialized", false),
 		},
 	}
 
 	if !checksPassed {
 		report.Status = "failed"
-		result.AddError("Smoke test checks failed", "smoke-test")
+		result.AddErrorCode("CHECK_FAILED", "Smoke test checks failed", "smoke-test")
 	} else {
 		result.AddInfo("All smoke test checks passed", "smoke-test")
 	}
@@ -78,20 +78,16 @@ func HandleFinalize(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResu
 	return result
 }
 
-func getStringVar(vars map[string]any, key, defaultVal string) string {
-	if v, ok := vars[key]; ok {
-		if s, ok := v.(string); ok {
-			return s
-		}
+func getStringVar(input *taskkit.StepInput, key, defaultVal string) string {
+	if s, ok := input.GetVar(key).(string); ok {
+		return s
 	}
 	return defaultVal
 }
 
-func getBoolVar(vars map[string]any, key string, defaultVal bool) bool {
-	if v, ok := vars[key]; ok {
-		if b, ok := v.(bool); ok {
-			return b
-		}
+func getBoolVar(input *taskkit.StepInput, key string, defaultVal bool) bool {
+	if b, ok := input.GetVar(key).(bool); ok {
+		return b
 	}
 	return defaultVal
 }
@@ -9,7 +9,26 @@ import (
 )
 
 func init() {
-	taskkit.Register("smoke-test-init", HandleInit)
+	taskkit.RegisterWithTest("smoke-test-init", HandleInit, testInit)
+}
+
+// testInit checks that HandleInit initializes the expected vars.
+func testInit() error {
+	input := taskkit.StepInput{
+		StepName: "init",
+		Params:   map[string]any{"test_name": "self-test"},
+	}
+	result := HandleInit(input, taskkit.Deps{Logger: func(string, ...any) {}})
+	if result.HasErrors() {
+		return fmt.Errorf("unexpected errors: %v", result.Messages)
+	}
+	if got := result.ContextUpdates["test_name"]; got != "self-test" {
+		return fmt.Errorf("test_name = %v, want self-test", got)
+	}
+	if result.ContextUpdates["initialized"] != true {
+		return fmt.Errorf("initialized var not set")
+	}
+	return nil
 }
 
 // HandleInit validates input parameters and initializes workflow variables.
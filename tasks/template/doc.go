@@ -0,0 +1,18 @@
+// Package template provides a step handler that renders files from Go templates.
+//
+// Handlers:
+//   - render-template: Renders a text/template with workflow vars and params
+//
+// Because the handler is not tied to a platform prefix, reference it from a
+// step with an explicit handler name:
+//
+//	steps:
+//	  - name: render-config
+//	    handler: render-template
+//	    params:
+//	      template: templates/app.conf.tmpl
+//	      output: app.conf
+//
+// The template receives .Vars and .Params and can use upper, lower, trim,
+// replace, join, default, quote, and toJSON.
+package template
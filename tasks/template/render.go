@@ -0,0 +1,106 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func init() {
+	taskkit.Register("render-template", HandleRender)
+}
+
+// HandleRender renders the template param (a file path or inline text) to
+// the output path, resolved relative to the workdir.
+func HandleRender(input taskkit.StepInput, deps taskkit.Deps) taskkit.StepResult {
+	result := taskkit.NewStepResult()
+
+	deps.Logger("Running render-template")
+
+	source := input.GetParamString("template")
+	output := input.GetParamString("output")
+	if source == "" || output == "" {
+		result.AddError("Both 'template' and 'output' params are required", "template")
+		return result
+	}
+
+	// Treat the template param as a path when it names an existing file
+	text := source
+	name := "inline"
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			result.AddError(fmt.Sprintf("Failed to read template: %v", err), "template")
+			return result
+		}
+		text = string(data)
+		name = filepath.Base(source)
+	}
+
+	tmpl, err := texttemplate.New(name).Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		result.AddError(fmt.Sprintf("Failed to parse template: %v", err), "template")
+		return result
+	}
+
+	var buf bytes.Buffer
+	data := map[string]any{
+		"Vars":   input.Vars,
+		"Params": input.Params,
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		result.AddError(fmt.Sprintf("Failed to render template: %v", err), "template")
+		return result
+	}
+
+	outPath := output
+	if !filepath.IsAbs(outPath) {
+		outPath = filepath.Join(deps.Workdir, outPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		result.AddError(fmt.Sprintf("Failed to create output dir: %v", err), "template")
+		return result
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0644); err != nil {
+		result.AddError(fmt.Sprintf("Failed to write output: %v", err), "template")
+		return result
+	}
+
+	result.AddInfo(fmt.Sprintf("Rendered %s to %s (%d bytes)", name, outPath, buf.Len()), "template")
+	result.SetOutput("output_path", outPath)
+	result.SetOutput("bytes", buf.Len())
+
+	return result
+}
+
+var funcs = texttemplate.FuncMap{
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"join": func(sep string, items []any) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(def, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"quote": func(v any) string { return strconv.Quote(fmt.Sprint(v)) },
+	"toJSON": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
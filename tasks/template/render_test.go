@@ -0,0 +1,75 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/erauner/homelab-task-go/pkg/taskkit"
+)
+
+func render(t *testing.T, params map[string]any) (taskkit.StepResult, string) {
+	t.Helper()
+	workdir := t.TempDir()
+	result := HandleRender(taskkit.StepInput{
+		StepName: "render-config",
+		Params:   params,
+		Vars:     map[string]any{"image": "app:1.2", "hosts": []any{"a", "b"}},
+	}, taskkit.Deps{Workdir: workdir, Logger: func(string, ...any) {}})
+	return result, workdir
+}
+
+func TestRenderInlineTemplate(t *testing.T) {
+	result, workdir := render(t, map[string]any{
+		"template": `image={{ .Vars.image }} hosts={{ join "," .Vars.hosts }} env={{ upper .Params.env }} tier={{ default "web" .Params.tier }}`,
+		"output":   "conf/app.conf",
+		"env":      "prod",
+	})
+	if result.HasErrors() {
+		t.Fatalf("render failed: %+v", result.Messages)
+	}
+	path := filepath.Join(workdir, "conf", "app.conf")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "image=app:1.2 hosts=a,b env=PROD tier=web"; string(data) != want {
+		t.Errorf("rendered %q, want %q", data, want)
+	}
+	if result.Output["output_path"] != path {
+		t.Errorf("output_path = %v, want %s", result.Output["output_path"], path)
+	}
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "app.conf.tmpl")
+	if err := os.WriteFile(source, []byte("image: {{ .Vars.image | quote }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "app.conf")
+	result, _ := render(t, map[string]any{"template": source, "output": out})
+	if result.HasErrors() {
+		t.Fatalf("render failed: %+v", result.Messages)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "image: \"app:1.2\"\n"; string(data) != want {
+		t.Errorf("rendered %q, want %q", data, want)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := map[string]map[string]any{
+		"missing output": {"template": "x"},
+		"bad template":   {"template": "{{ .Vars.image ", "output": "out"},
+	}
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			if result, _ := render(t, params); !result.HasErrors() {
+				t.Error("render succeeded, want an error")
+			}
+		})
+	}
+}